@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Chunk is the byte range [Start, End) of a file, holding whole lines once
+// aligned by SplitChunks.
+type Chunk struct {
+	Start, End int64
+}
+
+// Len return the number of bytes of the chunk.
+func (c Chunk) Len() int64 {
+	return c.End - c.Start
+}
+
+// SplitChunks split the size bytes of r into up to n chunks of about the
+// same size, each holding whole lines. Every boundary is aligned up front
+// on the start of the line following the one it falls in, so the line
+// spanning it belongs to the previous chunk whole and a chunk is read up
+// to its End exactly. The chunks left empty, as the boundaries falling in
+// a line longer than a chunk, are dropped, a file smaller than n bytes
+// getting fewer chunks.
+func SplitChunks(r io.ReaderAt, size int64, n int) ([]Chunk, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of chunks %d: must be positive", n)
+	}
+	var (
+		chunks []Chunk
+		start  int64
+	)
+	for i := 1; i <= n && start < size; i++ {
+		end := size
+		if i < n {
+			var err error
+			if end, err = lineStart(r, max(size*int64(i)/int64(n), start), size); err != nil {
+				return nil, err
+			}
+		}
+		if end > start {
+			chunks = append(chunks, Chunk{Start: start, End: end})
+			start = end
+		}
+	}
+	return chunks, nil
+}
+
+// lineStart return the offset of the first line starting at or after off in
+// the size bytes of r, size when the line at off is the last one.
+func lineStart(r io.ReaderAt, off, size int64) (int64, error) {
+	if off <= 0 {
+		return 0, nil
+	}
+	// The line starts at off when it follows a newline, hence reading
+	// from the byte before it.
+	br := bufio.NewReader(io.NewSectionReader(r, off-1, size-off+1))
+	for pos := off - 1; ; {
+		b, err := br.ReadSlice('\n')
+		pos += int64(len(b))
+		switch {
+		case err == nil:
+			return pos, nil
+		case errors.Is(err, io.EOF):
+			return size, nil
+		case !errors.Is(err, bufio.ErrBufferFull):
+			return 0, err
+		}
+	}
+}
+
+// AnalyzeChunks analyze the uncompressed file at path split into up to n
+// chunks of whole lines, see SplitChunks, each chunk analyzed by its own
+// goroutine into its own report, and return the report merging them in file
+// order. Unlike AnalyzeContext, whose workers share the parsing but not the
+// aggregation, the chunks are aggregated concurrently too, speeding up the
+// analysis of a single large file on as many cores as chunks. The quantile
+// sketches of WithQuantileAccuracy merge as well as the response times.
+//
+// The invalid lines are numbered from the start of their chunk, and the
+// limits on them, such as WithMaxInvalidRatio, apply to each chunk apart.
+// Only the first chunk skips a header, see WithSkipHeader. The options are
+// otherwise the same as Analyze, except WithMaxEntries and WithMaxErrors
+// which depend on the lines before those of a chunk.
+func AnalyzeChunks(ctx context.Context, path string, n int, opts ...Option) (*AnalysisReport, error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if o.maxEntries > 0 || o.maxErrors > 0 {
+		return nil, fmt.Errorf("the max entries and max errors can not be applied to chunks")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := SplitChunks(f, fi.Size(), n)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	chunkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each chunk is parsed by its goroutine alone, the
+	// chunks running on the cores instead.
+	opts = append(opts[:len(opts):len(opts)], WithWorkers(1))
+	reports := make([]*AnalysisReport, len(chunks))
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chunkOpts := opts
+			if i > 0 {
+				chunkOpts = append(opts[:len(opts):len(opts)], WithSkipHeader(false))
+			}
+			in := newFileReader(path, io.NewSectionReader(f, c.Start, c.Len()), c.Start)
+			reports[i], errs[i] = AnalyzeContext(chunkCtx, in, chunkOpts...)
+			if failed(errs[i]) {
+				cancel() // the other chunks are of no use
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := o.newReport()
+	defer report.finish()
+	empty, interrupted := true, false
+	for i, err := range errs {
+		switch {
+		case errors.Is(err, ErrNoEntries):
+		case failed(err):
+			return report, err
+		case errors.As(err, new(*InterruptError)):
+			empty, interrupted = false, true
+		default:
+			empty = false
+		}
+		if err := report.Merge(reports[i]); err != nil {
+			return nil, err
+		}
+	}
+	if interrupted {
+		return report, &InterruptError{Line: report.TotalLines, Err: ctx.Err()}
+	}
+	if empty {
+		return report, ErrNoEntries
+	}
+	return report, nil
+}
+
+// failed report whether err is the error of a chunk which failed to be
+// analyzed, rather than of an empty or interrupted one.
+func failed(err error) bool {
+	return err != nil && !errors.Is(err, ErrNoEntries) && !errors.As(err, new(*InterruptError))
+}
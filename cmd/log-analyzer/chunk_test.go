@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSplitChunks(t *testing.T) {
+	long := strings.Repeat("y", 10_000) // longer than the bufio buffer
+	for _, tt := range []struct {
+		name string
+		text string
+		n    int
+		want []string
+	}{
+		{"line starts", "aa\nbb\ncc\n", 3, []string{"aa\n", "bb\n", "cc\n"}},
+		{"mid-line", "a\nbb\nccc\n", 3, []string{"a\nbb\n", "ccc\n"}},
+		{"single chunk", "a\nbb\n", 1, []string{"a\nbb\n"}},
+		{"fewer bytes than chunks", "a\n", 8, []string{"a\n"}},
+		{"long line", "x\n" + long + "\nz\n", 4, []string{"x\n" + long + "\n", "z\n"}},
+		{"no final newline", "aa\nbb", 2, []string{"aa\n", "bb"}},
+		{"empty", "", 4, nil},
+	} {
+		r := strings.NewReader(tt.text)
+		chunks, err := SplitChunks(r, int64(len(tt.text)), tt.n)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, c := range chunks {
+			got = append(got, tt.text[c.Start:c.End])
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got chunks %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if _, err := SplitChunks(strings.NewReader(""), 0, 0); err == nil {
+		t.Error("accepted 0 chunks")
+	}
+}
+
+// The report of the chunks is the report of the whole file, whatever the
+// number of chunks, an invalid line being counted once.
+func TestAnalyzeChunks(t *testing.T) {
+	text := logText(generateEntries(5000, 1))
+	i := len(text) / 2
+	text = text[:i] + "\nnot a log line\n" + text[i:] // splitting a line in two
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := []Option{WithTopN(5), WithPercentiles(50, 99), WithInvalidLineHandler(DiscardInvalidLine)}
+	want, err := AnalyzeReader(strings.NewReader(text), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wantText strings.Builder
+	want.Fprint(&wantText)
+
+	for _, n := range []int{1, 3, 7, 64} {
+		got, err := AnalyzeChunks(context.Background(), path, n, opts...)
+		if err != nil {
+			t.Fatalf("%d chunks: %v", n, err)
+		}
+		if got.TotalLines != want.TotalLines || got.SkippedLines != 3 || !reflect.DeepEqual(got.MsgFrequency, want.MsgFrequency) {
+			t.Errorf("%d chunks: got %d lines, %d skipped, want %d lines, 3 skipped", n, got.TotalLines, got.SkippedLines, want.TotalLines)
+		}
+		var gotText strings.Builder
+		got.Fprint(&gotText)
+		if gotText.String() != wantText.String() {
+			t.Errorf("%d chunks: got report\n%s\nwant\n%s", n, gotText.String(), wantText.String())
+		}
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.log")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AnalyzeChunks(context.Background(), empty, 4); !errors.Is(err, ErrNoEntries) {
+		t.Errorf("got %v, want ErrNoEntries for an empty file", err)
+	}
+	if _, err := AnalyzeChunks(context.Background(), path, 4, WithMaxErrors(1)); err == nil {
+		t.Error("accepted the max errors for chunks")
+	}
+}
+
+// Only the first chunk skips the header, the invalid lines of the
+// others failing the analysis as in a serial one.
+func TestAnalyzeChunksHeader(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"=== banner ===",
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 INFO Request processed in 20 ms",
+		"truncated",
+		"2025-01-01 10:00:02 ERROR Connection lost",
+	)
+	report, err := AnalyzeChunks(context.Background(), path, 4, WithSkipHeader(true), WithInvalidLineHandler(DiscardInvalidLine))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalLines != 4 || report.SkippedLines != 1 {
+		t.Errorf("got %d lines, %d skipped, want 4 lines, 1 skipped", report.TotalLines, report.SkippedLines)
+	}
+	_, err = AnalyzeChunks(context.Background(), path, 4, WithSkipHeader(true), WithStrict(true), WithInvalidLineHandler(DiscardInvalidLine))
+	if !errors.As(err, new(*InvalidInputError)) {
+		t.Errorf("got %v, want the truncated line failing the strict analysis", err)
+	}
+}
+
+func BenchmarkAnalyzeChunks(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(logText(generateEntries(200_000, 0))), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	opts := []Option{WithInvalidLineHandler(DiscardInvalidLine)}
+	b.Run("pipeline", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			f, err := os.Open(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := AnalyzeReader(f, opts...); err != nil {
+				b.Fatal(err)
+			}
+			f.Close()
+		}
+	})
+	b.Run("chunks", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := AnalyzeChunks(context.Background(), path, runtime.GOMAXPROCS(0), opts...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
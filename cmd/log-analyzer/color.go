@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// Color modes of the human readable report, see -color.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// ANSI escape codes of the colors used in the human readable report.
+const (
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+// palette color text written to the output, the zero value leaving it plain.
+type palette struct {
+	enabled bool
+}
+
+// paletteFor return the palette of the output w as selected by -color. In
+// auto mode only a terminal is colored, unless the NO_COLOR environment
+// variable is set to a non-empty value, see https://no-color.org. Any other
+// writer than a terminal, such as a pipe, a file or an HTTP response, gets
+// plain text without escape codes.
+func paletteFor(w io.Writer) palette {
+	switch *colorMode {
+	case ColorAlways:
+		return palette{enabled: true}
+	case ColorNever:
+		return palette{}
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return palette{}
+	}
+	f, ok := w.(*os.File)
+	return palette{enabled: ok && isTerminal(f)}
+}
+
+// paint return s in the color, or s itself if the palette is disabled.
+func (p palette) paint(color, s string) string {
+	if !p.enabled {
+		return s
+	}
+	return color + s + colorReset
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setColorMode set -color for the test.
+func setColorMode(t *testing.T, mode string) {
+	t.Helper()
+	old := *colorMode
+	*colorMode = mode
+	t.Cleanup(func() { *colorMode = old })
+}
+
+// The report written to a pipe, a file or a buffer has no escape codes.
+func TestColorNotTerminal(t *testing.T) {
+	report, err := Analyze(generateEntries(100, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Warn == 0 || report.Error == 0 {
+		t.Fatalf("got %d warnings and %d errors, want both to be highlighted", report.Warn, report.Error)
+	}
+	setColorMode(t, ColorAuto)
+	var b strings.Builder
+	report.Fprint(&b)
+	if strings.Contains(b.String(), "\033[") {
+		t.Errorf("got escape codes written to a buffer:\n%q", b.String())
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if paletteFor(f).enabled {
+		t.Error("colored the report written to a file")
+	}
+
+	setColorMode(t, ColorAlways)
+	b.Reset()
+	report.Fprint(&b)
+	if !strings.Contains(b.String(), colorRed+"ERROR: ") || !strings.Contains(b.String(), colorYellow+"WARN: ") {
+		t.Errorf("got\n%q\nwant the warnings and errors highlighted with -color always", b.String())
+	}
+	setColorMode(t, ColorNever)
+	b.Reset()
+	report.Fprint(&b)
+	if strings.Contains(b.String(), "\033[") {
+		t.Errorf("got escape codes with -color never:\n%q", b.String())
+	}
+}
+
+// NO_COLOR disable the colors of a terminal in auto mode.
+func TestColorNoColor(t *testing.T) {
+	// A character device is detected as a terminal.
+	tty, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil || !isTerminal(tty) {
+		t.Skipf("no character device: %v", err)
+	}
+	defer tty.Close()
+	setColorMode(t, ColorAuto)
+	for value, enabled := range map[string]bool{"": true, "1": false} {
+		t.Setenv("NO_COLOR", value)
+		if got := paletteFor(tty).enabled; got != enabled {
+			t.Errorf("NO_COLOR=%q: got the colors enabled %v, want %v", value, got, enabled)
+		}
+	}
+	if got := (palette{}).paint(colorRed, "ERROR"); got != "ERROR" {
+		t.Errorf("got %q painted without colors", got)
+	}
+}
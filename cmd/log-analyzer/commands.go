@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// command is a log-analyzer subcommand.
+type command struct {
+	name  string
+	usage string // arguments following the command name
+	help  string
+	run   func(args []string)
+}
+
+var commands []command
+
+func init() {
+	// Assigned in init as the commands refer back to it through newFlagSet.
+	commands = []command{
+		{"analyze", "[OPTION] filename", "analyze the file and print the report, the default", analyze},
+		{"diff", "[OPTION] old new", "print the changes between the reports of two log files or saved reports", diff},
+		{"tail", "[OPTION] filename", "follow the file as it grows and print the report periodically", tail},
+		{"serve", "[OPTION] [-listen addr] filename", "follow the file and serve its report over HTTP", serve},
+		{"export", "[OPTION] -output file filename", "export the filtered entries to a parquet file", export},
+		{"validate", "[OPTION] filename", "report the ratio of lines which can be parsed", validate},
+	}
+}
+
+// lookupCommand return the command called name, or nil if there is none.
+func lookupCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// Names of the top level flags shared by the subcommands.
+var (
+	parseFlags  = []string{"input", "delimiter", "no-level", "source-prefix", "skip-matching", "strip-prefix", "workers", "chunks", "read-rate", "config", "quiet", "verbose", "strict", "max-invalid-ratio", "max-errors", "skip-header"}
+	filterFlags = []string{"level", "min-severity", "max-severity", "start", "end", "at", "around", "match", "explain"}
+	reportFlags = []string{"top", "percentiles", "timeline", "normalize", "group-regex", "include-empty", "include-unparseable", "max-message-len", "max-unique-messages", "quantile-accuracy", "sample-size", "seed", "metric"}
+	outputFlags = []string{"format", "pretty", "color", "graphite-prefix", "template-file", "humanize"}
+	followFlags = []string{"interval", "report-every", "follow-mode"}
+)
+
+// newFlagSet return the flag set of the command called name, holding the
+// top level flags of the given groups. Those flags share their value with
+// the top level ones, so the analysis set up is the same for every command.
+func newFlagSet(name string, groups ...[]string) *flag.FlagSet {
+	cmd := lookupCommand(name)
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	for _, group := range groups {
+		for _, name := range group {
+			f := flag.Lookup(name)
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of log-analyzer %s:\n", cmd.name)
+		fmt.Fprintf(fs.Output(), "\tlog-analyzer %s %s\n", cmd.name, cmd.usage)
+		fmt.Fprintf(fs.Output(), "%s.\n", cmd.help)
+		fmt.Fprintf(fs.Output(), "Flags:\n")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// analyze run the analyze subcommand, accepting all the top level flags.
+func analyze(args []string) {
+	flag.CommandLine.Parse(args)
+	runAnalysis(flag.Args())
+}
+
+// tail run the tail subcommand, analyze in follow mode.
+func tail(args []string) {
+	fs := newFlagSet("tail", parseFlags, filterFlags, reportFlags, outputFlags, followFlags)
+	fs.Parse(args)
+	*follow = true
+	runAnalysis(fs.Args())
+}
+
+// export run the export subcommand, writing the filtered entries to -output.
+func export(args []string) {
+	fs := newFlagSet("export", parseFlags, filterFlags, []string{"output"})
+	fs.Parse(args)
+	*format = FormatParquet
+	runAnalysis(fs.Args())
+}
+
+// diff run the diff subcommand, printing the changes from the report of
+// the first file to the report of the second. Each file is either a log
+// file, analyzed as selected by the flags, or a report saved with -save.
+func diff(args []string) {
+	fs := newFlagSet("diff", parseFlags, filterFlags, reportFlags)
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	_, opts := setup()
+
+	var reports [2]*AnalysisReport
+	for i, file := range fs.Args() {
+		report, err := loadOrAnalyze(file, opts)
+		if errors.As(err, new(*InvalidInputError)) {
+			exitInvalidInput(err)
+		} else if err != nil {
+			log.Fatalln(err)
+		}
+		reports[i] = report
+	}
+	invalidLines.Flush()
+	if err := reports[1].Delta(reports[0]).Print(os.Stdout); err != nil {
+		log.Fatalln("failed to write report: ", err)
+	}
+}
+
+// loadOrAnalyze return the report of the log file at path analyzed with
+// opts, or the report saved at path if it is not a log file.
+func loadOrAnalyze(path string, opts []Option) (*AnalysisReport, error) {
+	if !isLogFile(path) {
+		return Load(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	report, err := AnalyzeReader(newFileReader(path, f, 0), opts...)
+	if errors.Is(err, ErrNoEntries) {
+		return report, nil
+	}
+	return report, err
+}
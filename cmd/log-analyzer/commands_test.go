@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// commandLog is the log of the subcommand smoke tests.
+var commandLog = []string{
+	"2025-01-01 10:00:00 INFO Starting the application",
+	"2025-01-01 10:00:01 WARN Memory usage is high",
+	"2025-01-01 10:00:02 ERROR Connection lost",
+}
+
+// lockedBuffer is a bytes.Buffer written by a child process
+// while the test reads it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// startMain start log-analyzer with args in a child process, as runMain,
+// for the commands running until interrupted. The process is interrupted
+// and waited for by the returned function, returning its exit status.
+func startMain(t *testing.T, args ...string) (stdout, stderr *lockedBuffer, stop func() int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=^$", "--"}, args...)...)
+	cmd.Env = append(os.Environ(), mainEnv+"=1")
+	stdout, stderr = new(lockedBuffer), new(lockedBuffer)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	var once sync.Once
+	var status int
+	stop = func() int {
+		once.Do(func() {
+			cmd.Process.Signal(syscall.SIGINT)
+			if err := cmd.Wait(); err != nil {
+				status = -1
+				if exitErr, ok := err.(*exec.ExitError); ok {
+					status = exitErr.ExitCode()
+				}
+			}
+		})
+		return status
+	}
+	t.Cleanup(func() { stop() })
+	return stdout, stderr, stop
+}
+
+// waitFor wait up to 10s for cond to hold.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); !cond(); time.Sleep(20 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestCommandAnalyze(t *testing.T) {
+	path := writeLines(t, "app.log", commandLog...)
+	want, _, _ := runMain(t, "-level", "info,warn,error", "-top", "2", path)
+	stdout, stderr, status := runMain(t, "analyze", "-level", "info,warn,error", "-top", "2", path)
+	if status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	if !strings.Contains(stdout, "Total Log Entries: 3") || stdout != want {
+		t.Errorf("got\n%s\nwant the report without subcommand\n%s", stdout, want)
+	}
+}
+
+func TestCommandDiff(t *testing.T) {
+	old := writeLines(t, "old.log", commandLog[:1]...)
+	path := writeLines(t, "new.log", commandLog...)
+	stdout, stderr, status := runMain(t, "diff", "-level", "info,warn,error", old, path)
+	if status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	for _, want := range []string{"Total Log Entries: +2", "WARN: +1", "ERROR: +1"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("got\n%s\nwithout %q", stdout, want)
+		}
+	}
+	if _, stderr, status := runMain(t, "diff", old); status != 2 || !strings.Contains(stderr, "Usage of log-analyzer diff:") {
+		t.Errorf("got the exit status %d and %q with a single file, want 2 and the usage", status, stderr)
+	}
+}
+
+func TestCommandTail(t *testing.T) {
+	path := writeLines(t, "app.log", commandLog...)
+	stdout, stderr, stop := startMain(t, "tail", "-level", "info,warn,error", "-interval", "50ms", path)
+	waitFor(t, "the report", func() bool { return strings.Contains(stdout.String(), "Total Log Entries: 3") })
+	appendFile(t, path, "2025-01-01 10:00:03 ERROR Connection lost\n")
+	waitFor(t, "the report of the appended line", func() bool { return strings.Contains(stdout.String(), "Total Log Entries: 4") })
+	if status := stop(); status != 0 {
+		t.Errorf("exit status %d: %s", status, stderr)
+	}
+}
+
+func TestCommandServe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	path := writeLines(t, "app.log", commandLog...)
+	_, stderr, stop := startMain(t, "serve", "-level", "info,warn,error", "-listen", addr, "-interval", "50ms", path)
+	waitFor(t, "the served report", func() bool {
+		resp, err := http.Get("http://" + addr + "/report.txt")
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		var b bytes.Buffer
+		b.ReadFrom(resp.Body)
+		return resp.StatusCode == http.StatusOK && strings.Contains(b.String(), "Total Log Entries: 3")
+	})
+	if status := stop(); status != 0 {
+		t.Errorf("exit status %d: %s", status, stderr)
+	}
+}
+
+func TestCommandExport(t *testing.T) {
+	path := writeLines(t, "app.log", commandLog...)
+	out := filepath.Join(t.TempDir(), "entries.parquet")
+	if _, stderr, status := runMain(t, "export", "-level", "warn,error", "-output", out, path); status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := parquet.Read[parquetEntry](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Message != "Memory usage is high" || rows[1].Message != "Connection lost" {
+		t.Errorf("got the rows %+v, want the warn and error entries", rows)
+	}
+}
+
+func TestCommandValidate(t *testing.T) {
+	path := writeLines(t, "app.log", commandLog...)
+	if stdout, stderr, status := runMain(t, "validate", path); status != 0 || !strings.Contains(stdout, "(100.00%)") {
+		t.Errorf("got the exit status %d and\n%s%s\nwant 0 and every line parsed", status, stdout, stderr)
+	}
+	if _, stderr, status := runMain(t, "validate"); status != 2 || !strings.Contains(stderr, "Usage of log-analyzer validate:") {
+		t.Errorf("got the exit status %d and %q without file, want 2 and the usage", status, stderr)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// configFile is the config file used when -config is not given,
+// looked up in the working directory.
+const configFile = ".log-analyzer.yaml"
+
+// configValue is a flag value set by a config file.
+type configValue struct {
+	line  int
+	key   string
+	value string
+}
+
+// ParseConfig parse a config file setting flag defaults, one flag per key.
+// Only a flat subset of YAML is supported: "key: value" pairs, comments,
+// quoted strings and lists, either "key: [a, b]" or "- a" items below the
+// key, which are joined with commas, e.g:
+//
+//	level: [info, warn, error]
+//	format: json
+//	normalize: true
+func ParseConfig(r io.Reader) ([]configValue, error) {
+	var (
+		values []configValue
+		list   *configValue // key whose list items follow
+	)
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(s.Text()))
+		if line == "" || line == "---" {
+			continue
+		}
+		if item, ok := strings.CutPrefix(line, "- "); ok {
+			if list == nil {
+				return nil, fmt.Errorf("line %d: list item without a key", n)
+			}
+			if list.value != "" {
+				list.value += ","
+			}
+			list.value += unquote(strings.TrimSpace(item))
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected 'key: value', got %q", n, line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		for _, v := range values {
+			if v.key == key {
+				return nil, fmt.Errorf("line %d: duplicate key %q, first set on line %d", n, key, v.line)
+			}
+		}
+		if inner, ok := strings.CutPrefix(value, "["); ok {
+			inner, ok = strings.CutSuffix(inner, "]")
+			if !ok {
+				return nil, fmt.Errorf("line %d: unterminated list", n)
+			}
+			items := strings.Split(inner, ",")
+			for i, item := range items {
+				items[i] = unquote(strings.TrimSpace(item))
+			}
+			value = strings.Join(items, ",")
+		} else {
+			value = unquote(value)
+		}
+		values = append(values, configValue{line: n, key: key, value: value})
+		list = nil
+		if value == "" {
+			list = &values[len(values)-1]
+		}
+	}
+	return values, s.Err()
+}
+
+// stripComment remove a trailing # comment from line,
+// ignoring a # within quotes.
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// loadConfig set the defaults of the top level flags from the config file
+// given with -config in args, or else configFile if it exists, so the
+// flags given on the command line override the config file.
+func loadConfig(args []string) error {
+	path, explicit := configPath(args)
+	f, err := os.Open(path)
+	if !explicit && errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	defer f.Close()
+
+	values, err := ParseConfig(f)
+	if err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	for _, v := range values {
+		fl := flag.Lookup(v.key)
+		if fl == nil || v.key == "config" {
+			msg := fmt.Sprintf("config %s: line %d: unknown key %q", path, v.line, v.key)
+			if s := suggestFlag(v.key); s != "" {
+				msg += fmt.Sprintf(", did you mean %q?", s)
+			}
+			return errors.New(msg)
+		}
+		if err := fl.Value.Set(v.value); err != nil {
+			return fmt.Errorf("config %s: line %d: invalid value %q for %s: %w", path, v.line, v.value, v.key, err)
+		}
+	}
+	return nil
+}
+
+// configPath return the value of the -config flag in args, stopping at
+// the first "--", and whether it was given rather than the default.
+func configPath(args []string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value, true
+		}
+		if i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return configFile, false
+}
+
+// suggestFlag return the name of the flag closest to name,
+// or an empty string if none is close enough.
+func suggestFlag(name string) string {
+	best, bestDist := "", 3
+	flag.VisitAll(func(f *flag.Flag) {
+		if d := editDistance(name, f.Name); d < bestDist {
+			best, bestDist = f.Name, d
+		}
+	})
+	return best
+}
+
+// editDistance return the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	values, err := ParseConfig(strings.NewReader(`---
+# defaults of the nightly run
+level: [info, "warn", error]
+format: json # the dashboards read json
+match: "a # b"
+percentiles:
+  - 50
+  - '99'
+normalize: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []configValue{
+		{3, "level", "info,warn,error"},
+		{4, "format", "json"},
+		{5, "match", "a # b"},
+		{6, "percentiles", "50,99"},
+		{9, "normalize", "true"},
+	}
+	if len(values) != len(want) {
+		t.Fatalf("got %+v, want %+v", values, want)
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("value %d: got %+v, want %+v", i, values[i], want[i])
+		}
+	}
+
+	for _, tt := range []struct{ config, err string }{
+		{"- info", "line 1: list item without a key"},
+		{"level info", "line 1: expected 'key: value'"},
+		{"level: [info, warn", "line 1: unterminated list"},
+		{"top: 3\ntop: 5", `line 2: duplicate key "top", first set on line 1`},
+	} {
+		if _, err := ParseConfig(strings.NewReader(tt.config)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseConfig(%q): got %v, want an error of %q", tt.config, err, tt.err)
+		}
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		path     string
+		explicit bool
+	}{
+		{[]string{"app.log"}, configFile, false},
+		{[]string{"-config", "a.yaml", "app.log"}, "a.yaml", true},
+		{[]string{"--config=b.yaml", "app.log"}, "b.yaml", true},
+		{[]string{"--", "-config", "a.yaml"}, configFile, false},
+	} {
+		if path, explicit := configPath(tt.args); path != tt.path || explicit != tt.explicit {
+			t.Errorf("configPath(%q) = %q, %v, want %q, %v", tt.args, path, explicit, tt.path, tt.explicit)
+		}
+	}
+}
+
+// The flags given on the command line override the config file,
+// which overrides the defaults of the flags.
+func TestConfigPrecedence(t *testing.T) {
+	logPath := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 ERROR Connection lost")
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, []byte("format: json\nlevel: [error]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default", []string{logPath}, "Total Log Entries: 1\nDEBUG: 0\nINFO: 1\n"},
+		{"config", []string{"-config", config, logPath}, `{"total_entries":1,"info":0,"warn":0,"error":1,`},
+		{"flag", []string{"-config", config, "-level", "info", logPath}, `{"total_entries":1,"info":1,"warn":0,"error":0,`},
+		{"flag after", []string{"-config", config, "-format", "text", logPath}, "Total Log Entries: 1\nDEBUG: 0\nINFO: 0\n"},
+	} {
+		stdout, stderr, status := runMain(t, tt.args...)
+		if status != 0 || !strings.HasPrefix(stdout, tt.want) {
+			t.Errorf("%s: got %q, %q, exit %d, want the output starting with %q", tt.name, stdout, stderr, status, tt.want)
+		}
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	logPath := writeLines(t, "app.log", "2025-01-01 10:00:00 INFO Started")
+	dir := t.TempDir()
+	for _, tt := range []struct{ config, err string }{
+		{"formt: json", `line 1: unknown key "formt", did you mean "format"?`},
+		{"config: other.yaml", `line 1: unknown key "config"`},
+		{"top: many", `line 1: invalid value "many" for top`},
+	} {
+		config := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(config, []byte(tt.config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, stderr, status := runMain(t, "-config", config, logPath)
+		if status == 0 || !strings.Contains(stderr, tt.err) {
+			t.Errorf("%q: got %q, exit %d, want an error of %q", tt.config, stderr, status, tt.err)
+		}
+	}
+	if _, stderr, status := runMain(t, "-config", filepath.Join(dir, "missing.yaml"), logPath); status == 0 || !strings.Contains(stderr, "config:") {
+		t.Errorf("got %q, exit %d, want an error for the missing config file", stderr, status)
+	}
+}
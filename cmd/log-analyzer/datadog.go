@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// datadogIntakeURL is the url of the Datadog Logs API of the US1 site.
+const datadogIntakeURL = "https://http-intake.logs.datadoghq.com/api/v2/logs"
+
+// datadogMaxBatch is the maximum number of entries the Logs API
+// accepts in a single request.
+const datadogMaxBatch = 1000
+
+// datadogSource is the ddsource of the entries sent without Source.
+const datadogSource = "log-analyzer"
+
+// datadogLog is an entry of the Datadog Logs API, its timestamp
+// being in milliseconds since the epoch.
+type datadogLog struct {
+	Source    string `json:"ddsource"`
+	Tags      string `json:"ddtags,omitempty"`
+	Hostname  string `json:"hostname,omitempty"`
+	Service   string `json:"service,omitempty"`
+	Message   string `json:"message"`
+	Status    string `json:"status,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// DatadogClient send entries to the Datadog Logs API.
+type DatadogClient struct {
+	APIKey string
+	// URL is the intake url of the Datadog site, datadogIntakeURL if empty.
+	URL string
+	// Source is the ddsource of the entries, datadogSource if empty.
+	Source string
+	// Tags are the comma separated ddtags of the entries. e.g: 'env:prod'
+	Tags string
+	// Hostname is the host the entries come from.
+	Hostname string
+	// BatchSize is the number of entries sent per request,
+	// at most and by default datadogMaxBatch.
+	BatchSize int
+	// Client is the http client of the requests, one
+	// timing out after webhookTimeout if nil.
+	Client *http.Client
+}
+
+// Send post the entries to the Datadog Logs API as json arrays of
+// BatchSize entries. The status of an entry is its level, its service
+// its source and its message the message, see datadogLog.
+func (c *DatadogClient) Send(ctx context.Context, entries []LogEntry) error {
+	size := c.BatchSize
+	if size < 1 || size > datadogMaxBatch {
+		size = datadogMaxBatch
+	}
+	for len(entries) > 0 {
+		n := min(size, len(entries))
+		if err := c.send(ctx, entries[:n]); err != nil {
+			return err
+		}
+		entries = entries[n:]
+	}
+	return nil
+}
+
+// send post the entries to the Datadog Logs API in a single request.
+func (c *DatadogClient) send(ctx context.Context, entries []LogEntry) error {
+	source := c.Source
+	if source == "" {
+		source = datadogSource
+	}
+	logs := make([]datadogLog, len(entries))
+	for i, e := range entries {
+		logs[i] = datadogLog{
+			Source:    source,
+			Tags:      c.Tags,
+			Hostname:  c.Hostname,
+			Service:   e.source,
+			Message:   e.message,
+			Timestamp: e.time.UnixMilli(),
+		}
+		switch e.level {
+		case LevelNone:
+		case LevelUnknown:
+			logs[i].Status = strings.ToLower(e.rawLevel)
+		default:
+			logs[i].Status = e.level.String()
+		}
+	}
+	body, err := json.Marshal(logs)
+	if err != nil {
+		return err
+	}
+	url := c.URL
+	if url == "" {
+		url = datadogIntakeURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", c.APIKey)
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("datadog %s: unexpected status %s", url, resp.Status)
+	}
+	return nil
+}
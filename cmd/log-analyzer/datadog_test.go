@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDatadogClientSend(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]datadogLog
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("DD-API-KEY"); key != "secret" {
+			t.Errorf("got the api key %q, want secret", key)
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got a %s request of %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var logs []datadogLog
+		if err := json.Unmarshal(body, &logs); err != nil {
+			t.Errorf("invalid logs %q: %v", body, err)
+		}
+		mu.Lock()
+		batches = append(batches, logs)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	at := time.Date(2024, 1, 15, 10, 34, 56, 123e6, time.UTC)
+	entries := []LogEntry{
+		{time: at, level: LevelError, rawLevel: "ERROR", source: "api", message: "Failed to connect"},
+		{time: at, level: LevelWarn, rawLevel: "[WARNING]", message: "Memory usage is high"},
+		{time: at, rawLevel: "AUDIT", message: "User logged in"},
+		{time: at, level: LevelNone, message: "Started"},
+		{time: at, level: LevelInfo, rawLevel: "INFO", message: "Request processed in 10 ms"},
+	}
+	client := &DatadogClient{APIKey: "secret", URL: srv.URL, Tags: "env:prod", Hostname: "web-1", BatchSize: 2}
+	if err := client.Send(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batches) != 3 {
+		t.Fatalf("got %d requests, want 3 batches of at most 2 entries", len(batches))
+	}
+	want := datadogLog{Source: datadogSource, Tags: "env:prod", Hostname: "web-1", Service: "api", Message: "Failed to connect", Status: "error", Timestamp: at.UnixMilli()}
+	if got := batches[0][0]; got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	var statuses []string
+	for _, logs := range batches {
+		for _, l := range logs {
+			statuses = append(statuses, l.Status)
+		}
+	}
+	if got := strings.Join(statuses, ","); got != "error,warn,audit,,info" {
+		t.Errorf("got the statuses %s, want error,warn,audit,,info", got)
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+	if err := client.Send(context.Background(), entries); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("got %v, want the rejected request reported", err)
+	}
+}
+
+// The filtered entries are sent to -datadog-url with the api key.
+func TestFormatDatadog(t *testing.T) {
+	var got []datadogLog
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("DD-API-KEY"); key != "secret" {
+			t.Errorf("got the api key %q, want secret", key)
+		}
+		var logs []datadogLog
+		json.NewDecoder(r.Body).Decode(&logs)
+		got = append(got, logs...)
+	}))
+	defer srv.Close()
+
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO Started",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+	)
+	if _, stderr, status := runMain(t, "-format", "datadog", "-datadog-api-key", "secret", "-datadog-url", srv.URL, "-datadog-tags", "env:test", "-level", "error", path); status != 0 {
+		t.Fatalf("got %q, exit %d", stderr, status)
+	}
+	if len(got) != 1 || got[0].Message != "Connection lost" || got[0].Tags != "env:test" || got[0].Source != datadogSource {
+		t.Errorf("got %+v, want the error entry", got)
+	}
+	if _, stderr, status := runMain(t, "-format", "datadog", path); status == 0 || !strings.Contains(stderr, "-datadog-api-key") {
+		t.Errorf("got %q, exit %d without an api key, want an error", stderr, status)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// errZeroTime is the error of a timestamp of the zero time, 0001-01-01
+// 00:00:00 UTC, which the analysis takes for the time of no entry.
+var errZeroTime = errors.New("zero time")
+
+// checkTime return t, or errZeroTime when it is the zero time.
+func checkTime(t time.Time) (time.Time, error) {
+	if t.IsZero() {
+		return time.Time{}, errZeroTime
+	}
+	return t, nil
+}
+
+// parseDateTime parse s in the time.DateTime layout, as time.Parse does but
+// without its allocations for the common well formed timestamps. Any other
+// input is left to time.Parse, so the errors are the same, the zero time
+// being rejected with errZeroTime.
+func parseDateTime(s string) (time.Time, error) {
+	// 2006-01-02 15:04:05
+	if len(s) == len(time.DateTime) && s[4] == '-' && s[7] == '-' && s[10] == ' ' && s[13] == ':' && s[16] == ':' {
+		year, ok1 := atoiFixed(s[0:4])
+		month, ok2 := atoiFixed(s[5:7])
+		day, ok3 := atoiFixed(s[8:10])
+		hour, ok4 := atoiFixed(s[11:13])
+		minute, ok5 := atoiFixed(s[14:16])
+		sec, ok6 := atoiFixed(s[17:19])
+		if ok1 && ok2 && ok3 && ok4 && ok5 && ok6 &&
+			month >= 1 && month <= 12 && day >= 1 && day <= daysIn(time.Month(month), year) &&
+			hour < 24 && minute < 60 && sec < 60 {
+			return checkTime(time.Date(year, time.Month(month), day, hour, minute, sec, 0, time.UTC))
+		}
+	}
+	t, err := time.Parse(time.DateTime, s)
+	if err != nil {
+		return t, err
+	}
+	return checkTime(t)
+}
+
+// atoiFixed return the value of s made of decimal digits only.
+func atoiFixed(s string) (int, bool) {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
+
+// daysIn return the number of days of the month of year.
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// The fast path parse the timestamps as time.Parse, errors included.
+func TestParseDateTime(t *testing.T) {
+	for _, s := range []string{
+		"2025-01-01 10:00:00",
+		"2024-02-29 23:59:59",
+		"2025-02-29 10:00:00",
+		"2025-04-31 10:00:00",
+		"2025-13-01 10:00:00",
+		"2025-00-10 10:00:00",
+		"2025-01-00 10:00:00",
+		"2025-01-01 24:00:00",
+		"2025-01-01 10:60:00",
+		"2025-01-01 10:00:60",
+		"2025-01-01T10:00:00",
+		"2025-01-01 10:00:0x",
+		"+025-01-01 10:00:00",
+		"2025-01-01 10:00",
+		"",
+	} {
+		want, wantErr := time.Parse(time.DateTime, s)
+		got, err := parseDateTime(s)
+		if !got.Equal(want) || got.Location() != want.Location() || (err == nil) != (wantErr == nil) || (err != nil && err.Error() != wantErr.Error()) {
+			t.Errorf("parseDateTime(%q) = %v, %v, want %v, %v", s, got, err, want, wantErr)
+		}
+	}
+}
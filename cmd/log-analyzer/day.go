@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// PeriodSummary is the summary of the entries of a period of time, the
+// calendar day of GroupByDay or the time window of GroupByWindow.
+type PeriodSummary struct {
+	Start         time.Time // midnight starting the day, or the window start
+	Total         int
+	Errors        int
+	Warns         int
+	ResponseCount int
+	ResponseSum   float64 // in ms
+}
+
+// AverageResponseTime return the average response time of
+// the period in ms, or false if no entry had a response time.
+func (p PeriodSummary) AverageResponseTime() (float64, bool) {
+	if p.ResponseCount == 0 {
+		return 0, false
+	}
+	return p.ResponseSum / float64(p.ResponseCount), true
+}
+
+// GroupByDay summarize the entries per calendar day, in the location of
+// their time, returning one summary per day having entries in
+// chronological order.
+func GroupByDay(entries []LogEntry) []PeriodSummary {
+	return summarize(entries, func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	})
+}
+
+// GroupByWindow summarize the entries per window of the given width aligned
+// on the UTC clock, e.g. 10 minutes from 10:00, 10:10 and so on, returning a summary per window
+// from the first to the last having entries in chronological order. Unlike
+// GroupByDay the windows without entries are summarized too, so the
+// summaries are a time series of the trend.
+func GroupByWindow(entries []LogEntry, width time.Duration) []PeriodSummary {
+	if width <= 0 {
+		return nil
+	}
+	windows := summarize(entries, func(t time.Time) time.Time {
+		return t.Truncate(width)
+	})
+	var series []PeriodSummary
+	for _, w := range windows {
+		if n := len(series); n > 0 {
+			for start := series[n-1].Start.Add(width); start.Before(w.Start); start = start.Add(width) {
+				series = append(series, PeriodSummary{Start: start})
+			}
+		}
+		series = append(series, w)
+	}
+	return series
+}
+
+// summarize summarize the entries by the start of the period of their time,
+// returning one summary per period having entries in chronological order.
+func summarize(entries []LogEntry, period func(time.Time) time.Time) []PeriodSummary {
+	periods := make(map[time.Time]*PeriodSummary)
+	for _, entry := range entries {
+		start := period(entry.time)
+		p, ok := periods[start]
+		if !ok {
+			p = &PeriodSummary{Start: start}
+			periods[start] = p
+		}
+		p.Total++
+		switch entry.level {
+		case LevelError:
+			p.Errors++
+		case LevelWarn:
+			p.Warns++
+		}
+		if v, ok := responseTime(entry.message); ok {
+			p.ResponseCount++
+			p.ResponseSum += v
+		}
+	}
+	summaries := make([]PeriodSummary, 0, len(periods))
+	for _, p := range periods {
+		summaries = append(summaries, *p)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Start.Before(summaries[j].Start)
+	})
+	return summaries
+}
+
+// PrintDays write one row per day summary as a table.
+func PrintDays(w io.Writer, days []PeriodSummary) error {
+	return printSummaries(w, days, "DATE", time.DateOnly)
+}
+
+// PrintWindowStats write one row per window summary of
+// GroupByWindow as a table.
+func PrintWindowStats(w io.Writer, windows []PeriodSummary) error {
+	return printSummaries(w, windows, "WINDOW", time.DateTime)
+}
+
+// printSummaries write one row per summary as a table,
+// the first column being its start in the layout.
+func printSummaries(w io.Writer, summaries []PeriodSummary, column, layout string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tTOTAL\tERRORS\tWARNS\tAVG RESPONSE (ms)\n", column)
+	for _, p := range summaries {
+		avg := "-"
+		if v, ok := p.AverageResponseTime(); ok {
+			avg = fmt.Sprintf("%.2f", v)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n", p.Start.Format(layout), p.Total, p.Errors, p.Warns, avg)
+	}
+	return tw.Flush()
+}
+
+// WriteDaysCSV write the day summaries to w as CSV with a header row, the
+// average response time being empty for the days without response times.
+func WriteDaysCSV(w io.Writer, days []PeriodSummary) error {
+	return writeSummariesCSV(w, days, "date", time.DateOnly)
+}
+
+// WriteWindowStatsCSV write the window summaries of GroupByWindow to w
+// as CSV, as WriteDaysCSV.
+func WriteWindowStatsCSV(w io.Writer, windows []PeriodSummary) error {
+	return writeSummariesCSV(w, windows, "window", time.DateTime)
+}
+
+// writeSummariesCSV write the summaries to w as CSV with a header row,
+// the first column being their start in the layout.
+func writeSummariesCSV(w io.Writer, summaries []PeriodSummary, column, layout string) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{column, "total", "errors", "warns", "avg_response_ms"})
+	for _, p := range summaries {
+		var avg string
+		if v, ok := p.AverageResponseTime(); ok {
+			avg = strconv.FormatFloat(v, 'f', 2, 64)
+		}
+		cw.Write([]string{p.Start.Format(layout), strconv.Itoa(p.Total), strconv.Itoa(p.Errors), strconv.Itoa(p.Warns), avg})
+	}
+	cw.Flush()
+	return cw.Error()
+}
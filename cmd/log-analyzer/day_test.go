@@ -0,0 +1,160 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupByDay(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-02 08:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:00 INFO Request processed in 20 ms",
+		"2025-01-01 23:59:59 ERROR Connection lost",
+		"2025-01-02 09:00:00 WARN Memory usage is high",
+		"2025-01-02 10:00:00 ERROR Request failed in 30 ms",
+		"2025-01-02 11:00:00 error Connection lost",
+		"2025-01-04 00:00:00 DEBUG Cache hit",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	want := []PeriodSummary{
+		{Start: day(1), Total: 2, Errors: 1, ResponseCount: 1, ResponseSum: 20},
+		{Start: day(2), Total: 4, Errors: 2, Warns: 1, ResponseCount: 2, ResponseSum: 40},
+		{Start: day(4), Total: 1},
+	}
+	got := GroupByDay(entries)
+	if len(got) != len(want) {
+		t.Fatalf("got %d days, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || got[i].Total != want[i].Total || got[i].Errors != want[i].Errors ||
+			got[i].Warns != want[i].Warns || got[i].ResponseCount != want[i].ResponseCount || got[i].ResponseSum != want[i].ResponseSum {
+			t.Errorf("day %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	var b strings.Builder
+	if err := PrintDays(&b, got); err != nil {
+		t.Fatal(err)
+	}
+	wantTable := `DATE        TOTAL  ERRORS  WARNS  AVG RESPONSE (ms)
+2025-01-01  2      1       0      20.00
+2025-01-02  4      2       1      20.00
+2025-01-04  1      0       0      -
+`
+	if b.String() != wantTable {
+		t.Errorf("got the table\n%s\nwant\n%s", b.String(), wantTable)
+	}
+	b.Reset()
+	if err := WriteDaysCSV(&b, got); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := `date,total,errors,warns,avg_response_ms
+2025-01-01,2,1,0,20.00
+2025-01-02,4,2,1,20.00
+2025-01-04,1,0,0,
+`
+	if b.String() != wantCSV {
+		t.Errorf("got the csv\n%s\nwant\n%s", b.String(), wantCSV)
+	}
+}
+
+func TestGroupByDayFlag(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO Started",
+		"2025-01-02 10:00:00 ERROR Connection lost")
+	stdout, stderr, status := runMain(t, "-group-by-day", "-format", "csv", "-level", "info,error", path)
+	if want := "2025-01-01,1,0,0,\n2025-01-02,1,1,0,\n"; status != 0 || !strings.HasSuffix(stdout, want) {
+		t.Errorf("got %q, exit %d, want %q\n%s", stdout, status, want, stderr)
+	}
+	if _, stderr, status := runMain(t, "-format", "csv", path); status != 1 || !strings.Contains(stderr, "requires -group-by-day") {
+		t.Errorf("got %q, exit %d, want -format csv rejected without -group-by-day", stderr, status)
+	}
+}
+
+func TestGroupByWindow(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:03:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:09:59 ERROR Request failed in 30 ms",
+		"2025-01-01 10:10:00 WARN Memory usage is high",
+		"2025-01-01 10:15:00 INFO Request processed in 20 ms",
+		"2025-01-01 10:42:00 ERROR Connection lost",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	at := func(m int) time.Time { return time.Date(2025, 1, 1, 10, m, 0, 0, time.UTC) }
+	want := []PeriodSummary{
+		{Start: at(0), Total: 2, Errors: 1, ResponseCount: 2, ResponseSum: 40},
+		{Start: at(10), Total: 2, Warns: 1, ResponseCount: 1, ResponseSum: 20},
+		{Start: at(20)},
+		{Start: at(30)},
+		{Start: at(40), Total: 1, Errors: 1},
+	}
+	got := GroupByWindow(entries, 10*time.Minute)
+	if len(got) != len(want) {
+		t.Fatalf("got %d windows, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || got[i].Total != want[i].Total || got[i].Errors != want[i].Errors ||
+			got[i].Warns != want[i].Warns || got[i].ResponseCount != want[i].ResponseCount || got[i].ResponseSum != want[i].ResponseSum {
+			t.Errorf("window %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got := GroupByWindow(nil, time.Minute); len(got) != 0 {
+		t.Errorf("got %+v without entries, want no window", got)
+	}
+
+	var b strings.Builder
+	if err := PrintWindowStats(&b, got[:2]); err != nil {
+		t.Fatal(err)
+	}
+	wantTable := `WINDOW               TOTAL  ERRORS  WARNS  AVG RESPONSE (ms)
+2025-01-01 10:00:00  2      1       0      20.00
+2025-01-01 10:10:00  2      0       1      20.00
+`
+	if b.String() != wantTable {
+		t.Errorf("got the table\n%s\nwant\n%s", b.String(), wantTable)
+	}
+	b.Reset()
+	if err := WriteWindowStatsCSV(&b, got[2:]); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := `window,total,errors,warns,avg_response_ms
+2025-01-01 10:20:00,0,0,0,
+2025-01-01 10:30:00,0,0,0,
+2025-01-01 10:40:00,1,1,0,
+`
+	if b.String() != wantCSV {
+		t.Errorf("got the csv\n%s\nwant\n%s", b.String(), wantCSV)
+	}
+}
+
+func TestWindowStatsFlag(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:30 INFO Request processed in 30 ms",
+		"2025-01-01 10:02:00 ERROR Connection lost")
+	stdout, stderr, status := runMain(t, "-window-stats", "1m", "-format", "csv", "-level", "info,error", path)
+	want := "window,total,errors,warns,avg_response_ms\n" +
+		"2025-01-01 10:00:00,2,0,0,20.00\n" +
+		"2025-01-01 10:01:00,0,0,0,\n" +
+		"2025-01-01 10:02:00,1,1,0,\n"
+	if status != 0 || stdout != want {
+		t.Errorf("got %q, exit %d, want %q\n%s", stdout, status, want, stderr)
+	}
+	if _, stderr, status := runMain(t, "-window-stats", "1m", "-group-by-day", path); status != 1 || !strings.Contains(stderr, "-window-stats can not be used") {
+		t.Errorf("got %q, exit %d, want -window-stats rejected with -group-by-day", stderr, status)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "fmt"
+
+// DeduplicateGlobal return the first entry of each distinct message of
+// entries, in their order, with the number of occurrences of the message
+// appended to it when it occurs more than once, e.g. "Request failed (100
+// occurrences)". Unlike the consecutive duplicates, all the occurrences of
+// a message over the entries are collapsed into one.
+func DeduplicateGlobal(entries []LogEntry) []LogEntry {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[entry.message]++
+	}
+	unique := make([]LogEntry, 0, len(counts))
+	for _, entry := range entries {
+		n, ok := counts[entry.message]
+		if !ok {
+			continue
+		}
+		delete(counts, entry.message)
+		if n > 1 {
+			entry.message = fmt.Sprintf("%s (%d occurrences)", entry.message, n)
+		}
+		unique = append(unique, entry)
+	}
+	return unique
+}
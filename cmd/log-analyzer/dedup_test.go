@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 100 occurrences of a message collapse into its first entry
+// suffixed with their number, the other messages kept as is.
+func TestDeduplicateGlobal(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	var entries []LogEntry
+	for i := 0; i < 100; i++ {
+		entries = append(entries, LogEntry{time: start.Add(time.Duration(i) * time.Second), level: LevelError, message: "Connection lost"})
+		if i == 10 || i == 50 {
+			entries = append(entries, LogEntry{time: start.Add(time.Duration(i) * time.Second), level: LevelInfo, message: fmt.Sprintf("Request %d processed", i)})
+		}
+	}
+
+	got := DeduplicateGlobal(entries)
+	want := []LogEntry{
+		{time: start, level: LevelError, message: "Connection lost (100 occurrences)"},
+		{time: start.Add(10 * time.Second), level: LevelInfo, message: "Request 10 processed"},
+		{time: start.Add(50 * time.Second), level: LevelInfo, message: "Request 50 processed"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries %v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("got the entry %d %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	report, err := Analyze(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.UniqueMessageCount != 3 || report.MsgFrequency["Connection lost"] != 100 {
+		t.Errorf("got %d unique messages, %d connections lost, want 3 and 100", report.UniqueMessageCount, report.MsgFrequency["Connection lost"])
+	}
+
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = "2025-01-01 10:00:00 ERROR Connection lost"
+	}
+	path := writeLines(t, "app.log", lines...)
+	stdout, stderr, status := runMain(t, "-deduplicate-global", "-level", "error", "-top", "1", path)
+	if status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	if !strings.Contains(stdout, "Total Log Entries: 1\n") || !strings.Contains(stdout, "Connection lost (100 occurrences)") {
+		t.Errorf("got the report\n%s\nwant the single entry of the 100 occurrences", stdout)
+	}
+}
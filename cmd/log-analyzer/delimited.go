@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InputDelimited is the -input of the lines of fields separated by
+// commas, or by the -delimiter, see DelimitedParser.
+const InputDelimited = "delimited"
+
+// DelimitedParser parse the lines made of fields separated by Delimiter,
+// the timestamp, the level and the message followed by optional key=value
+// fields kept as the entry data, see LogEntry.Field. e.g. with ",":
+//
+//	2025-01-01 10:00:00,ERROR,"Connection lost, retrying",msg="a, b"
+//
+// A delimiter within double quotes is part of the field rather than a field
+// separator, the quotes being removed from the field and \" standing for
+// a quote within them. An unknown level is kept as its raw token.
+type DelimitedParser struct {
+	Delimiter string
+}
+
+func (p DelimitedParser) Parse(line string) (LogEntry, error) {
+	fields, err := splitQuoted(line, p.Delimiter)
+	if err != nil {
+		return LogEntry{}, &ParseError{Raw: line, Reason: Malformed, Err: err}
+	}
+	if len(fields) < 3 {
+		return LogEntry{}, &ParseError{Raw: line, Reason: TooFewFields}
+	}
+	t, err := parseTime(fields[0])
+	if err == nil {
+		t, err = checkTime(t)
+	}
+	if err != nil {
+		return LogEntry{}, &ParseError{Raw: line, Reason: BadTimestamp, Err: err}
+	}
+	level, _ := ParseLevel(fields[1])
+	entry := LogEntry{time: t, level: level, rawLevel: fields[1], message: fields[2]}
+	for _, field := range fields[3:] {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok || k == "" {
+			continue
+		}
+		if entry.data == nil {
+			entry.data = make(map[string]string)
+		}
+		entry.data[k] = v
+	}
+	return entry, nil
+}
+
+// splitQuoted split s into the fields separated by delim outside of double
+// quotes, removing the quotes. e.g. `a,"b, c",d="e, f"` with "," returns
+// "a", "b, c" and "d=e, f". An unterminated quote is an error.
+func splitQuoted(s, delim string) ([]string, error) {
+	var (
+		fields []string
+		field  strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\' && i+1 < len(s) && s[i+1] == '"':
+			field.WriteByte('"')
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], delim):
+			fields = append(fields, field.String())
+			field.Reset()
+			i += len(delim) - 1
+		default:
+			field.WriteByte(s[i])
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	return append(fields, field.String()), nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDelimitedParser(t *testing.T) {
+	p := DelimitedParser{Delimiter: ","}
+	entry, err := p.Parse(`2025-01-01 10:00:00,ERROR,"Connection lost, retrying",msg="a, b",attempt=3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LogEntry{
+		time:     time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+		level:    LevelError,
+		rawLevel: "ERROR",
+		message:  "Connection lost, retrying",
+		data:     map[string]string{"msg": "a, b", "attempt": "3"},
+	}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("got %+v, want %+v", entry, want)
+	}
+
+	// A quoted message spanning the delimiter is a single field.
+	entry, err = DelimitedParser{Delimiter: " | "}.Parse(`2025-01-01T10:00:01 | INFO | "a | b \"quoted\""`)
+	if err != nil || entry.message != `a | b "quoted"` || entry.level != LevelInfo {
+		t.Errorf("got %+v, %v, want the message a | b \"quoted\"", entry, err)
+	}
+
+	for line, reason := range map[string]error{
+		`2025-01-01 10:00:00,ERROR`:                   ErrTooFewFields,
+		`2025-01-01 10:00:00,"ERROR,Connection lost"`: ErrTooFewFields,
+		`yesterday,ERROR,Connection lost`:             ErrBadTimestamp,
+		`2025-01-01 10:00:00,ERROR,"Connection lost`:  ErrMalformed,
+	} {
+		if _, err := p.Parse(line); !errors.Is(err, reason) {
+			t.Errorf("Parse(%q): got %v, want %v", line, err, reason)
+		}
+	}
+}
+
+func TestSplitQuoted(t *testing.T) {
+	for _, test := range []struct {
+		s, delim string
+		want     []string
+	}{
+		{`a,b,c`, ",", []string{"a", "b", "c"}},
+		{`a,"b, c",d="e, f"`, ",", []string{"a", "b, c", "d=e, f"}},
+		{`a,,""`, ",", []string{"a", "", ""}},
+		{`a;;"b;;c";;d`, ";;", []string{"a", "b;;c", "d"}},
+		{`"a \"b\", c"`, ",", []string{`a "b", c`}},
+		{`a\,b`, ",", []string{`a\`, "b"}},
+	} {
+		got, err := splitQuoted(test.s, test.delim)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitQuoted(%q, %q): got %q, %v, want %q", test.s, test.delim, got, err, test.want)
+		}
+	}
+}
+
+// The -delimiter lines are analyzed with their quoted messages whole.
+func TestDelimiterFlag(t *testing.T) {
+	path := writeLines(t, "app.log",
+		`2025-01-01 10:00:00,INFO,"Request processed, status 200 in 20 ms"`,
+		`2025-01-01 10:00:01,ERROR,"Connection lost, retrying"`,
+		`2025-01-01 10:00:02,ERROR,"Connection lost, retrying"`,
+	)
+	// -input delimited is the same as -delimiter ','.
+	for _, args := range [][]string{{"-delimiter", ","}, {"-input", "delimited"}, {"-input", "delimited", "-delimiter", ","}} {
+		stdout, stderr, status := runMain(t, append(args, "-level", "info,error", "-format", "json", path)...)
+		if status != 0 {
+			t.Fatalf("%q: got %q, exit %d", args, stderr, status)
+		}
+		for _, want := range []string{`"Connection lost, retrying":2`, `"total_entries":3`, `"response_count":1`} {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("%q: got %q, want %q", args, stdout, want)
+			}
+		}
+	}
+	if _, stderr, status := runMain(t, "-delimiter", ",", "-input", "json", path); status == 0 || !strings.Contains(stderr, "-delimiter can not be used") {
+		t.Errorf("got %q, exit %d with -input json, want an error", stderr, status)
+	}
+	if _, stderr, status := runMain(t, "-delimiter", ",", "-no-level", path); status == 0 || !strings.Contains(stderr, "-delimiter can not be used") {
+		t.Errorf("got %q, exit %d with -no-level, want an error", stderr, status)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ReportDelta is the difference between two reports of the same log,
+// typically analyzed before and after it grew.
+type ReportDelta struct {
+	TotalEntries int
+	Info         int
+	Warn         int
+	Error        int
+	Debug        int
+	// Messages hold the change of count of each message whose count
+	// changed, the largest increase first.
+	Messages []MessageCount
+}
+
+// Delta return what changed in the report since prev.
+func (r *AnalysisReport) Delta(prev *AnalysisReport) *ReportDelta {
+	d := &ReportDelta{
+		TotalEntries: r.TotalEntries - prev.TotalEntries,
+		Info:         r.Info - prev.Info,
+		Warn:         r.Warn - prev.Warn,
+		Error:        r.Error - prev.Error,
+		Debug:        r.Debug - prev.Debug,
+	}
+	for msg, n := range r.MsgFrequency {
+		if diff := n - prev.MsgFrequency[msg]; diff != 0 {
+			d.Messages = append(d.Messages, MessageCount{Message: msg, Count: diff})
+		}
+	}
+	for msg, n := range prev.MsgFrequency {
+		if _, ok := r.MsgFrequency[msg]; !ok {
+			d.Messages = append(d.Messages, MessageCount{Message: msg, Count: -n})
+		}
+	}
+	sort.Slice(d.Messages, func(i, j int) bool {
+		if d.Messages[i].Count != d.Messages[j].Count {
+			return d.Messages[i].Count > d.Messages[j].Count
+		}
+		return d.Messages[i].Message < d.Messages[j].Message
+	})
+	return d
+}
+
+// Empty report whether nothing changed.
+func (d *ReportDelta) Empty() bool {
+	return d.TotalEntries == 0 && d.Info == 0 && d.Warn == 0 &&
+		d.Error == 0 && d.Debug == 0 && len(d.Messages) == 0
+}
+
+// Print write the changes to w.
+func (d *ReportDelta) Print(w io.Writer) error {
+	if d.Empty() {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+	fmt.Fprintf(w, "Total Log Entries: %+d\n", d.TotalEntries)
+	fmt.Fprintf(w, "DEBUG: %+d\n", d.Debug)
+	fmt.Fprintf(w, "INFO: %+d\n", d.Info)
+	fmt.Fprintf(w, "WARN: %+d\n", d.Warn)
+	fmt.Fprintf(w, "ERROR: %+d\n", d.Error)
+	if len(d.Messages) > 0 {
+		fmt.Fprintln(w, "Messages:")
+		for _, m := range d.Messages {
+			fmt.Fprintf(w, "  %+-8d %s\n", m.Count, m.Message)
+		}
+	}
+	return nil
+}
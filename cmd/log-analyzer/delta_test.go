@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReportDelta(t *testing.T) {
+	analyze := func(text string) *AnalysisReport {
+		t.Helper()
+		report, err := AnalyzeReader(strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	before := "2025-01-01 10:00:00 INFO Started\n2025-01-01 10:00:01 ERROR Connection lost\n"
+	prev := analyze(before)
+	cur := analyze(before + "2025-01-01 10:00:02 ERROR Connection lost\n2025-01-01 10:00:03 WARN Disk almost full\n")
+
+	d := cur.Delta(prev)
+	want := &ReportDelta{TotalEntries: 2, Warn: 1, Error: 1, Messages: []MessageCount{{"Connection lost", 1}, {"Disk almost full", 1}}}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("got the delta %+v, want %+v", d, want)
+	}
+	var b strings.Builder
+	if err := d.Print(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "Total Log Entries: +2\n") || !strings.Contains(b.String(), "ERROR: +1\n") {
+		t.Errorf("got the printed delta\n%s", b.String())
+	}
+
+	if d := cur.Delta(cur); !d.Empty() {
+		t.Errorf("got the delta %+v of the same report, want none", d)
+	}
+	b.Reset()
+	if err := cur.Delta(cur).Print(&b); err != nil || b.String() != "No changes\n" {
+		t.Errorf("got %q, %v, want No changes", b.String(), err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// summarySample is the number of bytes read at the start of
+// a file to estimate its number of lines.
+const summarySample = 64 << 10
+
+// FileSummary is the size and the estimated number of lines of a file,
+// obtained without parsing any entry.
+type FileSummary struct {
+	Path string
+	// Size is the size of the content, uncompressed for the gzip files.
+	Size int64
+	// Lines is the number of lines, exact when the whole file fit in the
+	// sample and otherwise extrapolated from the lines of the sample.
+	Lines int64
+	Exact bool
+}
+
+// SummarizeFile return the summary of the file at path, decompressing
+// it when its extension is '.gz' as the -rotated files.
+func SummarizeFile(path string) (FileSummary, error) {
+	s := FileSummary{Path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return s, err
+	}
+	s.Size = fi.Size()
+
+	var r io.Reader = f
+	if filepath.Ext(path) == ".gz" {
+		if s.Size, err = gzipSize(f, fi.Size()); err != nil {
+			return s, fmt.Errorf("%s: %w", path, err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return s, fmt.Errorf("%s: %w", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	sample := make([]byte, summarySample)
+	n, err := io.ReadFull(r, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return s, &ReadError{Name: path, Offset: int64(n), Err: err}
+	}
+	sample = sample[:n]
+	s.Lines = int64(bytes.Count(sample, []byte{'\n'}))
+	if n > 0 && sample[n-1] != '\n' && int64(n) == s.Size {
+		s.Lines++ // last line without newline
+	}
+	s.Exact = int64(n) >= s.Size
+	if !s.Exact && n > 0 {
+		s.Lines = s.Lines * s.Size / int64(n)
+	}
+	return s, nil
+}
+
+// gzipSize return the uncompressed size recorded in the trailer of the
+// gzip file f of the given size, modulo 4GiB as the format records it.
+func gzipSize(f io.ReaderAt, size int64) (int64, error) {
+	if size < 4 {
+		return 0, gzip.ErrHeader
+	}
+	var trailer [4]byte
+	if _, err := f.ReadAt(trailer[:], size-4); err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint32(trailer[:])), nil
+}
+
+// DryRun write the files which would be analyzed, the rotations of file with
+// -rotated, their estimated number of lines and the filters applied to the
+// entries, without parsing any of them.
+func DryRun(w io.Writer, file string, filter []NamedFilter) error {
+	files := []string{file}
+	if *rotated {
+		var err error
+		if files, err = Rotations(file); err != nil {
+			return fmt.Errorf("failed to list rotated files: %w", err)
+		}
+	}
+
+	fmt.Fprintln(w, "Files:")
+	var total int64
+	for _, path := range files {
+		s, err := SummarizeFile(path)
+		if err != nil {
+			return err
+		}
+		estimate := "~"
+		if s.Exact {
+			estimate = ""
+		}
+		fmt.Fprintf(w, "  %s: %d bytes, %s%d lines\n", s.Path, s.Size, estimate, s.Lines)
+		total += s.Lines
+	}
+	fmt.Fprintf(w, "Estimated Lines: %d\n", total)
+
+	fmt.Fprintln(w, "Filters:")
+	if len(filter) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, f := range filter {
+		fmt.Fprintf(w, "  %-12s %s\n", f, f.Description)
+	}
+	return nil
+}
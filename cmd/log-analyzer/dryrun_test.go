@@ -0,0 +1,57 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSummarizeFile(t *testing.T) {
+	text := logText(generateEntries(5000, 0))
+	dir := t.TempDir()
+	large := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(large, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := SummarizeFile(large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Exact || s.Size != int64(len(text)) || s.Lines < 4500 || s.Lines > 5500 {
+		t.Errorf("got %+v, want about 5000 lines estimated", s)
+	}
+
+	gz := filepath.Join(dir, "app.log.1.gz")
+	f, err := os.Create(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	fmt.Fprint(zw, "2025-01-01 10:00:00 INFO Started\n2025-01-01 10:00:01 INFO Stopped")
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if s, err := SummarizeFile(gz); err != nil || !s.Exact || s.Lines != 2 || s.Size != 65 {
+		t.Errorf("got %+v, %v, want the 2 lines of 65 bytes uncompressed", s, err)
+	}
+}
+
+// A dry run list the file and the filters without parsing any entry, the
+// invalid lines then failing neither -strict nor -max-errors.
+func TestDryRun(t *testing.T) {
+	path := writeLines(t, "app.log", "not a log line", "2025-01-01 10:00:00 INFO Started", "truncated")
+	stdout, stderr, status := runMain(t, "-dry-run", "-strict", "-max-errors", "1", "-level", "error", "-match", "lost$", path)
+	if status != 0 || stderr != "" {
+		t.Fatalf("got %q, %q, exit %d", stdout, stderr, status)
+	}
+	want := fmt.Sprintf("Files:\n  %s: 58 bytes, 3 lines\nEstimated Lines: 3\n", path) +
+		"Filters:\n" +
+		"  level        level not in [error]\n" +
+		"  match        message not matching 'lost$'\n"
+	if stdout != want {
+		t.Errorf("got %q, want %q", stdout, want)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// WriteEntriesJSON write the entries to w as a single json array, one
+// entry per line, unlike the NDJSON of the HTTP server the whole output
+// is a valid json document which can be loaded at once, e.g. in a
+// notebook. The entries are encoded as LogEntry.MarshalJSON and can be
+// decoded back with LogEntry.UnmarshalJSON.
+func WriteEntriesJSON(w io.Writer, entries []LogEntry) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[")
+	for i, e := range entries {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		bw.WriteString("\n")
+		bw.Write(b)
+	}
+	bw.WriteString("\n]\n")
+	return bw.Flush()
+}
+
+// dumpEntries write the entries as WriteEntriesJSON to the file at path.
+func dumpEntries(path string, entries []LogEntry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteEntriesJSON(f, entries); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
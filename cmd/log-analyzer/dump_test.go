@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The dumped entries are a json array decoding back to the analyzed entries.
+func TestDumpRoundTrip(t *testing.T) {
+	entries := generateEntries(100, 0)
+	path := writeLines(t, "app.log", strings.Split(strings.TrimSuffix(logText(entries), "\n"), "\n")...)
+	dump := filepath.Join(t.TempDir(), "entries.json")
+	if _, stderr, status := runMain(t, "-level", "info,error", "-dump", dump, path); status != 0 {
+		t.Fatalf("exit %d: %s", status, stderr)
+	}
+	b, err := os.ReadFile(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []LogEntry
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("invalid json array: %v\n%s", err, b)
+	}
+	want := filterEntries(entries, []NamedFilter{{Name: "level", Filter: LevelFilter{Levels: []string{"info", "error"}}.Skip}})
+	if !EqualSlice(got, want) {
+		t.Errorf("got %d entries dumped, want the %d info and error entries", len(got), len(want))
+	}
+
+	var empty strings.Builder
+	if err := WriteEntriesJSON(&empty, nil); err != nil || json.Unmarshal([]byte(empty.String()), &got) != nil || len(got) != 0 {
+		t.Errorf("got %q without entries, want an empty json array", empty.String())
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"iter"
+	"regexp"
+)
+
+// Parser parse a log line into an entry.
+type Parser interface {
+	Parse(line string) (LogEntry, error)
+}
+
+// ParserFunc adapt an ordinary function to a Parser.
+type ParserFunc func(line string) (LogEntry, error)
+
+func (f ParserFunc) Parse(line string) (LogEntry, error) {
+	return f(line)
+}
+
+// errSkipLine is returned by a Parser for a line which is not an
+// entry and must be left out of the sequence without an error.
+var errSkipLine = errors.New("line skipped")
+
+// Entries return the sequence of the entries parsed by p from the lines of r,
+// read as they are iterated, without holding them in memory.
+//
+//	for entry, err := range Entries(f, ParserFunc(NewLogEntry)) {
+//		var perr *ParseError
+//		if errors.As(err, &perr) {
+//			continue // or count, or break
+//		} else if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// An invalid line is yielded with the entry and the error returned by the
+// parser, a *ParseError holding the line number, the sequence going on with
+// the next line. An error reading r is yielded last, ending the sequence.
+func Entries(r io.Reader, p Parser) iter.Seq2[LogEntry, error] {
+	return func(yield func(LogEntry, error) bool) {
+		var n int
+		for line, err := range scanLines(r) {
+			if err != nil {
+				yield(LogEntry{}, err)
+				return
+			}
+			n++
+			entry, err := p.Parse(line)
+			if err == errSkipLine {
+				continue
+			}
+			var perr *ParseError
+			if errors.As(err, &perr) && perr.Line == 0 {
+				perr.Line = n
+			}
+			if !yield(entry, err) {
+				return
+			}
+		}
+	}
+}
+
+// scanLines return the sequence of the lines of r, without their end of
+// line, followed by the error reading r if any. The lines of a MappedFile
+// are read from the mapping directly.
+func scanLines(r io.Reader) iter.Seq2[string, error] {
+	if m, ok := r.(*MappedFile); ok {
+		return m.lines()
+	}
+	return func(yield func(string, error) bool) {
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			if !yield(s.Text(), nil) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			yield("", err)
+		}
+	}
+}
+
+// LineParser parse the raw lines with Parser, or in the default format
+// when nil, after removing the StripPrefix match at their start, such as
+// the time, stream and tag container runtimes prepend to the lines of the
+// containers. The lines matching Skip are skipped before parsing, sparing
+// the parser the lines which are of no interest. With SourcePrefix the
+// bracketed source prefix of the messages is extracted, see ParseSource.
+type LineParser struct {
+	Parser       Parser
+	Skip         *regexp.Regexp
+	StripPrefix  *regexp.Regexp
+	SourcePrefix bool
+}
+
+// Parse parse the line, the lines matching Skip being left out of Entries.
+func (p LineParser) Parse(line string) (LogEntry, error) {
+	if p.skip(line) {
+		return LogEntry{}, errSkipLine
+	}
+	return p.parse(0, line)
+}
+
+// skip report whether the raw line should be skipped before parsing.
+func (p LineParser) skip(line string) bool {
+	return p.Skip != nil && p.Skip.MatchString(line)
+}
+
+// parse parse the n-th raw line, which is not skipped.
+func (p LineParser) parse(n int, line string) (LogEntry, error) {
+	if p.StripPrefix != nil {
+		// A match further in the line is left as is.
+		if loc := p.StripPrefix.FindStringIndex(line); loc != nil && loc[0] == 0 {
+			line = line[loc[1]:]
+		}
+	}
+	var (
+		entry LogEntry
+		err   error
+	)
+	if p.Parser != nil {
+		entry, err = p.Parser.Parse(line)
+	} else {
+		entry, err = NewLogEntry(line)
+	}
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		perr.Line = n
+	}
+	if p.SourcePrefix {
+		entry.source, entry.message = ParseSource(entry.message)
+	}
+	return entry, err
+}
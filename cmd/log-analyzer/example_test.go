@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func ExampleNewLogEntry() {
+	entry, err := NewLogEntry("2025-01-01 10:00:00 ERROR Connection lost")
+	if err != nil {
+		panic(err)
+	}
+	b, _ := json.Marshal(entry)
+	fmt.Println(string(b))
+
+	_, err = NewLogEntry("2025-01-01 ERROR")
+	fmt.Println(err)
+	// Output:
+	// {"time":"2025-01-01T10:00:00Z","level":"ERROR","message":"Connection lost"}
+	// too few fields
+}
+
+func ExampleEntries() {
+	log := `2025-01-01 10:00:00 INFO Starting the application
+garbage
+2025-01-01 10:00:02 ERROR Connection lost
+`
+	var valid int
+	for entry, err := range Entries(strings.NewReader(log), DefaultParser{}) {
+		var perr *ParseError
+		if errors.As(err, &perr) {
+			fmt.Println("skipped", perr)
+			continue
+		} else if err != nil {
+			panic(err)
+		}
+		valid++
+		b, _ := json.Marshal(entry)
+		fmt.Println(string(b))
+	}
+	fmt.Println(valid, "entries")
+	// Output:
+	// {"time":"2025-01-01T10:00:00Z","level":"INFO","message":"Starting the application"}
+	// skipped line 2: too few fields
+	// {"time":"2025-01-01T10:00:02Z","level":"ERROR","message":"Connection lost"}
+	// 2 entries
+}
+
+func ExampleAnalyze() {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:00 INFO Request processed in 120 ms",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+		"2025-01-01 10:00:02 INFO Request processed in 80 ms",
+		"2025-01-01 10:00:03 ERROR Connection lost",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			panic(err)
+		}
+		entries = append(entries, entry)
+	}
+	report, err := Analyze(entries, WithTopN(1))
+	if err != nil {
+		panic(err)
+	}
+	avg, _ := report.AverageResponseTime()
+	fmt.Printf("%d entries, %d errors, %.0f ms on average\n", report.TotalEntries, report.Error, avg)
+	fmt.Printf("most frequent: %q %d times\n", report.Top[0].Message, report.Top[0].Count)
+	// Output:
+	// 4 entries, 2 errors, 100 ms on average
+	// most frequent: "Connection lost" 2 times
+}
+
+func ExampleAnalysisReport_Fprint() {
+	log := `2025-01-01 10:00:00 INFO Starting the application
+2025-01-01 10:00:01 WARN Memory usage is high
+2025-01-01 10:00:02 ERROR Connection lost
+2025-01-01 10:00:03 ERROR Connection lost
+`
+	report, err := AnalyzeReader(strings.NewReader(log))
+	if err != nil {
+		panic(err)
+	}
+	if err := report.Fprint(os.Stdout); err != nil {
+		panic(err)
+	}
+	// Output:
+	// Total Log Entries: 4
+	// DEBUG: 0
+	// INFO: 1
+	// WARN: 1
+	// ERROR: 2
+	// Time Range: 2025-01-01 10:00:00 - 2025-01-01 10:00:03
+	// Most frequent mesage: 'Connection lost'
+	// Longest message: 24 characters 'Starting the application'
+}
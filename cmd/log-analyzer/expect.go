@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LevelExpectation is the expected percentage of the entries of a level,
+// an observed percentage further than Tolerance points from it deviating.
+type LevelExpectation struct {
+	Level     Level
+	Pct       float64
+	Tolerance float64
+}
+
+// Check return the observed percentage of entries of the level in the
+// report and whether it is within the tolerance of the expected one.
+// A report without entries meet any expectation.
+func (e LevelExpectation) Check(report *AnalysisReport) (float64, bool) {
+	if report.TotalEntries == 0 {
+		return 0, true
+	}
+	count := report.count(e.Level)
+	observed := float64(count) / float64(report.TotalEntries) * 100
+	return observed, math.Abs(observed-e.Pct) <= e.Tolerance
+}
+
+// MessageExpectation is a message expected at least Min times, e.g. a
+// "server started" in the logs of a smoke test.
+type MessageExpectation struct {
+	Message string
+	Min     int
+	// Substring count the messages containing Message
+	// rather than only those equal to it.
+	Substring bool
+}
+
+// ParseMessageExpectation parse the 'message:N' expectation of a message
+// occurring at least N times, the count following the last colon so the
+// message may contain colons.
+func ParseMessageExpectation(s string) (MessageExpectation, error) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return MessageExpectation{}, fmt.Errorf("invalid expectation %q: want 'message:N'", s)
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil || n < 1 {
+		return MessageExpectation{}, fmt.Errorf("invalid expectation %q: the count %q must be a positive integer", s, s[i+1:])
+	}
+	if s[:i] == "" {
+		return MessageExpectation{}, fmt.Errorf("invalid expectation %q: the message is empty", s)
+	}
+	return MessageExpectation{Message: s[:i], Min: n}, nil
+}
+
+// Check return the number of times the message was counted in the report
+// MsgFrequency and whether it meets the expectation. The messages are
+// compared once counted, i.e. normalized or grouped as the options tell.
+func (e MessageExpectation) Check(report *AnalysisReport) (int, bool) {
+	count := report.MsgFrequency[e.Message]
+	if e.Substring {
+		count = 0
+		for msg, n := range report.MsgFrequency {
+			if strings.Contains(msg, e.Message) {
+				count += n
+			}
+		}
+	}
+	return count, count >= e.Min
+}
+
+// messageExpectations are the expectations of the -expect flags.
+var messageExpectations []MessageExpectation
+
+func init() {
+	flag.Func("expect", "exit with status 1 unless the message occurred at least N times, 'message:N', may be repeated. e.g: 'server started:1'", func(s string) error {
+		e, err := ParseMessageExpectation(s)
+		if err != nil {
+			return err
+		}
+		messageExpectations = append(messageExpectations, e)
+		return nil
+	})
+}
+
+// checkMessages report on stderr whether the report meets each of the -expect
+// expectations, matching the messages as substrings with -expect-substring,
+// and return whether it meets them all.
+func checkMessages(report *AnalysisReport) bool {
+	ok := true
+	for _, e := range messageExpectations {
+		e.Substring = *expectSubstr
+		count, met := e.Check(report)
+		status := "ok"
+		if !met {
+			status, ok = "missing", false
+		}
+		log.Printf("message '%s': %d observed, at least %d expected: %s", e.Message, count, e.Min, status)
+	}
+	return ok
+}
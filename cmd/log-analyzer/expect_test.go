@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevelExpectation(t *testing.T) {
+	// 2 errors and a warning out of 200 entries.
+	report := NewAnalysisReport()
+	report.TotalEntries, report.Info, report.Warn, report.Error = 200, 197, 1, 2
+	for _, tt := range []struct {
+		expect   LevelExpectation
+		observed float64
+		ok       bool
+	}{
+		{LevelExpectation{LevelError, 1, 0}, 1, true},
+		{LevelExpectation{LevelError, 0, 1}, 1, true},
+		{LevelExpectation{LevelError, 0, 0.5}, 1, false},
+		{LevelExpectation{LevelError, 5, 3}, 1, false},
+		{LevelExpectation{LevelWarn, 0, 0.5}, 0.5, true},
+		{LevelExpectation{LevelInfo, 100, 1}, 98.5, false},
+		{LevelExpectation{LevelDebug, 0, 0}, 0, true},
+	} {
+		observed, ok := tt.expect.Check(report)
+		if observed != tt.observed || ok != tt.ok {
+			t.Errorf("%+v: got %.2f%%, %v, want %.2f%%, %v", tt.expect, observed, ok, tt.observed, tt.ok)
+		}
+	}
+	if observed, ok := (LevelExpectation{LevelError, 0, 0}).Check(NewAnalysisReport()); observed != 0 || !ok {
+		t.Errorf("got %.2f%%, %v for an empty report, want the expectation met", observed, ok)
+	}
+}
+
+func TestExpectErrorExitStatus(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO Started",
+		"2025-01-01 10:00:01 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:02 INFO Request processed in 20 ms",
+		"2025-01-01 10:00:03 ERROR Connection lost")
+	for _, tt := range []struct {
+		args   []string
+		status int
+		log    string
+	}{
+		{[]string{"-level", "info,error", "-expect-error-pct", "25", "-expect-tolerance", "0", "-fail-on-deviation"}, 0, "25.00% observed, 25.00% ± 0.00% expected: ok"},
+		{[]string{"-level", "info,error", "-expect-error-pct", "1"}, 0, "25.00% observed, 1.00% ± 1.00% expected: deviating"},
+		{[]string{"-level", "info,error", "-expect-error-pct", "1", "-fail-on-deviation"}, 1, "expected: deviating"},
+		{[]string{"-expect-error-pct", "101"}, 1, "invalid expectation"},
+	} {
+		_, stderr, status := runMain(t, append(tt.args, path)...)
+		if status != tt.status || !strings.Contains(stderr, tt.log) {
+			t.Errorf("%q: got %q, exit %d, want %q, exit %d", tt.args, stderr, status, tt.log, tt.status)
+		}
+	}
+}
+
+func TestParseMessageExpectation(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want MessageExpectation
+		err  bool
+	}{
+		{s: "server started:1", want: MessageExpectation{Message: "server started", Min: 1}},
+		{s: "db: connected:3", want: MessageExpectation{Message: "db: connected", Min: 3}},
+		{s: "server started", err: true},
+		{s: "server started:0", err: true},
+		{s: "server started:x", err: true},
+		{s: ":1", err: true},
+	} {
+		got, err := ParseMessageExpectation(tt.s)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%q: got %+v, %v, want %+v, error %v", tt.s, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestMessageExpectation(t *testing.T) {
+	report := NewAnalysisReport()
+	report.MsgFrequency = map[string]int{"Server started on :8080": 1, "Request processed": 3, "Request failed": 2}
+	for _, tt := range []struct {
+		expect MessageExpectation
+		count  int
+		ok     bool
+	}{
+		{MessageExpectation{"Request processed", 3, false}, 3, true},
+		{MessageExpectation{"Request processed", 4, false}, 3, false},
+		{MessageExpectation{"Server started", 1, false}, 0, false},
+		{MessageExpectation{"Server started", 1, true}, 1, true},
+		{MessageExpectation{"Request", 5, true}, 5, true},
+		{MessageExpectation{"Shutting down", 1, true}, 0, false},
+	} {
+		count, ok := tt.expect.Check(report)
+		if count != tt.count || ok != tt.ok {
+			t.Errorf("%+v: got %d, %v, want %d, %v", tt.expect, count, ok, tt.count, tt.ok)
+		}
+	}
+}
+
+func TestExpectMessageExitStatus(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO Server started on :8080",
+		"2025-01-01 10:00:01 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:02 INFO Request processed in 10 ms")
+	for _, tt := range []struct {
+		args   []string
+		status int
+		log    string
+	}{
+		{[]string{"-expect", "Request processed in 10 ms:2"}, 0, "message 'Request processed in 10 ms': 2 observed, at least 2 expected: ok"},
+		{[]string{"-expect", "Request processed in 10 ms:2", "-expect", "Server started:1"}, 1, "message 'Server started': 0 observed, at least 1 expected: missing"},
+		{[]string{"-expect", "Server started:1", "-expect-substring"}, 0, "1 observed, at least 1 expected: ok"},
+		{[]string{"-expect", "Server started"}, 2, "invalid expectation"},
+	} {
+		_, stderr, status := runMain(t, append(tt.args, path)...)
+		if status != tt.status || !strings.Contains(stderr, tt.log) {
+			t.Errorf("%q: got %q, exit %d, want %q, exit %d", tt.args, stderr, status, tt.log, tt.status)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResponseTimeMetric is the name of the metric of ResponseTimeExtractor,
+// which is summarized in the report response time fields.
+const ResponseTimeMetric = "response_time_ms"
+
+// metricAccuracy is the relative accuracy of the quantiles of the
+// extracted metrics when the report has no quantile accuracy of its own.
+const metricAccuracy = 0.01
+
+// metricName is the pattern of the metric names.
+var metricName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// MetricExtractor return the name and value of a metric of the entry, or
+// false if the entry has none, e.g. the size of the batch in a "processed
+// batch of 120 events" message.
+type MetricExtractor func(LogEntry) (name string, value float64, ok bool)
+
+// ResponseTimeExtractor is the built-in extractor of every analysis, the
+// response time in ms of the messages ending with it. e.g:
+// 'Request processed in 245 ms'
+func ResponseTimeExtractor(entry LogEntry) (string, float64, bool) {
+	v, ok := responseTime(entry.message)
+	return ResponseTimeMetric, v, ok
+}
+
+// RegexExtractor return an extractor of the metric name, its value being
+// the capture group named value of re, or its first group, in the messages
+// re matches.
+func RegexExtractor(name string, re *regexp.Regexp) MetricExtractor {
+	group := max(re.SubexpIndex("value"), 1)
+	return func(entry LogEntry) (string, float64, bool) {
+		m := re.FindStringSubmatch(entry.message)
+		if m == nil || group >= len(m) {
+			return name, 0, false
+		}
+		v, err := strconv.ParseFloat(m[group], 64)
+		return name, v, err == nil
+	}
+}
+
+// ParseMetricExtractor parse a 'name=regex' -metric extractor, see
+// RegexExtractor. e.g: 'batch_size=batch of (\d+) events'
+func ParseMetricExtractor(s string) (MetricExtractor, error) {
+	name, expr, ok := strings.Cut(s, "=")
+	if !ok || !metricName.MatchString(name) {
+		return nil, fmt.Errorf("invalid metric %q: expected 'name=regex'", s)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric %s: %w", name, err)
+	}
+	if re.NumSubexp() == 0 {
+		return nil, fmt.Errorf("invalid metric %s: the regex %q captures no value", name, expr)
+	}
+	return RegexExtractor(name, re), nil
+}
+
+// metricExtractors are the extractors of the -metric flags.
+var metricExtractors []MetricExtractor
+
+func init() {
+	flag.Func("metric", "extract a metric from the messages, 'name=regex' capturing its value, may be repeated. e.g: 'batch_size=batch of (\\d+)'", func(s string) error {
+		extract, err := ParseMetricExtractor(s)
+		if err != nil {
+			return err
+		}
+		metricExtractors = append(metricExtractors, extract)
+		return nil
+	})
+}
+
+// Metric summarize the values of an extracted metric.
+type Metric struct {
+	Count       int          `json:"count"`
+	Sum         float64      `json:"sum"`
+	Min         float64      `json:"min"`
+	Max         float64      `json:"max"`
+	Percentiles []Percentile `json:"percentiles,omitempty"` // see WithPercentiles
+	sketch      *quantileSketch
+}
+
+// Add record the value v.
+func (m *Metric) Add(v float64) {
+	if m.Count == 0 || v < m.Min {
+		m.Min = v
+	}
+	if m.Count == 0 || v > m.Max {
+		m.Max = v
+	}
+	m.Count++
+	m.Sum += v
+	if m.sketch == nil {
+		m.sketch = newQuantileSketch(metricAccuracy)
+	}
+	m.sketch.Add(v, 1)
+}
+
+// Average return the average of the values, or false if there are none.
+func (m *Metric) Average() (float64, bool) {
+	if m.Count == 0 {
+		return 0, false
+	}
+	return m.Sum / float64(m.Count), true
+}
+
+// Quantile return the estimated q quantile of the values, q within [0, 1],
+// or false if there are none. It is within the relative accuracy of the
+// report quantiles, see WithQuantileAccuracy, or of 1% by default, the
+// values below zero being counted as zero.
+func (m *Metric) Quantile(q float64) (float64, bool) {
+	if m.sketch == nil {
+		return 0, false
+	}
+	return m.sketch.Quantile(q)
+}
+
+// merge add the values summarized by other to the metric.
+func (m *Metric) merge(other *Metric) {
+	if other.Count == 0 {
+		return
+	}
+	if m.Count == 0 || other.Min < m.Min {
+		m.Min = other.Min
+	}
+	if m.Count == 0 || other.Max > m.Max {
+		m.Max = other.Max
+	}
+	m.Count += other.Count
+	m.Sum += other.Sum
+	switch {
+	case other.sketch == nil:
+	case m.sketch == nil:
+		m.sketch = other.sketch.clone()
+	default:
+		m.sketch.Merge(other.sketch)
+	}
+}
+
+// quantiles return the percentiles ps of the values.
+func (m *Metric) quantiles(ps []float64) []Percentile {
+	var result []Percentile
+	for _, p := range ps {
+		v, ok := m.Quantile(p / 100)
+		if !ok {
+			return nil
+		}
+		result = append(result, Percentile{P: p, Value: v})
+	}
+	return result
+}
+
+func (m *Metric) clone() *Metric {
+	c := *m
+	c.Percentiles = append([]Percentile(nil), m.Percentiles...)
+	if m.sketch != nil {
+		c.sketch = m.sketch.clone()
+	}
+	return &c
+}
+
+// extract record the metric extract return for the entry, if any.
+func (r *AnalysisReport) extract(entry LogEntry, extract MetricExtractor) {
+	name, v, ok := extract(entry)
+	switch {
+	case !ok:
+	case name == ResponseTimeMetric:
+		r.addResponseTime(v)
+	default:
+		r.metric(name).Add(v)
+	}
+}
+
+// metric return the metric called name, added to the report if missing.
+func (r *AnalysisReport) metric(name string) *Metric {
+	m, ok := r.Metrics[name]
+	if !ok {
+		if r.Metrics == nil {
+			r.Metrics = make(map[string]*Metric)
+		}
+		accuracy := metricAccuracy
+		if r.sketch != nil {
+			accuracy = r.sketch.Accuracy
+		}
+		m = &Metric{sketch: newQuantileSketch(accuracy)}
+		r.Metrics[name] = m
+	}
+	return m
+}
+
+// metricNames return the names of the metrics in alphabetical order.
+func (r *AnalysisReport) metricNames() []string {
+	names := make([]string, 0, len(r.Metrics))
+	for name := range r.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// extractLog is a log of the entries analyzed by the extractor tests.
+const extractLog = `2025-01-01 10:00:00 INFO Processed batch of 10 events in 120 ms
+2025-01-01 10:00:01 INFO Cache hit ratio 0.5
+2025-01-01 10:00:02 INFO Processed batch of 30 events in 80 ms
+2025-01-01 10:00:03 INFO Cache hit ratio 0.75
+2025-01-01 10:00:04 INFO Processed batch of 20 events in 100 ms
+2025-01-01 10:00:05 ERROR Cache unavailable
+`
+
+// cacheHitRatio is an extractor of the cache hit ratio of the extractLog.
+func cacheHitRatio(entry LogEntry) (string, float64, bool) {
+	s, ok := strings.CutPrefix(entry.message, "Cache hit ratio ")
+	if !ok {
+		return "cache_hit_ratio", 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return "cache_hit_ratio", v, err == nil
+}
+
+func TestExtractors(t *testing.T) {
+	batch := RegexExtractor("batch_size", regexp.MustCompile(`batch of (?P<value>\d+) events`))
+	report, err := AnalyzeReader(strings.NewReader(extractLog), WithExtractors(batch, cacheHitRatio), WithPercentiles(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Metrics) != 2 {
+		t.Fatalf("got the metrics %v, want batch_size and cache_hit_ratio", report.metricNames())
+	}
+	m := report.Metrics["batch_size"]
+	if m.Count != 3 || m.Sum != 60 || m.Min != 10 || m.Max != 30 {
+		t.Errorf("got the batch size %+v, want 3 values summing to 60 within [10, 30]", m)
+	}
+	if avg, ok := m.Average(); !ok || avg != 20 {
+		t.Errorf("got the average batch size %g, %v, want 20", avg, ok)
+	}
+	if p50, ok := m.Quantile(0.5); !ok || math.Abs(p50-20) > 20*metricAccuracy {
+		t.Errorf("got the median batch size %g, %v, want 20 within 1%%", p50, ok)
+	}
+	if len(m.Percentiles) != 1 || m.Percentiles[0].P != 50 {
+		t.Errorf("got the percentiles %v, want the p50", m.Percentiles)
+	}
+	if m := report.Metrics["cache_hit_ratio"]; m.Count != 2 || m.Sum != 1.25 || m.Min != 0.5 || m.Max != 0.75 {
+		t.Errorf("got the cache hit ratio %+v, want 2 values within [0.5, 0.75]", m)
+	}
+
+	// The built-in extractor still summarizes the response times.
+	plain, err := AnalyzeReader(strings.NewReader(extractLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ResponseCount != 3 || report.ResponseSum != 300 || !reflect.DeepEqual(report.ResponseTime, plain.ResponseTime) {
+		t.Errorf("got the response times %v, want %v", report.ResponseTime, plain.ResponseTime)
+	}
+	if plain.Metrics != nil {
+		t.Errorf("got the metrics %v without extractors", plain.Metrics)
+	}
+	if _, err := newOptions(WithExtractors(nil)); err == nil {
+		t.Error("accepted a nil extractor")
+	}
+}
+
+// An extractor of the response time metric feeds the response time fields.
+func TestExtractorResponseTime(t *testing.T) {
+	latency := RegexExtractor(ResponseTimeMetric, regexp.MustCompile(`latency=(\d+)ms`))
+	report, err := Analyze([]LogEntry{{level: LevelInfo, message: "GET / latency=42ms"}}, WithExtractors(latency))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg, ok := report.AverageResponseTime(); !ok || avg != 42 || len(report.Metrics) != 0 {
+		t.Errorf("got the average response time %g, %v and the metrics %v, want 42", avg, ok, report.Metrics)
+	}
+}
+
+func TestMetricMerge(t *testing.T) {
+	entries := generateEntries(200, 0)
+	extract := RegexExtractor("latency", regexp.MustCompile(`processed in (\d+) ms`))
+	all, err := Analyze(entries, WithExtractors(extract))
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err := Analyze(entries[:80], WithExtractors(extract))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest, err := Analyze(entries[80:], WithExtractors(extract))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := merged.Clone()
+	if err := merged.Merge(rest); err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Metrics) != 1 {
+		t.Fatalf("got the metrics %v, want the latency", all.metricNames())
+	}
+	for name, m := range all.Metrics {
+		got := merged.Metrics[name]
+		if got == nil || got.Count != m.Count || got.Sum != m.Sum || got.Min != m.Min || got.Max != m.Max || !reflect.DeepEqual(got.sketch.Buckets, m.sketch.Buckets) {
+			t.Errorf("%s: got %+v merged, want %+v", name, got, m)
+		}
+	}
+	if clone.Metrics["latency"].Count == merged.Metrics["latency"].Count {
+		t.Error("the clone changed along with the merged report")
+	}
+}
+
+func TestMetricSaveLoad(t *testing.T) {
+	report, err := AnalyzeReader(strings.NewReader(extractLog), WithExtractors(cacheHitRatio))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := report.Metrics["cache_hit_ratio"].Quantile(0.99)
+	if got, ok := loaded.Metrics["cache_hit_ratio"].Quantile(0.99); !ok || got != want {
+		t.Errorf("got the loaded p99 %g, %v, want %g", got, ok, want)
+	}
+}
+
+func TestParseMetricExtractor(t *testing.T) {
+	extract, err := ParseMetricExtractor(`batch_size=batch of (\d+) events`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, v, ok := extract(LogEntry{message: "Processed batch of 12 events"}); name != "batch_size" || v != 12 || !ok {
+		t.Errorf("got %s %g %v, want batch_size 12", name, v, ok)
+	}
+	if _, _, ok := extract(LogEntry{message: "Processed batch of many events"}); ok {
+		t.Error("extracted a value from a message without any")
+	}
+	for _, s := range []string{"batch_size", "=(\\d+)", "1batch=(\\d+)", "batch size=(\\d+)", "batch=(", "batch=\\d+"} {
+		if _, err := ParseMetricExtractor(s); err == nil {
+			t.Errorf("ParseMetricExtractor(%q): got no error", s)
+		}
+	}
+}
+
+// The -metric flags add a line per metric to the report.
+func TestMetricFlag(t *testing.T) {
+	path := writeLines(t, "app.log", strings.Split(strings.TrimSuffix(extractLog, "\n"), "\n")...)
+	stdout, stderr, status := runMain(t, "-metric", `batch_size=batch of (\d+)`, "-metric", `cache_hit_ratio=ratio ([\d.]+)`, path)
+	if status != 0 {
+		t.Fatalf("got %q, exit %d", stderr, status)
+	}
+	for _, want := range []string{
+		"  batch_size           count=3 avg=20.00 min=10.00 max=30.00\n",
+		"  cache_hit_ratio      count=2 avg=0.62 min=0.50 max=0.75\n",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("got %q, want %q", stdout, want)
+		}
+	}
+	if _, stderr, status := runMain(t, "-metric", "batch_size", path); status == 0 || !strings.Contains(stderr, "expected 'name=regex'") {
+		t.Errorf("got %q, exit %d for an invalid metric", stderr, status)
+	}
+}
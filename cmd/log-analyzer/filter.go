@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FilterFunc report whether the entry should be skipped.
+type FilterFunc func(LogEntry) bool
+
+// NamedFilter is a filter along with a name describing it, e.g. "level",
+// which the -explain output refer to, and an optional description of the
+// entries it skips, e.g. "level not in [info]", listed by -dry-run.
+type NamedFilter struct {
+	Name        string
+	Description string
+	Filter      FilterFunc
+}
+
+// Skip report whether the entry should be skipped, as Filter does.
+func (f NamedFilter) Skip(entry LogEntry) bool {
+	return f.Filter(entry)
+}
+
+// String return the name of the filter.
+func (f NamedFilter) String() string {
+	return f.Name
+}
+
+// ExplainableFilter is a filter able to tell why an entry is skipped.
+type ExplainableFilter interface {
+	// Skip report whether the entry should be skipped.
+	Skip(LogEntry) bool
+	// Explain report whether the entry should be skipped and why.
+	Explain(LogEntry) (skipped bool, reason string)
+}
+
+// LevelFilter skip entries whose level is not one of Levels, parsed with
+// ParseLevel so "warning" also keeps the "WARN" entries. The levels which
+// are not recognized are compared case insensitively with the level token.
+// NewLevelFilter parse the levels once, rather than for each entry.
+type LevelFilter struct {
+	Levels []string
+	parsed bool
+	levels []Level  // the recognized Levels
+	tokens []string // the other Levels
+}
+
+// NewLevelFilter return the filter keeping the entries of the levels.
+func NewLevelFilter(levels ...string) LevelFilter {
+	f := LevelFilter{Levels: levels, parsed: true}
+	for _, l := range levels {
+		if level, err := ParseLevel(l); err == nil {
+			f.levels = append(f.levels, level)
+		} else {
+			f.tokens = append(f.tokens, l)
+		}
+	}
+	return f
+}
+
+func (f LevelFilter) Skip(entry LogEntry) bool {
+	if !f.parsed {
+		f = NewLevelFilter(f.Levels...)
+	}
+	for _, l := range f.levels {
+		if l == entry.level {
+			return false
+		}
+	}
+	if entry.level == LevelUnknown {
+		for _, token := range f.tokens {
+			if strings.EqualFold(token, entry.rawLevel) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (f LevelFilter) String() string {
+	return fmt.Sprintf("level not in [%s]", strings.Join(f.Levels, ","))
+}
+
+func (f LevelFilter) Explain(entry LogEntry) (bool, string) {
+	if !f.Skip(entry) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("level '%s' not in [%s]", strings.ToLower(entry.levelToken()), strings.Join(f.Levels, ","))
+}
+
+// SeverityFilter skip entries whose severity, as ranked by LevelSeverity,
+// is below Min or above Max, as well as the entries of unknown severity.
+// Max is zero, i.e. debug, unless set, so NewSeverityFilter or
+// MinimumSeverityFilter are usually more convenient.
+type SeverityFilter struct {
+	Min int
+	Max int
+}
+
+// NewSeverityFilter return the filter keeping the entries from the severity
+// of min to the one of max, either being the least or the most severe
+// when empty. It returns an error if a level is not recognized.
+func NewSeverityFilter(min, max string) (SeverityFilter, error) {
+	f := SeverityFilter{Max: len(severityNames) - 1}
+	var err error
+	if min != "" {
+		if f.Min, err = ParseSeverity(min); err != nil {
+			return f, err
+		}
+	}
+	if max != "" {
+		if f.Max, err = ParseSeverity(max); err != nil {
+			return f, err
+		}
+	}
+	if f.Min > f.Max {
+		return f, fmt.Errorf("minimum severity %s above the maximum %s", severityNames[f.Min], severityNames[f.Max])
+	}
+	return f, nil
+}
+
+// MinimumSeverityFilter return a filter skipping entries less severe than
+// level, e.g. "warn" keeps the warn, error and fatal entries. It returns an
+// error if the level is not recognized.
+func MinimumSeverityFilter(level string) (FilterFunc, error) {
+	f, err := NewSeverityFilter(level, "")
+	if err != nil {
+		return nil, err
+	}
+	return f.Skip, nil
+}
+
+func (f SeverityFilter) Skip(entry LogEntry) bool {
+	s := entry.severity()
+	return s < 0 || s < f.Min || s > f.Max
+}
+
+func (f SeverityFilter) String() string {
+	return fmt.Sprintf("severity not within [%s,%s]", severityNames[f.Min], severityNames[f.Max])
+}
+
+func (f SeverityFilter) Explain(entry LogEntry) (bool, string) {
+	token := strings.ToLower(entry.levelToken())
+	switch s := LevelSeverity(token); {
+	case s < 0:
+		return true, fmt.Sprintf("level '%s' of unknown severity", token)
+	case s < f.Min:
+		return true, fmt.Sprintf("level '%s' below %s", token, severityNames[f.Min])
+	case s > f.Max:
+		return true, fmt.Sprintf("level '%s' above %s", token, severityNames[f.Max])
+	}
+	return false, ""
+}
+
+// TimeRangeFilter skip entries before Start or after End,
+// a zero Start or End leaves the range open on that side.
+type TimeRangeFilter struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (f TimeRangeFilter) Skip(entry LogEntry) bool {
+	return (!f.Start.IsZero() && entry.time.Before(f.Start)) || (!f.End.IsZero() && entry.time.After(f.End))
+}
+
+func (f TimeRangeFilter) String() string {
+	var bounds []string
+	if !f.Start.IsZero() {
+		bounds = append(bounds, "before "+f.Start.Format(time.DateTime))
+	}
+	if !f.End.IsZero() {
+		bounds = append(bounds, "after "+f.End.Format(time.DateTime))
+	}
+	if len(bounds) == 0 {
+		return "none"
+	}
+	return "time " + strings.Join(bounds, " or ")
+}
+
+func (f TimeRangeFilter) Explain(entry LogEntry) (bool, string) {
+	if !f.Start.IsZero() && entry.time.Before(f.Start) {
+		return true, fmt.Sprintf("time %s before start %s", entry.time.Format(time.DateTime), f.Start.Format(time.DateTime))
+	}
+	if !f.End.IsZero() && entry.time.After(f.End) {
+		return true, fmt.Sprintf("time %s after end %s", entry.time.Format(time.DateTime), f.End.Format(time.DateTime))
+	}
+	return false, ""
+}
+
+// Around return a filter skipping entries more than radius away from at,
+// i.e. a time range centered on at.
+func Around(at time.Time, radius time.Duration) TimeRangeFilter {
+	return TimeRangeFilter{Start: at.Add(-radius), End: at.Add(radius)}
+}
+
+// MessagePatternFilter skip entries whose message does not match Pattern.
+type MessagePatternFilter struct {
+	Pattern *regexp.Regexp
+}
+
+func (f MessagePatternFilter) Skip(entry LogEntry) bool {
+	return !f.Pattern.MatchString(entry.message)
+}
+
+func (f MessagePatternFilter) String() string {
+	return fmt.Sprintf("message not matching '%s'", f.Pattern)
+}
+
+func (f MessagePatternFilter) Explain(entry LogEntry) (bool, string) {
+	if f.Pattern.MatchString(entry.message) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("message does not match '%s'", f.Pattern)
+}
+
+// ExplainTo return the filter f called name which write the reason of each
+// skipped entry to w. e.g:
+//
+//	filter "level" skipped entry at 2025-01-01 10:00:00: level 'debug' not in [info]
+func ExplainTo(w io.Writer, name string, f ExplainableFilter) NamedFilter {
+	return NamedFilter{Name: name, Description: describe(f), Filter: func(entry LogEntry) bool {
+		skipped, reason := f.Explain(entry)
+		if skipped {
+			fmt.Fprintf(w, "filter %q skipped entry at %s: %s\n", name, entry.time.Format(time.DateTime), reason)
+		}
+		return skipped
+	}}
+}
+
+// describe return the description of the filter f,
+// empty unless it is a fmt.Stringer.
+func describe(f ExplainableFilter) string {
+	if s, ok := f.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return ""
+}
+
+// filterEntries return the entries not skipped by the filter.
+func filterEntries(entries []LogEntry, filter []NamedFilter) []LogEntry {
+	var kept []LogEntry
+	for _, entry := range entries {
+		if !skip(entry, filter) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// skip report whether any of the filter wants the entry skipped.
+func skip(entry LogEntry, filter []NamedFilter) bool {
+	_, skipped := skippedBy(entry, filter)
+	return skipped
+}
+
+// skippedBy return the first of the filter which wants the entry skipped.
+func skippedBy(entry LogEntry, filter []NamedFilter) (NamedFilter, bool) {
+	for _, f := range filter {
+		if f.Filter(entry) {
+			return f, true
+		}
+	}
+	return NamedFilter{}, false
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFiltersExplain(t *testing.T) {
+	parse := func(line string) LogEntry {
+		t.Helper()
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entry
+	}
+	errorEntry := parse("2025-01-01 10:00:00 ERROR Connection lost")
+	infoEntry := parse("2025-01-01 12:00:00 INFO Request processed in 10 ms")
+	start, _ := time.Parse(time.DateTime, "2025-01-01 11:00:00")
+	end, _ := time.Parse(time.DateTime, "2025-01-01 11:30:00")
+
+	for _, tt := range []struct {
+		name    string
+		filter  ExplainableFilter
+		entry   LogEntry
+		skipped bool
+		reason  string
+	}{
+		{"level", LevelFilter{Levels: []string{"info"}}, errorEntry, true, "level 'error' not in [info]"},
+		{"level kept", LevelFilter{Levels: []string{"info", "ERROR"}}, errorEntry, false, ""},
+		{"start", TimeRangeFilter{Start: start}, errorEntry, true, "time 2025-01-01 10:00:00 before start 2025-01-01 11:00:00"},
+		{"end", TimeRangeFilter{Start: start, End: end}, infoEntry, true, "time 2025-01-01 12:00:00 after end 2025-01-01 11:30:00"},
+		{"open range", TimeRangeFilter{}, infoEntry, false, ""},
+		{"pattern", MessagePatternFilter{Pattern: regexp.MustCompile(`^Request`)}, errorEntry, true, "message does not match '^Request'"},
+		{"pattern kept", MessagePatternFilter{Pattern: regexp.MustCompile(`^Request`)}, infoEntry, false, ""},
+		{"severity", SeverityFilter{Min: 2, Max: 4}, infoEntry, true, "level 'info' below warn"},
+		{"severity max", SeverityFilter{Min: 0, Max: 2}, errorEntry, true, "level 'error' above warn"},
+		{"severity kept", SeverityFilter{Min: 2, Max: 4}, errorEntry, false, ""},
+	} {
+		skipped, reason := tt.filter.Explain(tt.entry)
+		if skipped != tt.skipped || reason != tt.reason {
+			t.Errorf("%s: Explain = %v, %q, want %v, %q", tt.name, skipped, reason, tt.skipped, tt.reason)
+		}
+		if got := tt.filter.Skip(tt.entry); got != tt.skipped {
+			t.Errorf("%s: Skip = %v, want %v as Explain", tt.name, got, tt.skipped)
+		}
+	}
+}
+
+func TestExplainTo(t *testing.T) {
+	var b strings.Builder
+	filter := ExplainTo(&b, "level", LevelFilter{Levels: []string{"info"}})
+	if filter.String() != "level" {
+		t.Errorf("got the filter %q, want level", filter)
+	}
+	for _, line := range []string{"2025-01-01 10:00:00 ERROR Connection lost", "2025-01-01 10:00:01 INFO Started"} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter.Skip(entry)
+	}
+	if want := "filter \"level\" skipped entry at 2025-01-01 10:00:00: level 'error' not in [info]\n"; b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+// The -explain output name the filter of each skipped entry.
+func TestExplainFlag(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 DEBUG Cache warmed",
+		"2025-01-01 10:00:01 INFO Started",
+		"2025-01-01 10:00:02 INFO Request processed in 10 ms",
+		"2025-01-01 12:00:00 INFO Stopped",
+	)
+	_, stderr, status := runMain(t, "-explain", "-end", "2025-01-01T11:00:00", "-match", "^Request", path)
+	if status != 0 {
+		t.Fatalf("got %q, exit %d", stderr, status)
+	}
+	for _, want := range []string{
+		`filter "level" skipped entry at 2025-01-01 10:00:00: level 'debug' not in [info]`,
+		`filter "match" skipped entry at 2025-01-01 10:00:01: message does not match '^Request'`,
+		`filter "start/end" skipped entry at 2025-01-01 12:00:00: time 2025-01-01 12:00:00 after end 2025-01-01 11:00:00`,
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("got %q, want %q", stderr, want)
+		}
+	}
+}
+
+// The filters given without name are applied as the named ones.
+func TestWithFilters(t *testing.T) {
+	entries := generateEntries(100, 0)
+	isDebug := func(e LogEntry) bool { return e.level == LevelDebug }
+	unnamed, err := Analyze(entries, WithFilters(isDebug))
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := Analyze(entries, WithNamedFilters(NamedFilter{Name: "debug", Filter: isDebug}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unnamed.TotalEntries != named.TotalEntries || named.Debug != 0 || named.TotalEntries == len(entries) {
+		t.Errorf("got %d entries without name and %d with, want the debug entries skipped", unnamed.TotalEntries, named.TotalEntries)
+	}
+	if _, err := newOptions(WithNamedFilters(NamedFilter{Name: "nil"})); err == nil {
+		t.Error("accepted a filter without function")
+	}
+}
+
+// Around keep the entries within the radius on both sides of its time.
+func TestAround(t *testing.T) {
+	at, _ := time.Parse(time.DateTime, "2025-01-01 12:00:00")
+	filter := Around(at, 5*time.Minute)
+	var kept []string
+	for _, ts := range []string{"11:54:59", "11:55:00", "11:59:00", "12:00:00", "12:04:59", "12:05:00", "12:05:01"} {
+		entry, err := NewLogEntry("2025-01-01 " + ts + " INFO tick")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !filter.Skip(entry) {
+			kept = append(kept, ts)
+		}
+	}
+	if got := strings.Join(kept, " "); got != "11:55:00 11:59:00 12:00:00 12:04:59 12:05:00" {
+		t.Errorf("got the entries at %s, want those from 11:55:00 to 12:05:00", got)
+	}
+	if f := Around(at, 0); !f.Start.Equal(at) || !f.End.Equal(at) {
+		t.Errorf("got %v to %v for a zero radius, want %v", f.Start, f.End, at)
+	}
+}
+
+// skipReasonsLog has a line or entry of each skip reason, along
+// with the entries kept by the filters of TestSkipReasons.
+const skipReasonsLog = `2025-01-01 10:00:00 INFO Started
+truncated
+2025-01-01 10:00:0x INFO Bad timestamp
+2025-01-01 10:00:01 DEBUG Cache warmed
+2025-01-01 10:00:02 DEBUG Cache refreshed
+2025-01-01 10:00:03 ERROR Connection lost
+2025-01-01 12:00:00 INFO Stopped
+2025-01-01 12:00:01 INFO Stopped
+2025-01-01 12:00:02 INFO Stopped
+`
+
+func TestSkipReasons(t *testing.T) {
+	end := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+	filters := []NamedFilter{
+		{Name: "level", Filter: LevelFilter{Levels: []string{"info", "error"}}.Skip},
+		{Name: "start/end", Filter: TimeRangeFilter{End: end}.Skip},
+	}
+	want := map[string]int{"too few fields": 1, "bad timestamp": 1, "filtered by level": 2, "filtered by start/end": 3}
+	for _, workers := range []int{0, 2} {
+		opts := []Option{WithNamedFilters(filters...), WithSkipReasons(true), WithInvalidLineHandler(DiscardInvalidLine)}
+		var report *AnalysisReport
+		var err error
+		if workers == 0 {
+			report, err = AnalyzeSource(NewReader(strings.NewReader(skipReasonsLog)), opts...)
+		} else {
+			report, err = AnalyzeReader(strings.NewReader(skipReasonsLog), append(opts, WithWorkers(workers))...)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(report.SkipReasons, want) || report.TotalEntries != 2 || report.SkippedLines != 2 {
+			t.Errorf("with %d workers: got the skip reasons %v, %d entries and %d skipped lines, want %v, 2 and 2", workers, report.SkipReasons, report.TotalEntries, report.SkippedLines, want)
+		}
+	}
+
+	// The skip reasons are only counted when asked for.
+	report, err := AnalyzeReader(strings.NewReader(skipReasonsLog), WithNamedFilters(filters...), WithInvalidLineHandler(DiscardInvalidLine))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SkipReasons != nil {
+		t.Errorf("got the skip reasons %v without WithSkipReasons", report.SkipReasons)
+	}
+}
+
+// The -explain report has a table of the skip reasons, the most frequent first.
+func TestExplainSkipReasons(t *testing.T) {
+	path := writeLines(t, "app.log", strings.Split(strings.TrimSuffix(skipReasonsLog, "\n"), "\n")...)
+	stdout, stderr, status := runMain(t, "-explain", "-level", "info,error", "-end", "2025-01-01T11:00:00", path)
+	if status != 0 {
+		t.Fatalf("got %q, exit %d", stderr, status)
+	}
+	want := "Skip Reasons:\n" +
+		"  filtered by start/end    3\n" +
+		"  filtered by level        2\n" +
+		"  bad timestamp            1\n" +
+		"  too few fields           1\n"
+	if !strings.Contains(stdout, want) {
+		t.Errorf("got %q, want %q", stdout, want)
+	}
+	if stdout, _, _ := runMain(t, "-level", "info,error", path); strings.Contains(stdout, "Skip Reasons") {
+		t.Errorf("got %q, want the skip reasons only with -explain", stdout)
+	}
+}
+
+func TestMinimumSeverityFilter(t *testing.T) {
+	if _, err := MinimumSeverityFilter("loud"); err == nil {
+		t.Error("got no error for an unknown level")
+	}
+	if _, err := NewSeverityFilter("error", "warn"); err == nil {
+		t.Error("got no error for a minimum above the maximum")
+	}
+	skip, err := MinimumSeverityFilter("WARN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, line := range []string{
+		"2025-01-01 10:00:00 DEBUG polling",
+		"2025-01-01 10:00:01 INFO started",
+		"2025-01-01 10:00:02 WARNING slow",
+		"2025-01-01 10:00:03 ERROR lost",
+		"2025-01-01 10:00:04 FATAL crashed",
+		"2025-01-01 10:00:05 AUDIT login",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !skip(entry) {
+			kept = append(kept, entry.levelToken())
+		}
+	}
+	if got := strings.Join(kept, ","); got != "WARNING,ERROR,FATAL" {
+		t.Errorf("got the levels %s kept, want WARNING,ERROR,FATAL", got)
+	}
+}
+
+// The severity flags replace the -level list.
+func TestMinSeverityFlag(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 DEBUG polling",
+		"2025-01-01 10:00:01 INFO started",
+		"2025-01-01 10:00:02 WARN slow",
+		"2025-01-01 10:00:03 ERROR lost")
+	stdout, stderr, status := runMain(t, "-min-severity", "warn", "-format", "json", path)
+	if status != 0 || !strings.Contains(stdout, `"total_entries":2,"info":0,"warn":1,"error":1,"debug":0`) {
+		t.Errorf("got %q, %q, exit %d, want the warn and error entries", stdout, stderr, status)
+	}
+	stdout, stderr, status = runMain(t, "-max-severity", "info", "-format", "json", path)
+	if status != 0 || !strings.Contains(stdout, `"total_entries":2,"info":1,"warn":0,"error":0,"debug":1`) {
+		t.Errorf("got %q, %q, exit %d, want the debug and info entries", stdout, stderr, status)
+	}
+	if _, stderr, status := runMain(t, "-min-severity", "loud", path); status == 0 || !strings.Contains(stderr, `unknown level "loud"`) {
+		t.Errorf("got %q, exit %d, want the unknown level rejected", stderr, status)
+	}
+}
+
+// Skip agrees with Explain without allocating, the levels of the filters
+// built by NewLevelFilter being parsed once.
+func TestFiltersSkip(t *testing.T) {
+	start, _ := time.Parse(time.DateTime, "2025-01-01 10:00:00")
+	filters := []ExplainableFilter{
+		LevelFilter{Levels: []string{"warning", "audit"}},
+		NewLevelFilter("info", "ERROR", "audit"),
+		SeverityFilter{Min: 1, Max: 3},
+		SeverityFilter{Min: 4, Max: 4},
+		TimeRangeFilter{Start: start, End: start.Add(time.Minute)},
+		TimeRangeFilter{End: start},
+	}
+	var entries []LogEntry
+	for i, token := range []string{"DEBUG", "info", "[WARN]", "Warning", "ERROR", "err", "FATAL", "[panic]", "crit:", "AUDIT", "other", ""} {
+		level, _ := ParseLevel(token)
+		if token == "" {
+			level = LevelNone
+		}
+		entries = append(entries, LogEntry{time: start.Add(time.Duration(i*10) * time.Second), level: level, rawLevel: token})
+	}
+	for _, f := range filters {
+		for _, entry := range entries {
+			skipped, _ := f.Explain(entry)
+			if got := f.Skip(entry); got != skipped {
+				t.Errorf("%v: Skip(%q) = %v, want %v as Explain", f, entry.rawLevel, got, skipped)
+			}
+		}
+	}
+	for _, f := range filters[1:] {
+		allocs := testing.AllocsPerRun(10, func() {
+			for _, entry := range entries {
+				f.Skip(entry)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("%v: got %.0f allocations skipping the entries, want none", f, allocs)
+		}
+	}
+}
+
+// The severity of an entry is the LevelSeverity of its token.
+func TestEntrySeverity(t *testing.T) {
+	for _, token := range []string{"debug", "TRACE", "info", "Warning", "[ERROR]", "err", "FATAL", "Panic:", "<crit>", "emergency", "other"} {
+		level, _ := ParseLevel(token)
+		if got, want := (LogEntry{level: level, rawLevel: token}).severity(), LevelSeverity(token); got != want {
+			t.Errorf("%q: got the severity %d, want %d", token, got, want)
+		}
+	}
+	if got := (LogEntry{level: LevelNone}).severity(); got != -1 {
+		t.Errorf("got the severity %d without level, want -1", got)
+	}
+}
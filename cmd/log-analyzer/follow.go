@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// FollowFull and FollowDelta are the -follow-mode printing either
+// the whole report or what changed since the previous one.
+const (
+	FollowFull  = "full"
+	FollowDelta = "delta"
+)
+
+// pollInterval is how long the follower waits for new data once it
+// reached the end of the file.
+const pollInterval = 250 * time.Millisecond
+
+// Follower keep analyzing lines appended to a log file and
+// periodically emit the report.
+type Follower struct {
+	// ReportEvery is the number of ticks to accumulate entries for before
+	// emitting the report and starting over with an empty one. Zero keeps
+	// a cumulative report which is emitted on every tick.
+	ReportEvery int
+	// Options configure the analysis, as for Analyze.
+	Options []Option
+	// Emit is called with the current report, unless it has no entries.
+	Emit func(*AnalysisReport)
+	// EmitDelta, if set, is called instead of Emit after the first report
+	// with what changed since the previous emitted report, unless nothing did.
+	EmitDelta func(*ReportDelta)
+}
+
+// Run analyze lines received from lines and emit the report as ticks arrive,
+// until the context is done or lines gets closed.
+func (f *Follower) Run(ctx context.Context, lines <-chan string, ticks <-chan time.Time) error {
+	o, err := newOptions(f.Options...)
+	if err != nil {
+		return err
+	}
+	report := o.newReport()
+	check := o.newCheck()
+	var (
+		n, lineNo int
+		prev      *AnalysisReport // previous emitted report, with EmitDelta
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				return nil
+			}
+			lineNo++
+			if o.lines.skip(line) {
+				continue
+			}
+			entry, err := o.lines.parse(lineNo, line)
+			if check.inHeader(err) {
+				continue
+			}
+			report.TotalLines++
+			if report.full() {
+				continue
+			}
+			if err != nil {
+				report.skipLine(err)
+			}
+			if err := check.line(err); err != nil {
+				if report.TotalEntries > 0 {
+					report.finish()
+					f.Emit(report)
+				}
+				return report.stop(err)
+			}
+			if err != nil {
+				continue
+			}
+			if f, ok := skippedBy(entry, o.filters); ok {
+				report.filtered(f)
+				continue
+			}
+			report.Add(entry)
+		case <-ticks:
+			n++
+			if f.ReportEvery > 0 && n%f.ReportEvery != 0 {
+				continue
+			}
+			if report.TotalEntries > 0 {
+				report.finish()
+				f.emit(report, prev)
+				if f.EmitDelta != nil {
+					prev = report.Clone()
+				}
+			}
+			if f.ReportEvery > 0 {
+				report = o.newReport()
+			}
+		}
+	}
+}
+
+// emit hand the report to EmitDelta as the delta since prev when both
+// are set and something changed, or else to Emit.
+func (f *Follower) emit(report, prev *AnalysisReport) {
+	if f.EmitDelta == nil || prev == nil {
+		f.Emit(report)
+		return
+	}
+	if d := report.Delta(prev); !d.Empty() {
+		f.EmitDelta(d)
+	}
+}
+
+// Tail send each complete line read from r to lines, waiting for more data
+// once the end is reached instead of stopping, like 'tail -f'.
+// It returns when the context is done or reading fails.
+func Tail(ctx context.Context, r io.Reader, lines chan<- string) error {
+	defer close(lines)
+	br := bufio.NewReader(r)
+	var partial strings.Builder
+	for {
+		chunk, err := br.ReadString('\n')
+		partial.WriteString(chunk)
+		if err == nil {
+			select {
+			case lines <- strings.TrimRight(partial.String(), "\r\n"):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			partial.Reset()
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// runFollower run f over the unbuffered lines and ticks handed to feed,
+// returning the totals of the emitted reports once feed returns.
+func runFollower(t *testing.T, f *Follower, feed func(lines chan<- string, ticks chan<- time.Time)) []int {
+	t.Helper()
+	var emitted []int
+	f.Emit = func(r *AnalysisReport) { emitted = append(emitted, r.TotalEntries) }
+	lines, ticks := make(chan string), make(chan time.Time)
+	done := make(chan error)
+	go func() { done <- f.Run(context.Background(), lines, ticks) }()
+	feed(lines, ticks)
+	close(lines)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	return emitted
+}
+
+func TestFollowerReportEvery(t *testing.T) {
+	emitted := runFollower(t, &Follower{ReportEvery: 3}, func(lines chan<- string, ticks chan<- time.Time) {
+		for tick := 0; tick < 6; tick++ {
+			for i := 0; i <= tick; i++ {
+				lines <- fmt.Sprintf("2025-01-01 10:00:%02d INFO tick %d", tick, tick)
+			}
+			ticks <- time.Time{}
+		}
+	})
+	// The reports of ticks 1 to 3 and 4 to 6, with 1+2+3 and 4+5+6 entries.
+	if fmt.Sprint(emitted) != "[6 15]" {
+		t.Errorf("got reports of %v entries, want [6 15]", emitted)
+	}
+}
+
+func TestFollowerCumulative(t *testing.T) {
+	emitted := runFollower(t, &Follower{}, func(lines chan<- string, ticks chan<- time.Time) {
+		ticks <- time.Time{} // nothing to report yet
+		for tick := 0; tick < 3; tick++ {
+			lines <- "2025-01-01 10:00:00 INFO started"
+			ticks <- time.Time{}
+		}
+	})
+	if fmt.Sprint(emitted) != "[1 2 3]" {
+		t.Errorf("got reports of %v entries, want the cumulative [1 2 3]", emitted)
+	}
+}
+
+// With EmitDelta the reports after the first one are emitted as the
+// changes since the previous emitted report, the ticks without any
+// change emitting nothing.
+func TestFollowerDelta(t *testing.T) {
+	var deltas []*ReportDelta
+	f := &Follower{EmitDelta: func(d *ReportDelta) { deltas = append(deltas, d) }}
+	emitted := runFollower(t, f, func(lines chan<- string, ticks chan<- time.Time) {
+		lines <- "2025-01-01 10:00:00 INFO Started"
+		lines <- "2025-01-01 10:00:01 ERROR Connection lost"
+		ticks <- time.Time{}
+		ticks <- time.Time{} // no change
+		lines <- "2025-01-01 10:00:02 ERROR Connection lost"
+		lines <- "2025-01-01 10:00:03 ERROR Disk full"
+		lines <- "2025-01-01 10:00:04 WARN Memory usage is high"
+		ticks <- time.Time{}
+		lines <- "2025-01-01 10:00:05 INFO Stopped"
+		ticks <- time.Time{}
+	})
+	if fmt.Sprint(emitted) != "[2]" {
+		t.Errorf("got full reports of %v entries, want only the first one of 2", emitted)
+	}
+	want := []*ReportDelta{
+		{TotalEntries: 3, Warn: 1, Error: 2, Messages: []MessageCount{{"Connection lost", 1}, {"Disk full", 1}, {"Memory usage is high", 1}}},
+		{TotalEntries: 1, Info: 1, Messages: []MessageCount{{"Stopped", 1}}},
+	}
+	if !reflect.DeepEqual(deltas, want) {
+		t.Errorf("got the deltas %+v, want %+v", deltas, want)
+	}
+}
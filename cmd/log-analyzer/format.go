@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatTable    = "table"
+	FormatMarkdown = "markdown"
+	FormatGraphite = "graphite"
+	// FormatCSV is only supported by the -group-by-day
+	// and -window-stats summaries.
+	FormatCSV = "csv"
+	// FormatParquet, FormatInfluxDB, FormatOTel and FormatGELF write the
+	// filtered entries rather than a report, FormatLoki and FormatDatadog
+	// send them.
+	FormatParquet  = "parquet"
+	FormatInfluxDB = "influxdb"
+	FormatOTel     = "opentelemetry"
+	FormatGELF     = "gelf"
+	FormatLoki     = "loki"
+	FormatDatadog  = "datadog"
+)
+
+// markdownTopN is the number of messages listed in the markdown report
+// when the analysis did not compute the top messages itself.
+const markdownTopN = 10
+
+// WriteJSON write the report to w as json. The output is a compact
+// single line unless pretty is set, in which case it is indented
+// with two spaces.
+func (r *AnalysisReport) WriteJSON(w io.Writer, pretty bool) error {
+	var (
+		b   []byte
+		err error
+	)
+	if pretty {
+		b, err = json.MarshalIndent(r, "", "  ")
+	} else {
+		b, err = json.Marshal(r)
+	}
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
+
+// AsTable return the report as a header row followed by one row per
+// metric, every row having the same number of columns. It holds the data
+// only, leaving the presentation to the renderer.
+func (r *AnalysisReport) AsTable() [][]string {
+	table := [][]string{
+		{"METRIC", "VALUE"},
+		{"Total Log Entries", strconv.Itoa(r.TotalEntries)},
+	}
+	for _, l := range r.levelCounts() {
+		table = append(table, []string{l.name(), strconv.Itoa(l.count)})
+	}
+	if first, last, ok := r.TimeRange(); ok {
+		table = append(table,
+			[]string{"First Entry", first.Format(time.DateTime)},
+			[]string{"Last Entry", last.Format(time.DateTime)},
+		)
+	}
+	if avg, ok := r.AverageResponseTime(); ok {
+		table = append(table, []string{"Average Response Time (ms)", fmt.Sprintf("%.2f", avg)})
+	}
+	for _, p := range r.Percentiles {
+		table = append(table, []string{fmt.Sprintf("p%g Response Time (ms)", p.P), fmt.Sprintf("%.2f", p.Value)})
+	}
+	if r.Stopped != nil {
+		table = append(table, []string{"Stopped Early at Line", strconv.Itoa(r.Stopped.Line)})
+	}
+	return table
+}
+
+// WriteTable write the report table to w with right justified columns.
+func (r *AnalysisReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, row := range r.AsTable() {
+		for _, cell := range row {
+			fmt.Fprintf(tw, "%s\t", cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown write the report to w as a Markdown document with tables
+// in pipe syntax, suitable for pasting into issues and pull requests. The
+// summary is the AsTable table, followed by the response time statistics
+// it does not hold and the top messages.
+func (r *AnalysisReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Log Analysis Report\n\n")
+	if r.Stopped != nil {
+		fmt.Fprintf(&b, "> **Warning:** analysis stopped early at line %d after %d parse errors.\n\n", r.Stopped.Line, r.Stopped.Errors)
+	}
+	writeMarkdownTable(&b, r.AsTable(), "---", "---:")
+
+	if _, ok := r.AverageResponseTime(); ok {
+		b.WriteString("\n## Response Time\n\n")
+		writeMarkdownTable(&b, [][]string{
+			{"Statistic", "Value (ms)"},
+			{"Count", strconv.Itoa(r.ResponseCount)},
+			{"Min", fmt.Sprintf("%.2f", r.ResponseMin)},
+			{"Max", fmt.Sprintf("%.2f", r.ResponseMax)},
+		}, "---", "---:")
+	}
+
+	top := r.Top
+	if top == nil {
+		top = r.TopMessages(markdownTopN)
+	}
+	if len(top) > 0 {
+		b.WriteString("\n## Top Messages\n\n")
+		table := [][]string{{"Count", "Message"}}
+		for _, m := range top {
+			table = append(table, []string{strconv.Itoa(m.Count), m.Message})
+		}
+		writeMarkdownTable(&b, table, "---:", "---")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownTable write the table, its header row followed by the data
+// rows, to b in pipe syntax, the columns being aligned as the delimiter
+// row cells align, e.g. "---:" for a right aligned column.
+func writeMarkdownTable(b *strings.Builder, table [][]string, align ...string) {
+	for i, row := range table {
+		for _, cell := range row {
+			fmt.Fprintf(b, "| %s ", markdownEscape(cell))
+		}
+		b.WriteString("|\n")
+		if i == 0 {
+			b.WriteString("| " + strings.Join(align, " | ") + " |\n")
+		}
+	}
+}
+
+// markdownEscape escape the characters of s which would
+// break a Markdown table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
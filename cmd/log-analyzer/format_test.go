@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Every row of the table has the columns of the header.
+func TestAsTable(t *testing.T) {
+	full, err := Analyze(generateEntries(500, 0), WithPercentiles(50, 90, 99), WithTopN(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, report := range map[string]*AnalysisReport{
+		"empty": NewAnalysisReport(),
+		"full":  full,
+	} {
+		table := report.AsTable()
+		if len(table) < 2 {
+			t.Fatalf("%s: got %d rows, want a header and rows", name, len(table))
+		}
+		for i, row := range table {
+			if len(row) != len(table[0]) {
+				t.Errorf("%s: row %d %q has %d columns, the header %d", name, i, row, len(row), len(table[0]))
+			}
+		}
+	}
+	if rows := len(full.AsTable()); rows <= len(NewAnalysisReport().AsTable())+3 {
+		t.Errorf("got %d rows, want the time range and response times of the full report", rows)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	report, err := Analyze(generateEntries(100, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := report.WriteTable(&b); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != len(report.AsTable()) {
+		t.Fatalf("got %d lines, want a line per row:\n%s", len(lines), b.String())
+	}
+	// The columns are right justified, so the lines are all as long.
+	for _, line := range lines {
+		if len(line) != len(lines[0]) {
+			t.Errorf("got the table\n%s\nwant right justified columns", b.String())
+			break
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:00 INFO Request processed in 100 ms",
+		"2025-01-01 10:00:01 INFO Request processed in 300 ms",
+		"2025-01-01 10:00:02 WARN Cache miss | key=user",
+		"2025-01-01 10:00:03 ERROR Connection lost",
+		"2025-01-01 10:00:04 ERROR Connection lost",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	report, err := Analyze(entries, WithPercentiles(50), WithTopN(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := report.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# Log Analysis Report
+
+| METRIC | VALUE |
+| --- | ---: |
+| Total Log Entries | 5 |
+| DEBUG | 0 |
+| INFO | 2 |
+| WARN | 1 |
+| ERROR | 2 |
+| First Entry | 2025-01-01 10:00:00 |
+| Last Entry | 2025-01-01 10:00:04 |
+| Average Response Time (ms) | 200.00 |
+| p50 Response Time (ms) | 100.00 |
+
+## Response Time
+
+| Statistic | Value (ms) |
+| --- | ---: |
+| Count | 2 |
+| Min | 100.00 |
+| Max | 300.00 |
+
+## Top Messages
+
+| Count | Message |
+| ---: | --- |
+| 2 | Connection lost |
+| 1 | Cache miss \| key=user |
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// fuzzLines are the seed lines of the fuzz targets, along with the
+// corpus kept in testdata/fuzz.
+var fuzzLines = []string{
+	"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+	"2025-01-01 10:00:00 ERROR [api] Connection lost",
+	"2025-01-01 10:00:00.123 WARN Memory usage is high",
+	"2025-01-01 10:00:00 INFO ",
+	"2025-01-01 10:00:00 INFO",
+	"0000-00-00 00:00:00 INFO Zero date",
+	"0001-01-01 00:00:00 INFO Zero time",
+	"9999-12-31 23:59:59 INFO Last second",
+	"2024-02-29 10:00:00 INFO Leap day",
+	"2025-02-29 10:00:00 INFO Not a leap day",
+	"2025-01-01 24:00:00 INFO Midnight",
+	"2025-01-01 10:00:60 INFO Leap second",
+	"2025-１-01 10:00:00 INFO Fullwidth digit",
+	"2025-01-01 10:00:00 INFO No-break space",
+	"2025-01-01 10:00:0é INFO Multibyte in the time",
+	"2025-01-01 10:00:00 ÉRROR Multibyte level",
+	"    ",
+	"",
+	" 2025-01-01 10:00:00 INFO Leading space",
+	"2025-01-01  10:00:00 INFO Double space",
+	"2025-01-01 10:00:00 " + strings.Repeat("X", 4096) + " Enormous level",
+	`{"time":"2025-01-01T10:00:00Z","level":"info","msg":"Request processed"}`,
+	`{"time":"0001-01-01T00:00:00Z","level":"info","msg":"Zero time"}`,
+	`time=2025-01-01T10:00:00Z level=error msg="Connection lost"`,
+	`time="" level= msg=`,
+	`<165>1 2025-01-01T10:00:00Z web-1 api 42 ID7 - Request processed`,
+	`<165>1 - - - - - -`,
+	"2025-01-01 10:00:00,INFO,Request processed,user=42",
+}
+
+// checkEntry fail unless the parser returned either an error or an entry
+// with a time, as the analysis takes for granted.
+func checkEntry(t *testing.T, name, line string, entry LogEntry, err error) {
+	t.Helper()
+	if err == nil && entry.time.IsZero() {
+		t.Errorf("%s: got an entry with a zero time and no error parsing %q", name, line)
+	}
+	if err != nil && !entry.Equal(LogEntry{}) {
+		t.Errorf("%s: got the entry %+v along with the error %v parsing %q", name, entry, err, line)
+	}
+}
+
+// NewLogEntry never panic and return either an entry with a time, whose
+// fields are those of the line, or an error.
+func FuzzNewLogEntry(f *testing.F) {
+	for _, line := range fuzzLines {
+		f.Add(line)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		entry, err := NewLogEntry(line)
+		checkEntry(t, "NewLogEntry", line, entry, err)
+		if err == nil && !strings.HasSuffix(line, " "+entry.rawLevel+" "+entry.message) {
+			t.Errorf("got the level %q and message %q, not the fields of %q", entry.rawLevel, entry.message, line)
+		}
+		if err == nil && utf8.ValidString(line) && !utf8.ValidString(entry.message) {
+			t.Errorf("got the invalid message %q of the valid line %q", entry.message, line)
+		}
+
+		entry, err = NewLogEntryNoLevel(line)
+		checkEntry(t, "NewLogEntryNoLevel", line, entry, err)
+	})
+}
+
+// The parsers of the -input formats and of -delimiter never panic and
+// return either an entry with a time or an error, as NewLogEntry.
+func FuzzParsers(f *testing.F) {
+	for _, line := range fuzzLines {
+		f.Add(line)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		for _, name := range Parsers() {
+			p, _ := LookupParser(name)
+			entry, err := p.Parse(line)
+			checkEntry(t, name, line, entry, err)
+		}
+		entry, err := DelimitedParser{Delimiter: ","}.Parse(line)
+		checkEntry(t, "delimited", line, entry, err)
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+)
+
+// gelfVersion is the version of the GELF specification followed by WriteGELF.
+const gelfVersion = "1.1"
+
+// gelfFieldName is the pattern of the names of the GELF additional fields.
+var gelfFieldName = regexp.MustCompile(`^[\w.\-]+$`)
+
+// WriteGELF write the entries to w in the Graylog Extended Log Format as
+// NDJSON, i.e. one GELF message per line, with the given host. The message
+// is the short_message, the time the timestamp in seconds with milliseconds
+// and the level the syslog severity, see syslogSeverity. The source and
+// the structured data are written as additional fields, e.g:
+//
+//	{"_source":"api","host":"web-1","level":3,"short_message":"Failed to connect","timestamp":1705312496.123,"version":"1.1"}
+func WriteGELF(w io.Writer, entries []LogEntry, host string) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, e := range entries {
+		msg := map[string]any{
+			"version":       gelfVersion,
+			"host":          host,
+			"short_message": e.message,
+			"timestamp":     float64(e.time.UnixMilli()) / 1000,
+		}
+		if severity, ok := syslogSeverity(e.level); ok {
+			msg["level"] = severity
+		}
+		if e.source != "" {
+			msg["_source"] = e.source
+		}
+		for k, v := range e.data {
+			// _id is reserved, and the names are restricted.
+			if k != "id" && gelfFieldName.MatchString(k) {
+				msg["_"+k] = v
+			}
+		}
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Each line is a GELF message with the fields required by the specification.
+func TestWriteGELF(t *testing.T) {
+	at := time.Date(2024, 1, 15, 9, 54, 56, 123e6, time.UTC)
+	var b strings.Builder
+	err := WriteGELF(&b, []LogEntry{
+		{time: at, level: LevelError, rawLevel: "ERROR", source: "api", message: "Failed to connect"},
+		{time: at, level: LevelWarn, rawLevel: "warn", message: "Memory usage is high", data: map[string]string{"pid": "42", "id": "1", "a b": "c"}},
+		{time: at, level: LevelInfo, rawLevel: "info", message: "Started"},
+		{time: at, level: LevelDebug, rawLevel: "DEBUG", message: "Cache hit"},
+		{time: at, level: LevelNone, message: "no level"},
+	}, "web-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var levels []any
+	s := bufio.NewScanner(strings.NewReader(b.String()))
+	for s.Scan() {
+		var msg map[string]any
+		d := json.NewDecoder(strings.NewReader(s.Text()))
+		d.UseNumber()
+		if err := d.Decode(&msg); err != nil {
+			t.Fatalf("invalid json %q: %v", s.Text(), err)
+		}
+		if msg["version"] != "1.1" || msg["host"] != "web-1" || msg["short_message"] == "" {
+			t.Errorf("got %s, want the version, host and short_message", s.Text())
+		}
+		if ts, ok := msg["timestamp"].(json.Number); !ok || ts.String() != "1705312496.123" {
+			t.Errorf("got the timestamp %v, want 1705312496.123", msg["timestamp"])
+		}
+		for k := range msg {
+			switch k {
+			case "version", "host", "short_message", "timestamp", "level":
+			case "_id":
+				t.Errorf("got the reserved additional field %s", k)
+			default:
+				if !strings.HasPrefix(k, "_") || !gelfFieldName.MatchString(k) {
+					t.Errorf("got the invalid additional field %q", k)
+				}
+			}
+		}
+		levels = append(levels, msg["level"])
+	}
+	want := []any{json.Number("3"), json.Number("4"), json.Number("6"), json.Number("7"), nil}
+	if len(levels) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(levels), len(want))
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Errorf("message %d: got the level %v, want %v", i, levels[i], want[i])
+		}
+	}
+	if !strings.Contains(b.String(), `"_source":"api"`) || !strings.Contains(b.String(), `"_pid":"42"`) {
+		t.Errorf("got\n%s\nwant the source and pid additional fields", b.String())
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AhmadWaleed/bite/internal/testutil"
+)
+
+// generateEntries return the entries of testutil.GenerateTestEntries.
+func generateEntries(count int, seed int64) []LogEntry {
+	generated := testutil.GenerateTestEntries(count, seed)
+	entries := make([]LogEntry, len(generated))
+	for i, e := range generated {
+		level, _ := ParseLevel(e.Level)
+		entries[i] = LogEntry{time: e.Time, level: level, rawLevel: e.Level, message: e.Message}
+	}
+	return entries
+}
+
+// logText return the entries as the lines of a log file.
+func logText(entries []LogEntry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s %s %s\n", e.time.Format(time.DateTime), e.levelToken(), e.message)
+	}
+	return b.String()
+}
+
+// The generated entries are parsed back from their lines.
+func TestGenerateEntries(t *testing.T) {
+	generated := testutil.GenerateTestEntries(100, 0)
+	entries := generateEntries(100, 0)
+	for i, e := range generated {
+		entry, err := NewLogEntry(e.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !entry.Equal(entries[i]) {
+			t.Errorf("got %+v from %q, want %+v", entry, e.String(), entries[i])
+		}
+	}
+}
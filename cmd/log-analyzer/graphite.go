@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WriteGraphite write the report to w as Graphite plaintext metrics, one
+// per level and the average response time, in the prefix namespace and
+// timestamped with the current time, e.g:
+//
+//	log_analyzer.level.error 300 1705312496
+func WriteGraphite(w io.Writer, report *AnalysisReport, prefix string) error {
+	now := time.Now().Unix()
+	bw := bufio.NewWriter(w)
+	for _, l := range []struct {
+		name  Level
+		count int
+	}{{LevelInfo, report.Info}, {LevelDebug, report.Debug}, {LevelWarn, report.Warn}, {LevelError, report.Error}} {
+		fmt.Fprintf(bw, "%s.level.%s %d %d\n", prefix, l.name, l.count, now)
+	}
+	if avg, ok := report.AverageResponseTime(); ok {
+		fmt.Fprintf(bw, "%s.avg_response_ms %.2f %d\n", prefix, avg, now)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteGraphite(t *testing.T) {
+	report, err := Analyze([]LogEntry{
+		{time: time.Unix(0, 0), level: LevelError, rawLevel: "ERROR", message: "Connection lost"},
+		{time: time.Unix(0, 0), level: LevelInfo, rawLevel: "INFO", message: "Request processed in 10 ms"},
+		{time: time.Unix(0, 0), level: LevelInfo, rawLevel: "INFO", message: "Request processed in 20 ms"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	before := time.Now().Unix()
+	if err := WriteGraphite(&b, report, "app.prod"); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().Unix()
+
+	var metrics []string
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			t.Fatalf("got the line %q, want a path, a value and a timestamp", line)
+		}
+		// The timestamp is the current time, in seconds since the epoch.
+		ts, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil || ts < before || ts > after {
+			t.Errorf("got the timestamp %q, want a Unix time from %d to %d", fields[2], before, after)
+		}
+		metrics = append(metrics, fields[0]+" "+fields[1])
+	}
+	want := "app.prod.level.info 2, app.prod.level.debug 0, app.prod.level.warn 0, app.prod.level.error 1, app.prod.avg_response_ms 15.00"
+	if got := strings.Join(metrics, ", "); got != want {
+		t.Errorf("got the metrics %s, want %s", got, want)
+	}
+}
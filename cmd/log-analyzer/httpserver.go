@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// EntryServer serve a report over HTTP along with
+// the entries it was analyzed from.
+type EntryServer struct {
+	report  *AnalysisReport
+	entries []LogEntry
+}
+
+func NewEntryServer(report *AnalysisReport, entries []LogEntry) *EntryServer {
+	return &EntryServer{report: report, entries: entries}
+}
+
+// Handler return the handler serving the report at / in the format
+// negotiated with the Accept header, the entries as NDJSON at /entries,
+// the Prometheus metrics at /metrics and a health check at /health.
+func (s *EntryServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.serveReport)
+	mux.HandleFunc("GET /entries", s.serveEntries)
+	mux.Handle("GET /metrics", MetricsHandler(s.report))
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "OK")
+	})
+	return mux
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Log Analysis Report</title></head>
+<body>
+<h1>Log Analysis Report</h1>
+<table>
+{{- range $i, $row := .}}
+<tr>{{range $row}}{{if eq $i 0}}<th>{{.}}</th>{{else}}<td>{{.}}</td>{{end}}{{end}}</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+func (s *EntryServer) serveReport(w http.ResponseWriter, r *http.Request) {
+	var err error
+	switch negotiate(r.Header.Get("Accept"), "text/plain", "application/json", "text/html") {
+	case "application/json":
+		w.Header().Set("Content-Type", "application/json")
+		err = s.report.WriteJSON(w, r.URL.Query().Has("pretty"))
+	case "text/html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = reportTemplate.Execute(w, s.report.AsTable())
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		err = s.report.Fprint(w)
+	}
+	if err != nil {
+		log.Println("failed to write report: ", err)
+	}
+}
+
+// serveEntries write the entries matching the query as NDJSON, i.e. one
+// json object per line. The level parameter is a comma separated list of
+// levels, limit the maximum number of entries written.
+func (s *EntryServer) serveEntries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := len(s.entries)
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid limit %q", v), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	var filter LevelFilter
+	if v := q.Get("level"); v != "" {
+		filter.Levels = strings.Split(v, ",")
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	for _, e := range s.entries {
+		if limit == 0 {
+			break
+		}
+		if filter.Levels != nil && filter.Skip(e) {
+			continue
+		}
+		if err := enc.Encode(e); err != nil {
+			log.Println("failed to write entries: ", err)
+			return
+		}
+		limit--
+	}
+}
+
+// negotiate return the media type of offers preferred by the accept
+// header, the first offer if none is acceptable or the header is empty.
+func negotiate(accept string, offers ...string) string {
+	best, bestQ := offers[0], 0.0
+	for _, spec := range strings.Split(accept, ",") {
+		media, params, _ := strings.Cut(strings.TrimSpace(spec), ";")
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		for _, offer := range offers {
+			if q > bestQ && mediaMatch(media, offer) {
+				best, bestQ = offer, q
+			}
+		}
+	}
+	return best
+}
+
+// mediaMatch report whether the media range, e.g. text/*, match typ.
+func mediaMatch(media, typ string) bool {
+	if media == "*/*" || media == typ {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(media, "/*")
+	return ok && strings.HasPrefix(typ, prefix+"/")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestEntryServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:00 INFO Started",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+		"2025-01-01 10:00:02 WARN Connection slow",
+		"2025-01-01 10:00:03 ERROR Connection refused",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	report, err := Analyze(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewEntryServer(report, entries).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// get request the path of srv with the Accept header, returning
+// the status, the content type and the body of the response.
+func get(t *testing.T, srv *httptest.Server, path, accept string) (int, string, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, resp.Header.Get("Content-Type"), string(b)
+}
+
+func TestEntryServerReport(t *testing.T) {
+	srv := newTestEntryServer(t)
+	for _, tt := range []struct {
+		accept, contentType, body string
+	}{
+		{"", "text/plain; charset=utf-8", "Total Log Entries: 4\n"},
+		{"application/json", "application/json", `{"total_entries":4,`},
+		{"text/html", "text/html; charset=utf-8", "<!DOCTYPE html>"},
+		{"text/html;q=0.5, application/json", "application/json", "{"},
+		{"image/png", "text/plain; charset=utf-8", "Total Log Entries: 4\n"},
+	} {
+		status, contentType, body := get(t, srv, "/", tt.accept)
+		if status != http.StatusOK || contentType != tt.contentType || !strings.HasPrefix(body, tt.body) {
+			t.Errorf("Accept %q: got %d %q\n%s\nwant %q starting with %q", tt.accept, status, contentType, body, tt.contentType, tt.body)
+		}
+	}
+	if _, _, body := get(t, srv, "/", "text/html"); !strings.Contains(body, "<th>") || !strings.Contains(body, "<td>4</td>") {
+		t.Errorf("got the html report\n%s\nwant a table", body)
+	}
+}
+
+func TestEntryServerEntries(t *testing.T) {
+	srv := newTestEntryServer(t)
+	for _, tt := range []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Started", "Connection lost", "Connection slow", "Connection refused"}},
+		{"?level=error", []string{"Connection lost", "Connection refused"}},
+		{"?level=error,warn&limit=2", []string{"Connection lost", "Connection slow"}},
+		{"?limit=0", nil},
+	} {
+		status, contentType, body := get(t, srv, "/entries"+tt.query, "")
+		if status != http.StatusOK || contentType != "application/x-ndjson" {
+			t.Errorf("%q: got %d %q, want ndjson", tt.query, status, contentType)
+		}
+		var got []string
+		dec := json.NewDecoder(strings.NewReader(body))
+		for dec.More() {
+			var e struct{ Message string }
+			if err := dec.Decode(&e); err != nil {
+				t.Fatalf("%q: %v in\n%s", tt.query, err, body)
+			}
+			got = append(got, e.Message)
+		}
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") || strings.Count(body, "\n") != len(tt.want) {
+			t.Errorf("%q: got the entries\n%s\nwant %q", tt.query, body, tt.want)
+		}
+	}
+	for _, query := range []string{"?limit=-1", "?limit=all"} {
+		if status, _, _ := get(t, srv, "/entries"+query, ""); status != http.StatusBadRequest {
+			t.Errorf("%q: got %d, want %d", query, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestEntryServerHealth(t *testing.T) {
+	srv := newTestEntryServer(t)
+	if status, _, body := get(t, srv, "/health", ""); status != http.StatusOK || body != "OK\n" {
+		t.Errorf("got %d %q, want 200 OK", status, body)
+	}
+	if status, _, _ := get(t, srv, "/missing", ""); status != http.StatusNotFound {
+		t.Errorf("got %d for a missing page, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"text/plain", "application/json", "text/html"}
+	for accept, want := range map[string]string{
+		"":                                     "text/plain",
+		"*/*":                                  "text/plain",
+		"application/json":                     "application/json",
+		"text/*;q=0.8, application/json;q=0.9": "application/json",
+		"application/*, text/html;q=0.1":       "application/json",
+		"image/png":                            "text/plain",
+	} {
+		if got := negotiate(accept, offers...); got != want {
+			t.Errorf("negotiate(%q) = %s, want %s", accept, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// influxMeasurement is the measurement of the entries written by WriteInfluxDB.
+const influxMeasurement = "log_entry"
+
+var (
+	influxTagEscaper    = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+	influxStringEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+// WriteInfluxDB write the entries to w in the InfluxDB line protocol, one
+// line per entry with the level and source tags, the message string field
+// and the timestamp in nanoseconds, e.g:
+//
+//	log_entry,level=ERROR message="Failed to connect" 1705312496000000000
+func WriteInfluxDB(w io.Writer, entries []LogEntry) error {
+	bw := bufio.NewWriter(w)
+	b := make([]byte, 0, 256)
+	for _, e := range entries {
+		b = append(b[:0], influxMeasurement...)
+		// A tag can not have an empty value.
+		if level := e.levelToken(); level != "" {
+			b = append(b, ",level="...)
+			b = append(b, influxTagEscaper.Replace(level)...)
+		}
+		if e.source != "" {
+			b = append(b, ",source="...)
+			b = append(b, influxTagEscaper.Replace(e.source)...)
+		}
+		b = append(b, ` message="`...)
+		b = append(b, influxStringEscaper.Replace(e.message)...)
+		b = append(b, "\" "...)
+		b = strconv.AppendInt(b, e.time.UnixNano(), 10)
+		b = append(b, '\n')
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteInfluxDB(t *testing.T) {
+	at := time.Date(2024, 1, 15, 9, 54, 56, 0, time.UTC)
+	var b strings.Builder
+	err := WriteInfluxDB(&b, []LogEntry{
+		{time: at, level: LevelError, rawLevel: "ERROR", message: "Failed to connect"},
+		{time: at.Add(time.Nanosecond), rawLevel: "a,b=c d", source: "api 1", message: `say "hi" from C:\tmp`},
+		{time: at, message: "no level"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `log_entry,level=ERROR message="Failed to connect" 1705312496000000000
+log_entry,level=a\,b\=c\ d,source=api\ 1 message="say \"hi\" from C:\\tmp" 1705312496000000001
+log_entry message="no level" 1705312496000000000
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
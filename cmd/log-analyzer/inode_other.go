@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "io/fs"
+
+// fileInode return zero as inodes are not available on this platform,
+// rotation is then only detected by the file shrinking.
+func fileInode(fi fs.FileInfo) uint64 {
+	return 0
+}
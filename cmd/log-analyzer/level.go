@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of an entry, ordered from the least severe
+// LevelDebug to the most severe LevelError. The levels which are not
+// recognized, LevelUnknown, and the absence of level, LevelNone, rank
+// below LevelDebug.
+type Level int
+
+const (
+	// LevelUnknown is the level of entries whose level token is not
+	// recognized by ParseLevel, the token being kept as it is.
+	LevelUnknown Level = iota
+	// LevelNone is the level of entries parsed from lines without a level.
+	LevelNone
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = [...]string{
+	LevelUnknown: "unknown",
+	LevelNone:    "none",
+	LevelDebug:   "debug",
+	LevelInfo:    "info",
+	LevelWarn:    "warn",
+	LevelError:   "error",
+}
+
+// String return the lower case name of the level, e.g. "warn".
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// levelAliases map the lower case level tokens to their level.
+var levelAliases = map[string]Level{
+	"none":        LevelNone,
+	"trace":       LevelDebug,
+	"debug":       LevelDebug,
+	"dbg":         LevelDebug,
+	"verbose":     LevelDebug,
+	"info":        LevelInfo,
+	"inf":         LevelInfo,
+	"information": LevelInfo,
+	"notice":      LevelInfo,
+	"warn":        LevelWarn,
+	"wrn":         LevelWarn,
+	"warning":     LevelWarn,
+	"error":       LevelError,
+	"err":         LevelError,
+	"fatal":       LevelError,
+	"critical":    LevelError,
+	"crit":        LevelError,
+	"alert":       LevelError,
+	"emerg":       LevelError,
+	"emergency":   LevelError,
+	"panic":       LevelError,
+}
+
+// upperLevelAliases map the upper case level tokens, the most common in
+// logs, to their level, sparing ParseLevel lowering them.
+var upperLevelAliases = func() map[string]Level {
+	m := make(map[string]Level, len(levelAliases))
+	for token, l := range levelAliases {
+		m[strings.ToUpper(token)] = l
+	}
+	return m
+}()
+
+// fatalLevels are the level tokens ranking above the errors in
+// LevelSeverity, although they are analyzed as LevelError.
+var fatalLevels = map[string]bool{
+	"fatal":     true,
+	"panic":     true,
+	"critical":  true,
+	"crit":      true,
+	"alert":     true,
+	"emerg":     true,
+	"emergency": true,
+}
+
+// LevelSeverity return the severity of a level token as an integer
+// comparable across tokens: 0 for debug, 1 for info, 2 for warn, 3 for
+// error and 4 for fatal, their aliases included, or -1 for the unknown
+// levels and the absence of level.
+func LevelSeverity(level string) int {
+	if fatalLevels[strings.ToLower(strings.Trim(level, levelDecorations))] {
+		return 4
+	}
+	l, _ := ParseLevel(level)
+	switch l {
+	case LevelDebug:
+		return 0
+	case LevelInfo:
+		return 1
+	case LevelWarn:
+		return 2
+	case LevelError:
+		return 3
+	}
+	return -1
+}
+
+// severityNames are the names of the severities ranked by LevelSeverity.
+var severityNames = [...]string{"debug", "info", "warn", "error", "fatal"}
+
+// ParseSeverity return the severity of a level token as LevelSeverity
+// does, or an error if the level is not recognized.
+func ParseSeverity(level string) (int, error) {
+	s := LevelSeverity(level)
+	if s < 0 {
+		return s, fmt.Errorf("unknown level %q", level)
+	}
+	return s, nil
+}
+
+// levelDecorations are the characters decorating level
+// tokens, e.g. "[ERROR]", "<warn>" or "INFO:".
+const levelDecorations = "[]<>(){}:|\"'"
+
+// ParseLevel return the level of a level token, compared case insensitively
+// with its decorations trimmed and accepting the usual aliases, so "[ERROR]",
+// "Error:" and "err" are all LevelError and "Warning" is LevelWarn. An
+// unrecognized token returns LevelUnknown with an error.
+func ParseLevel(s string) (Level, error) {
+	token := strings.Trim(s, levelDecorations)
+	if l, ok := levelAliases[token]; ok {
+		return l, nil
+	}
+	if l, ok := upperLevelAliases[token]; ok {
+		return l, nil
+	}
+	if l, ok := levelAliases[strings.ToLower(token)]; ok {
+		return l, nil
+	}
+	return LevelUnknown, fmt.Errorf("unknown level %q", s)
+}
+
+// severity return the severity of the entry as LevelSeverity of its level
+// token, without parsing the token again but for the errors, which may be
+// fatal.
+func (e LogEntry) severity() int {
+	switch e.level {
+	case LevelDebug:
+		return 0
+	case LevelInfo:
+		return 1
+	case LevelWarn:
+		return 2
+	case LevelError:
+		token := strings.Trim(e.rawLevel, levelDecorations)
+		for fatal := range fatalLevels {
+			if strings.EqualFold(token, fatal) {
+				return 4
+			}
+		}
+		return 3
+	}
+	return -1
+}
+
+// levelToken return the level token of the entry as found in the log,
+// or the name of its level if it was not parsed from a token.
+func (e LogEntry) levelToken() string {
+	if e.rawLevel != "" || e.level == LevelUnknown {
+		return e.rawLevel
+	}
+	return e.level.String()
+}
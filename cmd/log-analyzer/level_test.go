@@ -0,0 +1,133 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, tt := range []struct {
+		token string
+		want  Level
+	}{
+		{"error", LevelError},
+		{"ERROR", LevelError},
+		{"Error", LevelError},
+		{"[ERROR]", LevelError},
+		{"<error>", LevelError},
+		{"ERROR:", LevelError},
+		{"[ERROR", LevelError},
+		{"(Err)", LevelError},
+		{"|FATAL|", LevelError},
+		{"critical", LevelError},
+		{"CRIT", LevelError},
+		{"emerg", LevelError},
+		{"alert", LevelError},
+		{"panic", LevelError},
+		{"warn", LevelWarn},
+		{"WARNING", LevelWarn},
+		{"[wrn]", LevelWarn},
+		{"info", LevelInfo},
+		{"INF", LevelInfo},
+		{"Information", LevelInfo},
+		{"notice", LevelInfo},
+		{`"info"`, LevelInfo},
+		{"debug", LevelDebug},
+		{"DBG", LevelDebug},
+		{"trace", LevelDebug},
+		{"{verbose}", LevelDebug},
+		{"none", LevelNone},
+		{"NONE", LevelNone},
+	} {
+		got, err := ParseLevel(tt.token)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %s, %v, want %s", tt.token, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseLevelUnknown(t *testing.T) {
+	for _, token := range []string{"", "[]", "bogus", "errors", "in fo", "a,b=c d"} {
+		if got, err := ParseLevel(token); err == nil || got != LevelUnknown {
+			t.Errorf("ParseLevel(%q) = %s, %v, want an error", token, got, err)
+		}
+	}
+}
+
+func TestParseLevelString(t *testing.T) {
+	for _, l := range []Level{LevelNone, LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		if got, err := ParseLevel(l.String()); err != nil || got != l {
+			t.Errorf("ParseLevel(%q) = %s, %v, want %s", l.String(), got, err, l)
+		}
+	}
+	if got := LevelUnknown.String(); got != "unknown" {
+		t.Errorf("got %q, want unknown", got)
+	}
+	if got := Level(42).String(); got != "Level(42)" {
+		t.Errorf("got %q, want Level(42)", got)
+	}
+}
+
+func TestLevelOrder(t *testing.T) {
+	levels := []Level{LevelError, LevelDebug, LevelNone, LevelWarn, LevelUnknown, LevelInfo}
+	slices.Sort(levels)
+	want := []Level{LevelUnknown, LevelNone, LevelDebug, LevelInfo, LevelWarn, LevelError}
+	if !slices.Equal(levels, want) {
+		t.Errorf("got %v, want %v", levels, want)
+	}
+}
+
+// The level token of the lines is kept, unknown levels included.
+func TestLevelToken(t *testing.T) {
+	for line, want := range map[string]struct {
+		level Level
+		token string
+	}{
+		"2024-01-15 10:30:00 [WARNING] Disk almost full": {LevelWarn, "[WARNING]"},
+		"2024-01-15 10:30:00 ERROR Failed to connect":    {LevelError, "ERROR"},
+		"2024-01-15 10:30:00 NOTICE Started":             {LevelInfo, "NOTICE"},
+		"2024-01-15 10:30:00 AUDIT User logged in":       {LevelUnknown, "AUDIT"},
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.level != want.level || entry.levelToken() != want.token {
+			t.Errorf("%q: got %s %q, want %s %q", line, entry.level, entry.levelToken(), want.level, want.token)
+		}
+	}
+	if got := (LogEntry{level: LevelNone}).levelToken(); got != "none" {
+		t.Errorf("got the level token %q of an entry without level, want none", got)
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	f := LevelFilter{Levels: []string{"warning", "audit"}}
+	for token, skipped := range map[string]bool{"WARN": false, "[wrn]": false, "AUDIT": false, "ERROR": true, "other": true} {
+		level, _ := ParseLevel(token)
+		if got := f.Skip(LogEntry{level: level, rawLevel: token}); got != skipped {
+			t.Errorf("%s: got skipped %v, want %v", token, got, skipped)
+		}
+	}
+}
+
+func TestLevelSeverity(t *testing.T) {
+	for level, want := range map[string]int{
+		"debug":    0,
+		"TRACE":    0,
+		"info":     1,
+		"warn":     2,
+		"Warning":  2,
+		"[ERROR]":  3,
+		"err":      3,
+		"FATAL":    4,
+		"panic":    4,
+		"none":     -1,
+		"":         -1,
+		"verbose?": -1,
+	} {
+		if got := LevelSeverity(level); got != want {
+			t.Errorf("LevelSeverity(%q) = %d, want %d", level, got, want)
+		}
+	}
+}
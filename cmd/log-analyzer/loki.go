@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lokiPushPath is the path of the Loki push API.
+const lokiPushPath = "/loki/api/v1/push"
+
+// lokiJob is the job label of the streams pushed without labels,
+// as Loki rejects streams without any label.
+const lokiJob = "log-analyzer"
+
+// lokiLabelName is the pattern of the Loki label names.
+var lokiLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// lokiStream is a stream of the Loki push API, its values being
+// the pairs of the timestamp in nanoseconds and the log line.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPush struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// ParseLokiLabels parse a comma separated list of name=value
+// labels, e.g. "job=app,env=prod".
+func ParseLokiLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if s == "" {
+		return labels, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !lokiLabelName.MatchString(name) {
+			return nil, fmt.Errorf("invalid loki label %q: expected 'name=value'", pair)
+		}
+		labels[name] = value
+	}
+	return labels, nil
+}
+
+// PushToLoki push the entries to the Loki server at url, its base url or
+// the push API url, in batches of -loki-batch-size entries. The entries of
+// each level are a stream with the level label along with the labels, or
+// the job label log-analyzer if there are none. The line of an entry is its
+// message prefixed by its source, if any.
+func PushToLoki(ctx context.Context, url string, entries []LogEntry, labels map[string]string) error {
+	if !strings.HasSuffix(url, lokiPushPath) {
+		url = strings.TrimSuffix(url, "/") + lokiPushPath
+	}
+	if len(labels) == 0 {
+		labels = map[string]string{"job": lokiJob}
+	}
+	size := max(*lokiBatch, 1)
+	for len(entries) > 0 {
+		n := min(size, len(entries))
+		if err := pushLokiBatch(ctx, url, entries[:n], labels); err != nil {
+			return err
+		}
+		entries = entries[n:]
+	}
+	return nil
+}
+
+// pushLokiBatch push the entries to the Loki push API url in a single request.
+func pushLokiBatch(ctx context.Context, url string, entries []LogEntry, labels map[string]string) error {
+	var push lokiPush
+	streams := make(map[string]*lokiStream)
+	for _, e := range entries {
+		level := e.level.String()
+		if e.level == LevelUnknown {
+			level = strings.ToLower(e.rawLevel)
+		}
+		s, ok := streams[level]
+		if !ok {
+			s = &lokiStream{Stream: make(map[string]string, len(labels)+1)}
+			for k, v := range labels {
+				s.Stream[k] = v
+			}
+			if level != "" && e.level != LevelNone {
+				s.Stream["level"] = level
+			}
+			streams[level] = s
+			push.Streams = append(push.Streams, s)
+		}
+		line := e.message
+		if e.source != "" {
+			line = "[" + e.source + "] " + line
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(e.time.UnixNano(), 10), line})
+	}
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("loki %s: unexpected status %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestPushToLoki(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		pushes []lokiPush
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != lokiPushPath || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got a request to %s of %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var push lokiPush
+		if err := json.Unmarshal(body, &push); err != nil {
+			t.Errorf("invalid push %q: %v", body, err)
+		}
+		mu.Lock()
+		pushes = append(pushes, push)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	old := *lokiBatch
+	*lokiBatch = 7
+	t.Cleanup(func() { *lokiBatch = old })
+	entries := generateEntries(50, 0)
+	labels := map[string]string{"job": "app", "env": "prod"}
+	if err := PushToLoki(context.Background(), srv.URL+"/", entries, labels); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pushes) != 8 {
+		t.Errorf("got %d requests, want 8 batches of at most 7 entries", len(pushes))
+	}
+	var values int
+	for _, push := range pushes {
+		for _, s := range push.Streams {
+			values += len(s.Values)
+			want := map[string]string{"job": "app", "env": "prod", "level": s.Stream["level"]}
+			if !reflect.DeepEqual(s.Stream, want) || s.Stream["level"] == "" {
+				t.Errorf("got the stream labels %v, want the labels and the level", s.Stream)
+			}
+		}
+	}
+	if values != len(entries) {
+		t.Errorf("got %d log values, want the %d entries", values, len(entries))
+	}
+	first := pushes[0].Streams[0].Values[0]
+	if want := [2]string{strconv.FormatInt(entries[0].time.UnixNano(), 10), entries[0].message}; first != want {
+		t.Errorf("got the first value %q, want %q", first, want)
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry out of order", http.StatusBadRequest)
+	})
+	if err := PushToLoki(context.Background(), srv.URL, entries, nil); err == nil {
+		t.Error("got no error for a rejected push")
+	}
+}
+
+func TestParseLokiLabels(t *testing.T) {
+	labels, err := ParseLokiLabels("job=app, env=prod")
+	if err != nil || !reflect.DeepEqual(labels, map[string]string{"job": "app", "env": "prod"}) {
+		t.Errorf("got %v, %v", labels, err)
+	}
+	for _, s := range []string{"job", "1job=app", "job=app,,", "my-job=app"} {
+		if _, err := ParseLokiLabels(s); err == nil {
+			t.Errorf("ParseLokiLabels(%q): got no error", s)
+		}
+	}
+}
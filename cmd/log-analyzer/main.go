@@ -1,137 +1,1142 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"os"
-	"sort"
+	"os/signal"
+	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
 var (
-	level = flag.String("level", "info", "comma separated list of log level to analyze. e.g: 'info,warn,error'")
-	start = flag.String("start", "", "start time filter. eg. '2021-01-01T00:00:00'")
-	end   = flag.String("end", "", "end time filter. eg. '2021-01-01T23:59:59'")
-)
+	level       = flag.String("level", "info", "comma separated list of log level to analyze. e.g: 'info,warn,error'")
+	minSeverity = flag.String("min-severity", "", "only analyze entries at least as severe as the level, instead of the -level list. e.g: 'warn' for warn, error and fatal")
+	maxSeverity = flag.String("max-severity", "", "only analyze entries at most as severe as the level, instead of the -level list. e.g: 'info' for debug and info")
+	start       = flag.String("start", "", "start time filter. eg. '2021-01-01T00:00:00'")
+	end         = flag.String("end", "", "end time filter. eg. '2021-01-01T23:59:59'")
 
-var (
-	levels    = make(map[string]struct{}, 4)
-	startTime time.Time
-	endTime   time.Time
+	format       = flag.String("format", "text", "report output format. one of 'text', 'json', 'table', 'markdown', 'graphite', 'template' with -template-file, 'csv' with -group-by-day or -window-stats, or 'parquet', 'influxdb', 'opentelemetry' and 'gelf' to export the filtered entries, or 'loki' and 'datadog' to send them to -loki-url or Datadog")
+	templateFile = flag.String("template-file", "", "go text/template file rendering the report with -format template, the report being dot. e.g: 'templates/default.txt'")
+	output       = flag.String("output", "", "file or 's3://bucket/key' object to write the report or the exported entries to, stdout by default but required with -format parquet")
+	awsRegion    = flag.String("aws-region", "", "region of the s3:// -output bucket, by default the one of the AWS configuration")
+	s3Endpoint   = flag.String("s3-endpoint", "", "url of an S3 compatible endpoint for the s3:// -output, addressed with path style. e.g: 'http://localhost:9000'")
+	graphitePfx  = flag.String("graphite-prefix", "log_analyzer", "metric namespace of the report with -format graphite")
+	gelfHost     = flag.String("gelf-host", "", "host of the messages with -format gelf (default the host name)")
+	lokiURL      = flag.String("loki-url", "", "url of the Grafana Loki server the entries are pushed to with -format loki. e.g: 'http://localhost:3100'")
+	lokiLabels   = flag.String("loki-labels", "", "comma separated labels of the streams pushed to Loki. e.g: 'job=app,env=prod'")
+	lokiBatch    = flag.Int("loki-batch-size", 100, "number of entries pushed to Loki per request")
+	datadogKey   = flag.String("datadog-api-key", "", "api key of the Datadog Logs API the entries are sent to with -format datadog")
+	datadogURL   = flag.String("datadog-url", datadogIntakeURL, "intake url of the Datadog Logs API, e.g. of another Datadog site")
+	datadogTags  = flag.String("datadog-tags", "", "comma separated tags of the entries sent to Datadog. e.g: 'env:prod,team:api'")
+	httpServer   = flag.String("http-server", "", "analyze the file once and serve the report and its entries over HTTP on the address. e.g: ':8080'")
+	webhook      = flag.String("webhook", "", "post the json report to the url once the analysis is done")
+	webhookKey   = flag.String("webhook-secret", "", "sign the webhook payload with HMAC-SHA256 in the X-Signature-256 header")
+	colorMode    = flag.String("color", ColorAuto, "color the text report: 'auto' on a terminal unless NO_COLOR is set, 'always' or 'never'")
+	humanize     = flag.Bool("humanize", false, "group the digits of the counts of the text report by thousands. e.g: '5,000,000'")
+	printSchema  = flag.Bool("print-schema", false, "print the JSON Schema of the json report and exit")
+	pretty       = flag.Bool("pretty", false, "indent the json report, only meaningful with -format json")
+	summaryLine  = flag.Bool("summary-line", false, "print a one line summary and exit with status 1 if any error entries were found")
+	workers      = flag.Int("workers", runtime.GOMAXPROCS(0), "number of workers parsing log entries concurrently")
+	chunks       = flag.Int("chunks", 0, "split a large uncompressed file into N ranges of lines analyzed concurrently and merged, e.g. the number of cores")
+	follow       = flag.Bool("f", false, "follow the file as it grows and print the report periodically")
+	interval     = flag.Duration("interval", 5*time.Second, "report interval in follow mode")
+	followMode   = flag.String("follow-mode", FollowFull, "in follow mode, print the 'full' report every interval or only the 'delta' since the previous one")
+	reportEvery  = flag.Int("report-every", 0, "in follow mode, print and reset the report every N intervals instead of printing a cumulative report every interval")
+	windowSpec   = flag.String("window-analysis", "", "analyze overlapping time windows. e.g: '2021-01-01T00:00:00,2021-01-01T02:00:00,15m'")
+	windowWidth  = flag.Duration("window-width", 0, "width of each window in window analysis (default step)")
+	topErrors    = flag.Int("top-errors-by-time", 0, "show the time distribution of the N most frequent error messages")
+	bucket       = flag.Duration("bucket", time.Hour, "bucket interval of time distributions")
+	sourceSpec   = flag.String("by-source", "", "analyze the entries of each source apart, identified by 'source' for the app or -source-prefix, a word position N of the message or a structured data field. e.g: '1' for a leading host name")
+	groupByDay   = flag.Bool("group-by-day", false, "summarize the entries per calendar day, as a table or with -format csv")
+	windowStats  = flag.Duration("window-stats", 0, "summarize the entries, errors, warnings and average response time per window of the duration, as a table or with -format csv. e.g: '10m'")
+	silence      = flag.Duration("silence-threshold", 0, "list the periods longer than the duration without any entry. e.g: '1m'")
+	failSilence  = flag.Bool("fail-on-silence", false, "exit with status 3 when any silence is found with -silence-threshold")
+	input        = flag.String("input", "", "input format of the lines: 'rfc5424' for syslog, 'json', 'logfmt', 'no-level' as -no-level, 'delimited' as -delimiter ',', 'auto' to try each of them in turn after 'default', the 'timestamp level message' lines, or a parser registered with RegisterParser")
+	delimiter    = flag.String("delimiter", "", "parse lines of fields separated by the delimiter as 'timestamp level message key=value...', a quoted field may contain it. e.g: ','")
+	noLevel      = flag.Bool("no-level", false, "parse lines without a level token as 'timestamp message', recording them under the 'none' level")
+	rotated      = flag.Bool("rotated", false, "analyze the rotations of the file too, oldest first. e.g: app.log.2.gz, app.log.1 then app.log")
+	statePath    = flag.String("state", "", "state file recording the analyzed offset, each run analyze only the lines appended since the previous one")
+	watch        = flag.Bool("watch", false, "re-run the analysis and print the report whenever the file changes")
+	at           = flag.String("at", "", "only analyze entries around the time, see -around. eg. '2021-01-01T12:00:00'")
+	around       = flag.Duration("around", 5*time.Minute, "radius of the time window centered on -at")
+	match        = flag.String("match", "", "only analyze entries whose message matches the regular expression")
+	dryRun       = flag.Bool("dry-run", false, "print the files which would be analyzed, their estimated number of lines and the filters, without analyzing them")
+	parseOnly    = flag.Bool("parse-only", false, "only parse the lines, printing the number of lines parsed per second, to profile the parser")
+	cpuProfile   = flag.String("cpuprofile", "", "write a pprof CPU profile of the analysis to the file")
+	unparseable  = flag.Bool("include-unparseable", false, "print the rate of the lines which could not be parsed, the Skipped Lines, in the text report even when zero")
+	explain      = flag.Bool("explain", false, "print the reason each skipped entry was filtered out on stderr and a skip reasons table")
+	skipMatching = flag.String("skip-matching", "", "skip raw lines matching the regular expression before parsing them")
+	stripPrefix  = flag.String("strip-prefix", "", "remove the start of the raw lines matching the regular expression before parsing them. e.g: '^\\S+ (stdout|stderr) [FP] ' for the container logs")
+	top          = flag.Int("top", 0, "list the N most frequent messages")
+	pcts         = flag.String("percentiles", "", "comma separated list of response time percentiles. e.g: '50,95,99'")
+	timeline     = flag.Duration("timeline", 0, "count entries per interval of the given duration. e.g: '5m'")
+	includeEmpty = flag.Bool("include-empty", false, "count the frequency of empty messages, which are left out by default")
+	groupRegex   = flag.String("group-regex", "", "count messages by the part captured by the regular expression, its first named or else first group. e.g: '(?P<path>/api/\\S+)'")
+	dedupGlobal  = flag.Bool("deduplicate-global", false, "analyze each distinct message once, suffixed with its number of occurrences")
+	sortByTime   = flag.Bool("sort", false, "sort the entries by time before analyzing them, e.g. when the -rotated files overlap")
+	dedup        = flag.Bool("dedup", false, "remove the entries duplicating the time, level and message of an earlier one, reporting their number on stderr")
+	normalize    = flag.Bool("normalize", false, "group messages differing only by numbers when counting their frequency")
+	dumpPath     = flag.String("dump", "", "write the analyzed entries to the file as a json array of their time, level and message")
+	savePath     = flag.String("save", "", "save the report to the file, to be loaded back for comparison or merging")
+	watchEvery   = flag.Duration("watch-interval", 0, "like -watch but poll the file modification time at the given interval")
+	deltaOnly    = flag.Bool("delta-only", false, "in watch mode, print only what changed since the previous report")
+	maxMessages  = flag.Int("max-unique-messages", 0, "bound memory by tracking at most N distinct messages, making their counts approximate beyond")
+	maxMsgLen    = flag.Int("max-message-len", 200, "truncate the messages to N characters when counting their frequency, 0 keeping them whole")
+	accuracy     = flag.Float64("quantile-accuracy", 0, "estimate response time percentiles within the given relative error, e.g. 0.01, instead of keeping every response time")
+	sampleSize   = flag.Int("sample-size", defaultSampleSize, "with -quantile-accuracy, keep a uniform sample of N response times in the json report response_time_sample_ms")
+	sampleSeed   = flag.Int64("seed", 1, "seed of the response time sampling, the same input and seed giving the same sample")
+	useMmap      = flag.Bool("mmap", false, "experimental: map the file in memory rather than reading it, unless it is compressed, read with -rotated, -state or -read-rate, or can not be mapped")
+	readRate     = flag.Int64("read-rate", 0, "limit reading the file to the given bytes per second, sparing the disk of busy hosts")
+	quiet        = flag.Bool("quiet", false, "do not display progress nor the invalid lines on stderr, only their count")
+	expectError  = flag.Float64("expect-error-pct", -1, "expected percentage of error entries, the observed one being reported on stderr")
+	tolerance    = flag.Float64("expect-tolerance", 1, "percentage points the observed error percentage may deviate from -expect-error-pct by")
+	expectSubstr = flag.Bool("expect-substring", false, "match the -expect messages as substrings of the counted messages rather than exactly")
+	failDeviate  = flag.Bool("fail-on-deviation", false, "exit with status 1 when the error percentage deviates from -expect-error-pct")
+	failFast     = flag.Bool("fail-fast", false, "with several files or a directory, stop analyzing them at the first one failing instead of reporting the others")
+	strict       = flag.Bool("strict", false, "abort the analysis at the first invalid line, exiting with status 4")
+	maxInvalid   = flag.Float64("max-invalid-ratio", 0, "abort the analysis, exiting with status 4, once more than the ratio of lines are invalid. e.g: 0.2")
+	maxEntries   = flag.Int("max-entries", 0, "only analyze the first N entries kept by the filters, reporting the coverage of the lines")
+	maxErrors    = flag.Int("max-errors", 0, "stop reading after N invalid lines, writing the partial report and exiting with status 4")
+	skipHeader   = flag.Bool("skip-header", false, "silently skip the lines before the first valid one, such as a banner at the top of the file")
+	verbose      = flag.Bool("verbose", false, "report every invalid line on stderr rather than the first few of them")
+	_            = flag.String("config", configFile, "config file setting the default of any flag, one 'flag: value' per line")
+	sourcePrefix = flag.Bool("source-prefix", false, "extract a bracketed source prefix from messages. e.g: '[api] Request processed'")
 )
 
+// lineParser parse the lines in the input format selected by the flags,
+// applying the enabled message extractions and skipping the lines
+// matching -skip-matching.
+var lineParser LineParser
+
+// bySource is the key of the source of the entries with -by-source.
+var bySource FrequencyKey
+
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("log-analyzer: ")
 	flag.Usage = Usage
+	if err := loadConfig(os.Args[1:]); err != nil {
+		log.Fatalln(err)
+	}
+	if len(os.Args) > 1 {
+		if cmd := lookupCommand(os.Args[1]); cmd != nil {
+			cmd.run(os.Args[2:])
+			return
+		}
+	}
+
+	// Without a subcommand the flags precede the optional
+	// validate or serve subcommand, as in earlier versions.
 	flag.Parse()
+	if *printSchema {
+		if err := WriteSchema(os.Stdout); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	switch flag.Arg(0) {
+	case "validate":
+		validate(flag.Args()[1:])
+		return
+	case "serve":
+		serve(flag.Args()[1:])
+		return
+	}
+	runAnalysis(flag.Args())
+}
 
-	var file string
-	if flag.NArg() > 0 {
-		file = flag.Arg(0)
+// setup validate the parsing and output flags and return the
+// filters and the analysis options selected by the flags.
+func setup() ([]NamedFilter, []Option) {
+	if *skipMatching != "" {
+		re, err := regexp.Compile(*skipMatching)
+		if err != nil {
+			log.Fatalln("invalid skip pattern: ", err)
+		}
+		lineParser.Skip = re
 	}
-	if file == "" {
-		log.Fatalln("arg: file name is required")
-	} else if !isLogFile(file) {
-		log.Fatalf("arg: %s is not a log file", file)
+	if *stripPrefix != "" {
+		re, err := regexp.Compile(*stripPrefix)
+		if err != nil {
+			log.Fatalln("invalid strip prefix: ", err)
+		}
+		lineParser.StripPrefix = re
 	}
 
-	for _, l := range strings.Split(*level, ",") {
-		levels[l] = struct{}{}
+	switch *format {
+	case FormatText, FormatJSON, FormatTable, FormatMarkdown, FormatGraphite:
+	case FormatTemplate:
+		if *templateFile == "" {
+			log.Fatalln("-format template requires -template-file")
+		}
+		if _, err := ParseTemplate(*templateFile); err != nil {
+			log.Fatalln(err)
+		}
+	case FormatCSV:
+		if !*groupByDay && *windowStats <= 0 {
+			log.Fatalln("-format csv requires -group-by-day or -window-stats")
+		}
+	case FormatParquet, FormatInfluxDB, FormatOTel, FormatGELF, FormatLoki, FormatDatadog:
+		if *format == FormatParquet && *output == "" {
+			log.Fatalln("-format parquet requires -output")
+		}
+		if *format == FormatLoki {
+			if *lokiURL == "" {
+				log.Fatalln("-format loki requires -loki-url")
+			}
+			if _, err := ParseLokiLabels(*lokiLabels); err != nil {
+				log.Fatalln(err)
+			}
+			if *lokiBatch < 1 {
+				log.Fatalf("invalid loki batch size %d: must be at least 1", *lokiBatch)
+			}
+		}
+		if *format == FormatDatadog && *datadogKey == "" {
+			log.Fatalln("-format datadog requires -datadog-api-key")
+		}
+		if *follow || *watch || *watchEvery > 0 || *httpServer != "" {
+			log.Fatalf("-format %s can not be used with -f, -watch or -http-server", *format)
+		}
+	default:
+		log.Fatalf("invalid format: %s", *format)
 	}
 
+	if *output != "" && (*follow || *watch || *watchEvery > 0 || *httpServer != "") {
+		log.Fatalln("-output can not be used with -f, -watch or -http-server")
+	}
+	if isS3URI(*output) {
+		if _, _, err := parseS3URI(*output); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	// -no-level and -delimiter are the no-level and delimited inputs.
+	in := *input
+	if *noLevel {
+		if in != "" {
+			log.Fatalf("-no-level can not be used with -input %s", in)
+		}
+		in = InputNoLevel
+	}
+	if *delimiter != "" {
+		if in != "" && in != InputDelimited {
+			log.Fatalln("-delimiter can not be used with -input or -no-level")
+		}
+		if strings.Contains(*delimiter, `"`) {
+			log.Fatalf("invalid delimiter %q: must not contain a double quote", *delimiter)
+		}
+		in = InputDelimited
+	}
+	switch {
+	case in == "":
+	case in == InputAuto:
+		lineParser.Parser = RegistryParser{}
+	case in == InputDelimited && *delimiter != "":
+		lineParser.Parser = DelimitedParser{Delimiter: *delimiter}
+	default:
+		p, ok := LookupParser(in)
+		if !ok {
+			log.Fatalf("invalid input format: %s", in)
+		}
+		lineParser.Parser = p
+	}
+	lineParser.SourcePrefix = *sourcePrefix
+
+	switch *followMode {
+	case FollowFull, FollowDelta:
+	default:
+		log.Fatalf("invalid follow mode %q: must be '%s' or '%s'", *followMode, FollowFull, FollowDelta)
+	}
+
+	switch {
+	case *verbose && *quiet:
+		log.Fatalln("-verbose can not be used with -quiet")
+	case *verbose:
+		invalidLines.SetVerbosity(VerbosityVerbose)
+	case *quiet:
+		invalidLines.SetVerbosity(VerbosityQuiet)
+	}
+
+	switch *colorMode {
+	case ColorAuto, ColorAlways, ColorNever:
+	default:
+		log.Fatalf("invalid color %q: must be one of 'auto', 'always' or 'never'", *colorMode)
+	}
+
+	if *dedupGlobal && (*follow || *watch || *watchEvery > 0) {
+		log.Fatalln("-deduplicate-global can not be used with -f or -watch")
+	}
+	if (*sortByTime || *dedup || *dumpPath != "") && (*follow || *watch || *watchEvery > 0) {
+		log.Fatalln("-sort, -dedup and -dump can not be used with -f or -watch")
+	}
+
+	if *silence < 0 {
+		log.Fatalf("invalid silence threshold %s: must not be negative", *silence)
+	}
+	if *failSilence && *silence == 0 {
+		log.Fatalln("-fail-on-silence requires -silence-threshold")
+	}
+	if *silence > 0 && (*follow || *watch || *watchEvery > 0) {
+		log.Fatalln("-silence-threshold can not be used with -f or -watch")
+	}
+	if *groupByDay && (*follow || *watch || *watchEvery > 0) {
+		log.Fatalln("-group-by-day can not be used with -f or -watch")
+	}
+	if *windowStats < 0 {
+		log.Fatalln("invalid -window-stats: the duration must be positive")
+	}
+	if *windowStats > 0 && (*groupByDay || *follow || *watch || *watchEvery > 0) {
+		log.Fatalln("-window-stats can not be used with -group-by-day, -f or -watch")
+	}
+	if *sourceSpec != "" {
+		if *follow || *watch || *watchEvery > 0 || *httpServer != "" || isEntryFormat(*format) {
+			log.Fatalln("-by-source can not be used with -f, -watch, -http-server nor to export the entries")
+		}
+		key, err := SourceKey(*sourceSpec)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		bySource = key
+	}
+
+	if *expectError > 100 || *tolerance < 0 {
+		log.Fatalln("invalid expectation: -expect-error-pct must be within [0, 100] and -expect-tolerance not negative")
+	}
+
+	if *readRate < 0 {
+		log.Fatalf("invalid read rate %d: must not be negative", *readRate)
+	}
+
+	// The filters are named after their flag in the -explain output.
+	var filter []NamedFilter
+	addFilter := func(name string, f ExplainableFilter) {
+		if *explain {
+			filter = append(filter, ExplainTo(os.Stderr, name, f))
+		} else {
+			filter = append(filter, NamedFilter{Name: name, Description: describe(f), Filter: f.Skip})
+		}
+	}
+	if *minSeverity != "" || *maxSeverity != "" {
+		if in == InputNoLevel {
+			log.Fatalln("-min-severity and -max-severity can not be used with -no-level")
+		}
+		f, err := NewSeverityFilter(*minSeverity, *maxSeverity)
+		if err != nil {
+			log.Fatalln("invalid severity: ", err)
+		}
+		addFilter("severity", f)
+	} else if in != InputNoLevel {
+		addFilter("level", NewLevelFilter(strings.Split(*level, ",")...))
+	}
+	var timeRange TimeRangeFilter
 	if *start != "" {
-		t, err := time.Parse(time.DateTime, *start)
+		t, err := parseTime(*start)
 		if err != nil {
 			log.Fatalln("invalid start time: ", err)
 		}
-		startTime = t
+		timeRange.Start = t
 	}
 	if *end != "" {
-		t, err := time.Parse(time.DateTime, *end)
+		t, err := parseTime(*end)
 		if err != nil {
 			log.Fatalln("invalid end time: ", err)
 		}
-		endTime = t
+		timeRange.End = t
+	}
+	if !timeRange.Start.IsZero() || !timeRange.End.IsZero() {
+		addFilter("start/end", timeRange)
+	}
+	if *at != "" {
+		t, err := parseTime(*at)
+		if err != nil {
+			log.Fatalln("invalid at time: ", err)
+		}
+		if *around < 0 {
+			log.Fatalf("invalid around %s: must not be negative", *around)
+		}
+		addFilter("at", Around(t, *around))
+	}
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			log.Fatalln("invalid match pattern: ", err)
+		}
+		addFilter("match", MessagePatternFilter{Pattern: re})
 	}
 
-	filter := []FilterFunc{
-		func(entry LogEntry) bool {
-			_, ok := levels[strings.ToLower(entry.level)]
-			return !ok
-		},
-		func(entry LogEntry) bool {
-			if !startTime.IsZero() && entry.time.Before(startTime) {
-				return true
-			}
-			return false
-		},
-		func(entry LogEntry) bool {
-			if !endTime.IsZero() && entry.time.After(endTime) {
-				return true
+	opts := []Option{WithLineParser(lineParser), WithNamedFilters(filter...), WithWorkers(*workers), WithNormalization(*normalize), WithEmptyMessages(*includeEmpty), WithStrict(*strict)}
+	if *top != 0 {
+		opts = append(opts, WithTopN(*top))
+	}
+	if *pcts != "" {
+		var ps []float64
+		for _, p := range strings.Split(*pcts, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				log.Fatalln("invalid percentile: ", err)
 			}
-			return false
-		},
+			ps = append(ps, v)
+		}
+		opts = append(opts, WithPercentiles(ps...))
+	}
+	if *timeline != 0 {
+		opts = append(opts, WithInterval(*timeline))
+	}
+	if *groupRegex != "" {
+		re, err := regexp.Compile(*groupRegex)
+		if err != nil {
+			log.Fatalln("invalid group regex: ", err)
+		}
+		opts = append(opts, WithGroupRegex(re))
+	}
+	if *maxMessages != 0 {
+		opts = append(opts, WithMaxUniqueMessages(*maxMessages))
+	}
+	opts = append(opts, WithMaxMessageLen(*maxMsgLen))
+	if *accuracy != 0 {
+		opts = append(opts, WithQuantileAccuracy(*accuracy))
+	}
+	opts = append(opts, WithResponseSample(*sampleSize, *sampleSeed))
+	if *maxInvalid != 0 {
+		opts = append(opts, WithMaxInvalidRatio(*maxInvalid))
+	}
+	if *maxErrors != 0 {
+		opts = append(opts, WithMaxErrors(*maxErrors))
+	}
+	if *maxEntries != 0 {
+		if *follow || *watch || *watchEvery > 0 {
+			log.Fatalln("-max-entries can not be used with -f or -watch")
+		}
+		opts = append(opts, WithMaxEntries(*maxEntries))
+	}
+	if *skipHeader {
+		opts = append(opts, WithSkipHeader(true))
+	}
+	if *explain {
+		opts = append(opts, WithSkipReasons(true))
+	}
+	if *unparseable {
+		opts = append(opts, WithUnparseableRate(true))
+	}
+	if *humanize {
+		opts = append(opts, WithHumanize(true))
+	}
+	if len(metricExtractors) > 0 {
+		opts = append(opts, WithExtractors(metricExtractors...))
+	}
+	if _, err := newOptions(opts...); err != nil {
+		log.Fatalln(err)
+	}
+	return filter, opts
+
+}
+
+// runAnalysis analyze the file named by the first of args
+// as selected by the flags and write the report.
+func runAnalysis(args []string) {
+	filter, opts := setup()
+	if *cpuProfile != "" {
+		stop, err := startCPUProfile(*cpuProfile)
+		if err != nil {
+			log.Fatalln("failed to start cpu profile: ", err)
+		}
+		defer stop()
+	}
+
+	var file string
+	if len(args) > 0 {
+		file = args[0]
+	}
+	if len(args) > 1 {
+		runFiles(args, opts)
+		return
+	}
+	// A directory named as a log file fails to be read as one below.
+	if fi, err := os.Stat(file); err == nil && fi.IsDir() && !isLogFile(file) {
+		files, err := logFilesIn(file)
+		if err != nil {
+			log.Fatalln("failed to list log files: ", err)
+		}
+		if len(files) == 0 {
+			log.Fatalf("arg: %s has no log files", file)
+		}
+		runFiles(files, opts)
+		return
+	}
+	if file == "" {
+		log.Fatalln("arg: file name is required")
+	} else if !isLogFile(file) {
+		log.Fatalf("arg: %s is not a log file", file)
+	}
+	if *dryRun {
+		if err := DryRun(os.Stdout, file, filter); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	if *chunks > 0 && (*follow || *watch || *watchEvery > 0 || *statePath != "" || *rotated || *httpServer != "" ||
+		*windowSpec != "" || *topErrors > 0 || *groupByDay || *windowStats > 0 || *silence > 0 || bySource != nil || isEntryFormat(*format) ||
+		*dedupGlobal || *sortByTime || *dedup || *dumpPath != "" || *parseOnly || *useMmap || *readRate > 0 ||
+		*maxEntries > 0 || *maxErrors > 0) {
+		log.Fatalln("-chunks only analyze a whole file into a single report, not followed, watched, exported, limited or otherwise analyzed")
 	}
 
 	f, err := os.OpenFile(file, os.O_RDONLY, 0644)
 	if err != nil {
 		log.Fatalln("failed to open file: ", err)
 	}
+	defer f.Close()
 
-	logs := ReadFile(f)
-	if len(logs) == 0 {
-		log.Fatalln("no log entries found")
+	var (
+		in         io.Reader = f
+		checkpoint *Checkpoint
+		progress   *Progress
+	)
+	// fatal release the state lock before exiting,
+	// the next run finding it locked otherwise.
+	fatal := func(v ...any) {
+		checkpoint.Release()
+		log.Fatalln(v...)
 	}
+	if *statePath != "" {
+		// With -max-entries the lines past the first entries would be read
+		// without being analyzed, and never analyzed by the next runs.
+		if *follow || *watch || *watchEvery > 0 || *maxEntries > 0 {
+			log.Fatalln("-state can not be used with -f, -watch or -max-entries")
+		}
+		checkpoint, in, err = OpenCheckpoint(*statePath, f)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	var rotations *rotatedReader
+	if *rotated {
+		if *statePath != "" || *follow || *watch || *watchEvery > 0 {
+			fatal("-rotated can not be used with -state, -f or -watch")
+		}
+		files, err := Rotations(file)
+		if err != nil {
+			fatal("failed to list rotated files: ", err)
+		}
+		rotations = &rotatedReader{files: files}
+		defer rotations.Close()
+		in = rotations
+	}
+
+	in = throttle(in)
 
-	report := Analyze(logs, filter...)
-	report.Print()
+	// The mapped file is read in place, without progress nor the
+	// offset of read errors which would fault rather than fail.
+	var mapped bool
+	if *useMmap {
+		if *follow || *watch || *watchEvery > 0 {
+			fatal("-mmap can not be used with -f or -watch")
+		}
+		if in == io.Reader(f) {
+			if m, ok := mapFile(f); ok {
+				defer m.Close()
+				in, mapped = m, true
+			}
+		}
+	}
+
+	// Display progress only when reading an entire file on a terminal.
+	if !mapped && *chunks == 0 && !*quiet && !*follow && !*watch && *watchEvery <= 0 && isTerminal(os.Stderr) {
+		var size int64
+		if rotations != nil {
+			// The compressed rotations are counted as read from
+			// their files, their size not being the size decompressed.
+			for _, name := range rotations.files {
+				if fi, err := os.Stat(name); err == nil {
+					size += fi.Size()
+				}
+			}
+		} else if sr, ok := in.(*io.SectionReader); ok {
+			size = sr.Size()
+		} else if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+			size = fi.Size()
+		}
+		progress = NewProgress(os.Stderr, size)
+		if rotations != nil {
+			rotations.wrap = progress.ByteReader
+			in = progress.LineReader(in)
+		} else {
+			in = progress.Reader(in)
+		}
+		progress.Start()
+	}
+
+	// A failing read is reported with the file name and offset,
+	// the rotations being several files.
+	if !*rotated && !mapped {
+		in = newFileReader(file, in, checkpoint.Start())
+	}
+
+	if *parseOnly {
+		if *follow || *watch || *watchEvery > 0 {
+			fatal("-parse-only can not be used with -f or -watch")
+		}
+		stats, err := ParseOnly(in, lineParser)
+		progress.Stop()
+		checkpoint.Release()
+		if err != nil {
+			fatal(err)
+		}
+		if err := stats.Fprint(os.Stdout); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	// readAll read the entries of the whole input for the
+	// analyses which are not done as the entries are read.
+	// Once stopped by -max-errors the partial result is written, the
+	// state is not saved and finish exit with a non-zero status.
+	o, _ := newOptions(opts...)
+	var (
+		stopped *StoppedError
+		lines   int
+	)
+	readAll := func() []LogEntry {
+		check := o.newCheck()
+		entries, err := readEntries(in, lineParser, check)
+		lines = check.total
+		if errors.As(err, &stopped) {
+			progress.Stop()
+			log.Println(stopped)
+			checkpoint.Release()
+			checkpoint = nil
+		} else if errors.As(err, new(*InvalidInputError)) {
+			progress.Stop()
+			checkpoint.Release()
+			exitInvalidInput(err)
+		} else if err != nil {
+			progress.Stop()
+			fatal(err)
+		}
+		entries = filterEntries(entries, filter)
+		if *maxEntries > 0 && len(entries) > *maxEntries {
+			entries = entries[:*maxEntries]
+		}
+		if *sortByTime {
+			SortEntries(entries, SortByTime)
+		}
+		if *dedup {
+			n := len(entries)
+			entries = DedupEntries(entries)
+			log.Printf("removed %d duplicate entries", n-len(entries))
+		}
+		if *dumpPath != "" {
+			if err := dumpEntries(*dumpPath, entries); err != nil {
+				fatal("failed to dump entries: ", err)
+			}
+		}
+		if *dedupGlobal {
+			entries = DeduplicateGlobal(entries)
+		}
+		return entries
+	}
+	// commit stop the progress, flush the invalid lines and save the
+	// state once the entries read by readAll are analyzed.
+	commit := func() {
+		progress.Stop()
+		invalidLines.Flush()
+		if err := checkpoint.Commit(); err != nil {
+			fatal("failed to save state: ", err)
+		}
+	}
+	// finish commit and write the result of the analysis with print,
+	// exiting with a non-zero status once stopped by -max-errors.
+	finish := func(print func() error) {
+		commit()
+		if err := print(); err != nil {
+			fatal("failed to write report: ", err)
+		}
+		if stopped != nil {
+			os.Exit(exitInvalidInputStatus)
+		}
+	}
+
+	if *windowSpec != "" {
+		ws, we, step, err := ParseWindowSpec(*windowSpec)
+		if err != nil {
+			fatal(err)
+		}
+		width := *windowWidth
+		if width <= 0 {
+			width = step
+		}
+		reports := SlidingWindowAnalyze(readAll(), ws, we, step, width)
+		finish(func() error { return PrintWindows(os.Stdout, reports, ws, step, width) })
+		return
+	}
+
+	if *topErrors > 0 {
+		timelines := TopErrorsByTime(readAll(), *topErrors, *bucket)
+		finish(func() error { return timelines.Print(os.Stdout) })
+		return
+	}
+
+	if *groupByDay {
+		days := GroupByDay(readAll())
+		finish(func() error {
+			if *format == FormatCSV {
+				return WriteDaysCSV(os.Stdout, days)
+			}
+			return PrintDays(os.Stdout, days)
+		})
+		return
+	}
+
+	if *windowStats > 0 {
+		windows := GroupByWindow(readAll(), *windowStats)
+		finish(func() error {
+			if *format == FormatCSV {
+				return WriteWindowStatsCSV(os.Stdout, windows)
+			}
+			return PrintWindowStats(os.Stdout, windows)
+		})
+		return
+	}
+
+	if bySource != nil {
+		reports, err := AnalyzeBySource(readAll(), bySource, opts...)
+		if err != nil {
+			progress.Stop()
+			fatal(err)
+		}
+		finish(func() error {
+			return writeOutput(func(w io.Writer) error {
+				if *format == FormatJSON {
+					return WriteSourcesJSON(w, reports, *pretty)
+				}
+				return PrintSources(w, reports, writeReportTo)
+			})
+		})
+		return
+	}
+
+	if *silence > 0 {
+		periods := SilenceDetector{Threshold: *silence}.Check(readAll())
+		finish(func() error { return PrintSilences(os.Stdout, periods, *silence) })
+		if len(periods) > 0 && *failSilence {
+			os.Exit(exitSilenceStatus)
+		}
+		return
+	}
+
+	if isEntryFormat(*format) {
+		entries := readAll()
+		finish(func() error { return writeEntries(entries) })
+		return
+	}
+
+	if *httpServer != "" {
+		entries := readAll()
+		commit()
+		report, err := Analyze(entries, opts...)
+		if err != nil {
+			fatal(err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		log.Printf("serving report of %s on %s", file, *httpServer)
+		if err := listenAndServe(ctx, *httpServer, NewEntryServer(report, entries).Handler()); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	// Cancel the analysis on the first interrupt and print the partial
+	// report, a second interrupt kills the process as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if *watch || *watchEvery > 0 {
+		f.Close()
+		watchFile(ctx, file, opts)
+		return
+	}
+
+	if *follow {
+		followFile(ctx, f, opts)
+		return
+	}
+
+	var report *AnalysisReport
+	if *dedupGlobal || *sortByTime || *dedup || *dumpPath != "" {
+		// The entries are already filtered, only added to the report.
+		report = o.newReport()
+		for _, entry := range readAll() {
+			report.Add(entry)
+		}
+		report.TotalLines = lines
+		report.finish()
+		if report.TotalEntries == 0 {
+			err = ErrNoEntries
+		}
+	} else if *chunks > 0 {
+		report, err = AnalyzeChunks(ctx, file, *chunks, opts...)
+	} else {
+		report, err = AnalyzeContext(ctx, in, opts...)
+	}
+	progress.Stop()
+	invalidLines.Flush()
+	var (
+		interrupted *InterruptError
+		stoppedErr  *StoppedError
+	)
+	if err == nil || errors.Is(err, ErrNoEntries) {
+		if err := checkpoint.Commit(); err != nil {
+			fatal("failed to save state: ", err)
+		}
+	} else {
+		checkpoint.Release()
+	}
+	if errors.As(err, new(*InvalidInputError)) {
+		exitInvalidInput(err)
+	}
+	if err != nil && !errors.Is(err, ErrNoEntries) && !errors.As(err, &interrupted) && !errors.As(err, &stoppedErr) {
+		fatal(err)
+	}
+	if *savePath != "" {
+		if err := report.Save(*savePath); err != nil {
+			fatal("failed to save report: ", err)
+		}
+	}
+	if *summaryLine {
+		fmt.Println(report.Summary())
+	} else if report.TotalEntries == 0 && *format == FormatText && *output == "" {
+		// A report of zeros is of no use when nothing matched.
+		fmt.Println("0 entries matched the filters")
+	} else if err := writeReport(report); err != nil {
+		fatal("failed to write report: ", err)
+	}
+	if *webhook != "" {
+		if err := PostReport(*webhook, *webhookKey, report); err != nil {
+			fatal("failed to notify webhook: ", err)
+		}
+	}
+	if interrupted != nil {
+		log.Println(interrupted)
+		os.Exit(130)
+	}
+	if stoppedErr != nil {
+		log.Println(stoppedErr)
+		os.Exit(exitInvalidInputStatus)
+	}
+	if *expectError >= 0 {
+		expect := LevelExpectation{Level: LevelError, Pct: *expectError, Tolerance: *tolerance}
+		observed, ok := expect.Check(report)
+		status := "ok"
+		if !ok {
+			status = "deviating"
+		}
+		log.Printf("%s entries: %.2f%% observed, %.2f%% ± %.2f%% expected: %s", expect.Level, observed, expect.Pct, expect.Tolerance, status)
+		if !ok && *failDeviate {
+			os.Exit(1)
+		}
+	}
+	if !checkMessages(report) {
+		os.Exit(1)
+	}
+	if *summaryLine && report.Error > 0 {
+		os.Exit(1)
+	}
+}
+
+// watchFile analyze the file at path every time it changes with -watch,
+// or every -watch-every, writing the report of each analysis until the
+// context is done.
+func watchFile(ctx context.Context, file string, opts []Option) {
+	var prev *AnalysisReport
+	run := func() error {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		report, err := AnalyzeContext(ctx, throttle(f), opts...)
+		invalidLines.Flush()
+		if errors.As(err, new(*InvalidInputError)) {
+			exitInvalidInput(err)
+		}
+		if errors.As(err, new(*StoppedError)) {
+			log.Println(err)
+			err = nil
+		}
+		if errors.Is(err, ErrNoEntries) || errors.As(err, new(*InterruptError)) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fmt.Printf("\n[%s]\n", time.Now().Format(time.DateTime))
+		defer func() { prev = report }()
+		if prev == nil || !*deltaOnly {
+			if err := writeReport(report); err != nil {
+				return err
+			}
+		}
+		if prev == nil {
+			return nil
+		}
+		fmt.Println("Changes since previous report:")
+		return report.Delta(prev).Print(os.Stdout)
+	}
+	var err error
+	if *watchEvery > 0 {
+		err = Poll(ctx, file, *watchEvery, run)
+	} else {
+		err = Watch(ctx, file, run)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// followFile analyze the lines appended to f with -f, writing the report
+// every -report-every entries and -interval until the context is done.
+func followFile(ctx context.Context, f *os.File, opts []Option) {
+	lines := make(chan string, batchSize)
+	go func() {
+		if err := Tail(ctx, throttle(f), lines); err != nil && !errors.Is(err, ctx.Err()) {
+			log.Println("failed to read file: ", err)
+		}
+	}()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	follower := &Follower{
+		ReportEvery: *reportEvery,
+		Options:     opts,
+		Emit: func(report *AnalysisReport) {
+			if err := writeReport(report); err != nil {
+				log.Fatalln("failed to write report: ", err)
+			}
+		},
+	}
+	if *followMode == FollowDelta {
+		follower.EmitDelta = func(d *ReportDelta) {
+			fmt.Println("Changes since previous report:")
+			if err := d.Print(os.Stdout); err != nil {
+				log.Fatalln("failed to write report: ", err)
+			}
+		}
+	}
+	err := follower.Run(ctx, lines, ticker.C)
+	invalidLines.Flush()
+	if errors.As(err, new(*InvalidInputError)) {
+		exitInvalidInput(err)
+	}
+	if errors.As(err, new(*StoppedError)) {
+		log.Println(err)
+		os.Exit(exitInvalidInputStatus)
+	}
 }
 
+// exitInvalidInputStatus is the exit status of an analysis aborted
+// by -strict or -max-invalid-ratio, or stopped early by -max-errors.
+const exitInvalidInputStatus = 4
+
+// exitSilenceStatus is the exit status of an analysis
+// finding silences with -fail-on-silence.
+const exitSilenceStatus = 3
+
+// exitInvalidInput exit as the analysis was aborted with err,
+// an *InvalidInputError.
+func exitInvalidInput(err error) {
+	invalidLines.Flush()
+	log.Println(err)
+	log.Println("the file is likely not in the expected format, see -input and -no-level")
+	os.Exit(exitInvalidInputStatus)
+}
+
+// writeReport write the report to stdout in the selected format.
+func writeReport(report *AnalysisReport) error {
+	return writeOutput(func(w io.Writer) error {
+		return writeReportTo(w, report)
+	})
+}
+
+// writeOutput call write with the -output file or s3:// object,
+// or stdout if there is none.
+func writeOutput(write func(io.Writer) error) error {
+	if *output == "" {
+		return write(os.Stdout)
+	}
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+	if isS3URI(*output) {
+		return UploadToS3(context.Background(), *output, buf.Bytes())
+	}
+	return os.WriteFile(*output, buf.Bytes(), 0o644)
+}
+
+// writeReportTo write the report to w in the selected format.
+func writeReportTo(w io.Writer, report *AnalysisReport) error {
+	switch *format {
+	case FormatJSON:
+		return report.WriteJSON(w, *pretty)
+	case FormatTable:
+		return report.WriteTable(w)
+	case FormatMarkdown:
+		return report.WriteMarkdown(w)
+	case FormatGraphite:
+		return WriteGraphite(w, report, *graphitePfx)
+	case FormatTemplate:
+		return RenderTemplate(w, *templateFile, report)
+	default:
+		return report.Fprint(w)
+	}
+}
+
+// isEntryFormat report whether the format writes the
+// filtered entries rather than the report.
+func isEntryFormat(format string) bool {
+	switch format {
+	case FormatParquet, FormatInfluxDB, FormatOTel, FormatGELF, FormatLoki, FormatDatadog:
+		return true
+	}
+	return false
+}
+
+// writeEntries write the entries in the selected format to the -output
+// file or s3:// object, or stdout if there is none, or push them to
+// -loki-url or Datadog.
+func writeEntries(entries []LogEntry) error {
+	switch *format {
+	case FormatLoki:
+		labels, _ := ParseLokiLabels(*lokiLabels)
+		return PushToLoki(context.Background(), *lokiURL, entries, labels)
+	case FormatDatadog:
+		host, _ := os.Hostname()
+		client := &DatadogClient{APIKey: *datadogKey, URL: *datadogURL, Tags: *datadogTags, Hostname: host}
+		return client.Send(context.Background(), entries)
+	}
+	var (
+		out io.WriteCloser = os.Stdout
+		buf *bytes.Buffer  // of the s3:// -output
+	)
+	switch {
+	case isS3URI(*output):
+		buf = new(bytes.Buffer)
+		out = nopCloser{buf}
+	case *output != "":
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		out = f
+	}
+	var err error
+	switch *format {
+	case FormatParquet:
+		err = WriteParquet(out, entries)
+	case FormatOTel:
+		err = WriteOTelJSON(out, entries)
+	case FormatGELF:
+		host := *gelfHost
+		if host == "" {
+			host, _ = os.Hostname()
+		}
+		err = WriteGELF(out, entries, host)
+	default:
+		err = WriteInfluxDB(out, entries)
+	}
+	if err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if buf != nil {
+		return UploadToS3(context.Background(), *output, buf.Bytes())
+	}
+	return nil
+}
+
+// nopCloser is a writer whose Close does nothing.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
 func Usage() {
 	fmt.Fprintf(os.Stderr, "Usage of log-analyzer:\n")
 	fmt.Fprintf(os.Stderr, "\tlog-analyzer [-level] [-start,-end 'DD-MM-YYY HH:MM:SS'] filename ... \n")
+	fmt.Fprintf(os.Stderr, "\tlog-analyzer command [OPTION] args ...\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.help)
+	}
 	fmt.Fprintf(os.Stderr, "Flags:\n")
 	flag.PrintDefaults()
 }
 
-type FilterFunc func(LogEntry) bool
-
 // Analyze Analyze logs and return the analysis report.
-// Each log entry will be tested against the provided filter.
-func Analyze(entries []LogEntry, filter ...FilterFunc) *AnalysisReport {
-	report := &AnalysisReport{
-		MsgFrequency: make(map[string]int, 10),
-	}
-	for _, entry := range entries {
-		for _, skip := range filter {
-			if skip(entry) {
-				continue
-			}
-		}
-		report.Add(entry)
-	}
-	return report
+// Each log entry will be tested against the filters given with WithFilters,
+// the other options enable additional statistics in the report.
+// An error is returned if any of the options is invalid.
+// Use AnalyzeSource to analyze entries lazily read with a Reader.
+func Analyze(entries []LogEntry, opts ...Option) (*AnalysisReport, error) {
+	src := SliceSource(entries)
+	return AnalyzeSource(&src, opts...)
 }
 
 // ReadFile read given log file and valid log entries.
 // Log entry not following the format will be skipped.
-func ReadFile(f *os.File) []LogEntry {
+// The error reading f is returned along with the entries read before it.
+func ReadFile(f io.Reader) ([]LogEntry, error) {
+	return readEntries(f, LineParser{}, &invalidCheck{})
+}
+
+// readEntries read the entries of r parsed by p as ReadFile, returning an
+// *InvalidInputError once there are too many invalid lines for check.
+func readEntries(r io.Reader, p Parser, check *invalidCheck) ([]LogEntry, error) {
 	var entries []LogEntry
-	s := bufio.NewScanner(f)
-	for s.Scan() {
-		line := s.Text()
-		entry, err := NewLogEntry(line)
+	entryReader := NewParserReader(r, p)
+	defer entryReader.Close()
+	for {
+		entry, err := entryReader.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		var perr *ParseError
+		if err != nil && !errors.As(err, &perr) {
+			return entries, err
+		}
+		if check.inHeader(err) {
+			continue
+		}
+		if err := check.line(err); err != nil {
+			return entries, err
+		}
+		// The entry of an invalid line is a zero entry.
 		if err != nil {
-			log.Println("invalid log entry: ", err)
+			continue
 		}
 		entries = append(entries, entry)
 	}
-	return entries
 }
 
 func isLogFile(file string) bool {
@@ -144,105 +1149,143 @@ func isLogFile(file string) bool {
 	}
 }
 
-type AnalysisReport struct {
-	TotalEntries int
-	Info         int
-	Warn         int
-	Error        int
-	Debug        int
-	ResponseTime []float64 // in ms
-	MsgFrequency map[string]int
+func NewLogEntry(line string) (LogEntry, error) {
+	// The fields are cut at the first three spaces without splitting the
+	// line into a slice, the timestamp being the first two fields as is.
+	_, rest, ok1 := strings.Cut(line, " ")
+	_, rest, ok2 := strings.Cut(rest, " ")
+	token, msg, ok3 := strings.Cut(rest, " ")
+	if !ok1 || !ok2 || !ok3 {
+		return LogEntry{}, &ParseError{Raw: line, Reason: TooFewFields}
+	}
+	t, err := parseDateTime(line[:len(line)-len(rest)-1])
+	if err != nil {
+		return LogEntry{}, &ParseError{Raw: line, Reason: BadTimestamp, Err: err}
+	}
+	// An unknown level is kept as its raw token.
+	level, _ := ParseLevel(token)
+	return LogEntry{
+		time:     t,
+		level:    level,
+		rawLevel: token,
+		message:  msg,
+	}, nil
 }
 
-const (
-	LevelInfo  = "info"
-	LevelWarn  = "warn"
-	LevelError = "error"
-	LevelDebug = "debug"
-)
-
-func (report *AnalysisReport) Add(entry LogEntry) {
-	report.TotalEntries++
-
-	// Record the log level count.
-	switch strings.ToLower(entry.level) {
-	case LevelInfo:
-		report.Info++
-	case LevelWarn:
-		report.Warn++
-	case LevelError:
-		report.Error++
-	case LevelDebug:
-		report.Debug++
+// ParseSource split a bracketed source prefix from the message.
+// e.g. "[api] Request processed" returns "api" and "Request processed".
+// Message without the prefix is returned as is with an empty source.
+func ParseSource(msg string) (source, rest string) {
+	if !strings.HasPrefix(msg, "[") {
+		return "", msg
 	}
-
-	// Record the response time.
-	if strings.HasSuffix(entry.message, "ms") {
-		words := strings.Split(strings.TrimSuffix(entry.message, " ms"), " ")
-		respTime := words[len(words)-1]
-		if n, err := strconv.ParseFloat(respTime, 64); err == nil {
-			report.ResponseTime = append(report.ResponseTime, float64(n))
-		}
+	end := strings.IndexByte(msg, ']')
+	if end < 2 {
+		return "", msg
 	}
-
-	// Record the frequency of each message.
-	report.MsgFrequency[entry.message]++
+	return msg[1:end], strings.TrimSpace(msg[end+1:])
 }
 
-// Total Log Entries: 5000
-// INFO: 3000
-// DEBUG: 1200
-// WARN: 500
-// ERROR: 300
-// Average Response Time: 245 ms
-func (r AnalysisReport) Print() {
-	fmt.Printf("Total Log Entries: %d\n", r.TotalEntries)
-	fmt.Printf("INFO: %d\n", r.Info)
-	fmt.Printf("DEBUG: %d\n", r.Debug)
-	fmt.Printf("WARN: %d\n", r.Warn)
-	fmt.Printf("ERROR: %d\n", r.Error)
-	if len(r.ResponseTime) > 0 {
-		var total float64
-		for _, v := range r.ResponseTime {
-			total += v
-		}
-		avg := total / float64(len(r.ResponseTime))
-		fmt.Printf("Average Response Time: %.2f ms\n", avg)
-	}
+// timeLayouts are the accepted layouts of time given on the command line.
+var timeLayouts = []string{time.DateTime, "2006-01-02T15:04:05"}
 
-	var freqCount []int
-	for k := range r.MsgFrequency {
-		freqCount = append(freqCount, r.MsgFrequency[k])
-	}
-	sort.Ints(freqCount)
-	var freqMsg string
-	for k, v := range r.MsgFrequency {
-		if v == freqCount[len(freqCount)-1] {
-			freqMsg = k
+// parseTime parse a time given on the command line in any of the timeLayouts.
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
 		}
 	}
-	fmt.Printf("Most frequent mesage: '%s'\n", freqMsg)
+	return time.Time{}, err
 }
 
-func NewLogEntry(line string) (LogEntry, error) {
-	logLine := strings.SplitN(line, " ", 4)
-	if len(logLine) < 4 {
-		return LogEntry{}, fmt.Errorf("invalid log entry")
+// NewLogEntryNoLevel parse a line without a level token, i.e. the timestamp
+// followed by the message. The entry is recorded under the LevelNone level.
+func NewLogEntryNoLevel(line string) (LogEntry, error) {
+	_, rest, ok1 := strings.Cut(line, " ")
+	_, msg, ok2 := strings.Cut(rest, " ")
+	if !ok1 || !ok2 {
+		return LogEntry{}, &ParseError{Raw: line, Reason: TooFewFields}
 	}
-	logDate, logTime, level, msg := logLine[0], logLine[1], logLine[2], logLine[3]
-	t, err := time.Parse(time.DateTime, logDate+" "+logTime)
+	t, err := parseDateTime(line[:len(line)-len(msg)-1])
 	if err != nil {
-		return LogEntry{}, fmt.Errorf("invalid log time: %w", err)
+		return LogEntry{}, &ParseError{Raw: line, Reason: BadTimestamp, Err: err}
 	}
 	return LogEntry{
 		time:    t,
-		level:   level,
+		level:   LevelNone,
 		message: msg,
 	}, nil
 }
 
 type LogEntry struct {
-	time    time.Time
-	level   string
-	message string
+	time     time.Time
+	level    Level
+	rawLevel string // level token of the line, see levelToken
+	source   string
+	message  string
+	data     map[string]string // structured data, e.g. of rfc5424 lines
+}
+
+// Field return the value of the structured data param name of the entry.
+func (e LogEntry) Field(name string) (string, bool) {
+	v, ok := e.data[name]
+	return v, ok
+}
+
+// MarshalJSON encode the entry as a json object with the time, level
+// token, source and message of the entry, and its structured data as fields.
+func (e LogEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Time    time.Time         `json:"time"`
+		Level   string            `json:"level"`
+		Source  string            `json:"source,omitempty"`
+		Message string            `json:"message"`
+		Fields  map[string]string `json:"fields,omitempty"`
+	}{e.time, e.levelToken(), e.source, e.message, e.data})
+}
+
+// UnmarshalJSON decode an entry encoded by MarshalJSON.
+func (e *LogEntry) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Time    time.Time         `json:"time"`
+		Level   string            `json:"level"`
+		Source  string            `json:"source"`
+		Message string            `json:"message"`
+		Fields  map[string]string `json:"fields"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	level, _ := ParseLevel(v.Level)
+	*e = LogEntry{time: v.Time, level: level, rawLevel: v.Level, source: v.Source, message: v.Message, data: v.Fields}
+	return nil
+}
+
+// Equal report whether both entries are the same, the time is compared
+// with time.Time.Equal so entries on different locations are equal if
+// they denote the same instant.
+func (e LogEntry) Equal(other LogEntry) bool {
+	return e.time.Equal(other.time) &&
+		e.level == other.level &&
+		e.levelToken() == other.levelToken() &&
+		e.source == other.source &&
+		e.message == other.message &&
+		maps.Equal(e.data, other.data)
+}
+
+// EqualSlice report whether both slices hold equal entries in the same order.
+func EqualSlice(a, b []LogEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mainEnv is set in the child processes of the test binary started by
+// runMain to run log-analyzer with the arguments following "--".
+const mainEnv = "LOG_ANALYZER_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(mainEnv) != "" {
+		i := slices.Index(os.Args, "--")
+		os.Args = append([]string{"log-analyzer"}, os.Args[i+1:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain run log-analyzer with args in a child process, as it exits on
+// error and reads the global flags, returning its standard output,
+// standard error and exit status.
+func runMain(t *testing.T, args ...string) (stdout, stderr string, status int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=^$", "--"}, args...)...)
+	cmd.Env = append(os.Environ(), mainEnv+"=1")
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		status = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return out.String(), errOut.String(), status
+}
+
+// writeLines write the lines to a log file in a temporary directory,
+// returning its path.
+func writeLines(tb testing.TB, name string, lines ...string) string {
+	tb.Helper()
+	return writeText(tb, name, strings.Join(lines, "\n")+"\n")
+}
+
+// writeText write text to a file named name and return its path.
+func writeText(tb testing.TB, name, text string) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), name)
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSource(t *testing.T) {
+	for _, tt := range []struct {
+		msg, source, rest string
+	}{
+		{"[api] Request processed", "api", "Request processed"},
+		{"[db-1]   Connection lost", "db-1", "Connection lost"},
+		{"[api]", "api", ""},
+		{"Request processed", "", "Request processed"},
+		{"[] empty source", "", "[] empty source"},
+		{"[unterminated source", "", "[unterminated source"},
+		{"", "", ""},
+	} {
+		source, rest := ParseSource(tt.msg)
+		if source != tt.source || rest != tt.rest {
+			t.Errorf("ParseSource(%q) = %q, %q, want %q, %q", tt.msg, source, rest, tt.source, tt.rest)
+		}
+	}
+}
+
+func TestAnalyzeSources(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:00 INFO [api] Request processed in 12 ms",
+		"2025-01-01 10:00:01 ERROR [db] Connection lost",
+		"2025-01-01 10:00:02 INFO [api] Request processed in 15 ms",
+		"2025-01-01 10:00:03 INFO No source",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.source, entry.message = ParseSource(entry.message)
+		entries = append(entries, entry)
+	}
+	report, err := Analyze(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Sources) != 2 || report.Sources["api"] != 2 || report.Sources["db"] != 1 {
+		t.Errorf("got sources %v, want api 2 and db 1", report.Sources)
+	}
+	if report.MsgFrequency["Connection lost"] != 1 {
+		t.Errorf("got messages %v, want the messages without their source", report.MsgFrequency)
+	}
+}
+
+func TestSummaryLineExitStatus(t *testing.T) {
+	clean := writeLines(t, "clean.log",
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 INFO Request processed in 20 ms",
+	)
+	failing := writeLines(t, "failing.log",
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+	)
+	for _, tt := range []struct {
+		path, want string
+		status     int
+	}{
+		{clean, "total=2 info=2 debug=0 warn=0 error=0 avg_response_ms=15.00\n", 0},
+		{failing, "total=2 info=1 debug=0 warn=0 error=1 avg_response_ms=10.00\n", 1},
+	} {
+		stdout, stderr, status := runMain(t, "-summary-line", "-level", "info,error", tt.path)
+		if stdout != tt.want || status != tt.status {
+			t.Errorf("%s: got %q with status %d, want %q with status %d\nstderr: %s", filepath.Base(tt.path), stdout, status, tt.want, tt.status, stderr)
+		}
+	}
+
+	// The errors filtered out do not fail the summary.
+	if stdout, _, status := runMain(t, "-summary-line", "-level", "info", failing); status != 0 || !strings.HasPrefix(stdout, "total=1 ") {
+		t.Errorf("got %q with status %d, want the info entry with status 0", stdout, status)
+	}
+}
+
+// The CRI prefix of the container logs is stripped before parsing the
+// inner entry, the lines without it being parsed as they are.
+func TestStripPrefix(t *testing.T) {
+	path := writeLines(t, "container.log",
+		"2025-01-01T10:00:00.123456789Z stdout F 2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01T10:00:01.000000001Z stderr F 2025-01-01 10:00:01 ERROR Connection lost",
+		"2025-01-01 10:00:02 WARN Disk almost full stdout F",
+	)
+	stdout, stderr, status := runMain(t, "-summary-line", "-level", "info,warn,error", "-strip-prefix", `\S+ (stdout|stderr) [FP] `, path)
+	if want := "total=3 info=1 debug=0 warn=1 error=1 avg_response_ms=10.00\n"; stdout != want {
+		t.Errorf("got %q with status %d, want %q\nstderr: %s", stdout, status, want, stderr)
+	}
+
+	stdout, _, _ = runMain(t, "-summary-line", "-level", "info,warn,error", path)
+	if !strings.HasPrefix(stdout, "total=1 ") {
+		t.Errorf("got %q, want the prefixed lines invalid without -strip-prefix", stdout)
+	}
+}
+
+// Without a level token, NewLogEntry takes the first word of the message
+// for the level while NewLogEntryNoLevel keeps the whole message.
+func TestNoLevel(t *testing.T) {
+	for _, tt := range []struct {
+		line       string
+		level      Level
+		message    string
+		err        error
+		noLevelMsg string
+		noLevelErr error
+	}{
+		{"2025-01-01 10:00:00 Request processed in 10 ms", LevelUnknown, "processed in 10 ms", nil, "Request processed in 10 ms", nil},
+		{"2025-01-01 10:00:00 error while connecting", LevelError, "while connecting", nil, "error while connecting", nil},
+		{"2025-01-01 10:00:00 Started", LevelUnknown, "", ErrTooFewFields, "Started", nil},
+		{"2025-01-01 10:00:00 ", LevelUnknown, "", ErrTooFewFields, "", nil},
+		{"2025-01-01 10:00:00", LevelUnknown, "", ErrTooFewFields, "", ErrTooFewFields},
+		{"yesterday at noon Request processed", LevelUnknown, "", ErrBadTimestamp, "", ErrBadTimestamp},
+	} {
+		entry, err := NewLogEntry(tt.line)
+		if !errors.Is(err, tt.err) || (err == nil && (entry.level != tt.level || entry.message != tt.message)) {
+			t.Errorf("NewLogEntry(%q) = %v %q, %v, want %v %q, %v", tt.line, entry.level, entry.message, err, tt.level, tt.message, tt.err)
+		}
+		entry, err = NewLogEntryNoLevel(tt.line)
+		if !errors.Is(err, tt.noLevelErr) || (err == nil && (entry.level != LevelNone || entry.message != tt.noLevelMsg)) {
+			t.Errorf("NewLogEntryNoLevel(%q) = %v %q, %v, want none %q, %v", tt.line, entry.level, entry.message, err, tt.noLevelMsg, tt.noLevelErr)
+		}
+	}
+
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 Request processed in 10 ms",
+		"2025-01-01 10:00:01 error while connecting",
+	)
+	for _, args := range [][]string{{"-no-level"}, {"-input", "no-level"}} {
+		stdout, stderr, status := runMain(t, append(args, path)...)
+		if status != 0 || !strings.HasPrefix(stdout, "Total Log Entries: 2\n") || strings.Contains(stdout, "Error") {
+			t.Errorf("%q: got %q, %q, exit %d, want the 2 entries without a level breakdown", args, stdout, stderr, status)
+		}
+	}
+}
+
+// The json report is a single compact line unless -pretty indents it,
+// both holding the same report.
+func TestJSONPretty(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+	)
+	compact, stderr, status := runMain(t, "-format", "json", "-level", "info,error", path)
+	if status != 0 || strings.Count(compact, "\n") != 1 || !strings.HasSuffix(compact, "}\n") || strings.Contains(compact, "  ") {
+		t.Errorf("got %q, %q, exit %d, want a compact single line", compact, stderr, status)
+	}
+	pretty, stderr, status := runMain(t, "-format", "json", "-pretty", "-level", "info,error", path)
+	if status != 0 || strings.Count(pretty, "\n") < 3 || !strings.Contains(pretty, "{\n  \"") {
+		t.Errorf("got %q, %q, exit %d, want lines indented with two spaces", pretty, stderr, status)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(compact), "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	if indented.String() != pretty {
+		t.Errorf("got the pretty report\n%s\nwant the compact one indented\n%s", pretty, indented.String())
+	}
+}
+
+func TestLogEntryEqual(t *testing.T) {
+	entry, err := NewLogEntry("2025-01-01 10:00:00 ERROR [db] Connection lost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.time = entry.time.Add(123456789 * time.Nanosecond)
+
+	same := entry
+	same.time = entry.time.In(time.FixedZone("CET", 3600))
+	if !entry.Equal(same) {
+		t.Errorf("%v is not equal to the same instant in another location %v", entry.time, same.time)
+	}
+	// The round trip of the time through its text loses nothing.
+	roundTrip, err := time.Parse(time.RFC3339Nano, entry.time.Format(time.RFC3339Nano))
+	if err != nil {
+		t.Fatal(err)
+	}
+	same.time = roundTrip
+	if !entry.Equal(same) {
+		t.Errorf("%v is not equal to its round trip %v", entry.time, same.time)
+	}
+
+	for name, change := range map[string]func(*LogEntry){
+		"time":    func(e *LogEntry) { e.time = e.time.Add(time.Nanosecond) },
+		"seconds": func(e *LogEntry) { e.time = e.time.Truncate(time.Second) },
+		"level":   func(e *LogEntry) { e.level = LevelWarn },
+		"token":   func(e *LogEntry) { e.rawLevel = "WARNING" },
+		"source":  func(e *LogEntry) { e.source = "api" },
+		"message": func(e *LogEntry) { e.message = "Connection restored" },
+	} {
+		other := entry
+		change(&other)
+		if entry.Equal(other) || other.Equal(entry) {
+			t.Errorf("entries differing by their %s are equal", name)
+		}
+	}
+
+	other := entry
+	other.message = "Connection restored"
+	if !EqualSlice([]LogEntry{entry, other}, []LogEntry{entry, other}) || !EqualSlice(nil, []LogEntry{}) {
+		t.Error("equal slices are not equal")
+	}
+	if EqualSlice([]LogEntry{entry, other}, []LogEntry{other, entry}) || EqualSlice([]LogEntry{entry}, []LogEntry{entry, entry}) {
+		t.Error("slices of different entries or lengths are equal")
+	}
+}
+
+func BenchmarkNewLogEntry(b *testing.B) {
+	lines := strings.Split(strings.TrimSuffix(logText(generateEntries(1000, 0)), "\n"), "\n")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewLogEntry(lines[i%len(lines)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	lines := strings.Split(strings.TrimSuffix(logText(generateEntries(100_000, 0)), "\n"), "\n")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		report := NewAnalysisReport()
+		for _, line := range lines {
+			entry, err := NewLogEntry(line)
+			if err != nil {
+				b.Fatal(err)
+			}
+			report.Add(entry)
+		}
+	}
+}
+
+// The output of the same analysis is byte-identical from one run to the
+// next, despite the map iterations and the ties between the counts, and
+// only changes deliberately, by updating the golden files with
+// 'go test -run Deterministic -update'.
+func TestOutputDeterministic(t *testing.T) {
+	sources := []string{"api", "db", "web"}
+	var lines []string
+	for i, line := range strings.Split(strings.TrimSuffix(logText(generateEntries(300, 3)), "\n"), "\n") {
+		level, msg, _ := strings.Cut(line[len(time.DateTime)+1:], " ")
+		lines = append(lines, fmt.Sprintf("%s %s [%s] %s", line[:len(time.DateTime)], level, sources[i%len(sources)], msg))
+	}
+	path := writeLines(t, "app.log", lines...)
+	common := []string{"-level", "info,warn,error,debug", "-source-prefix", "-top", "5", "-percentiles", "50,90,99"}
+	for _, tt := range []struct {
+		name string
+		args []string
+	}{
+		{"text", []string{"-color", "never"}},
+		{"json", []string{"-format", "json"}},
+		{"table", []string{"-format", "table"}},
+		{"markdown", []string{"-format", "markdown"}},
+		{"by_source", []string{"-color", "never", "-by-source", "source"}},
+		{"top_errors", []string{"-color", "never", "-top-errors-by-time", "3"}},
+		{"by_day", []string{"-group-by-day", "-format", "csv"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(append(append([]string(nil), common...), tt.args...), path)
+			first, stderr, status := runMain(t, args...)
+			if status != 0 {
+				t.Fatalf("exit status %d: %s", status, stderr)
+			}
+			if second, _, _ := runMain(t, args...); second != first {
+				t.Fatalf("got the output\n%s\nthen\n%s", first, second)
+			}
+			golden(t, "deterministic_"+tt.name+".golden", first)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// MetricsHandler return the handler serving the report
+// metrics in the Prometheus text exposition format.
+func MetricsHandler(report *AnalysisReport) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		writeMetrics(bw, report)
+		if err := bw.Flush(); err != nil {
+			log.Println("failed to write metrics: ", err)
+		}
+	}
+}
+
+func writeMetrics(w *bufio.Writer, report *AnalysisReport) {
+	fmt.Fprintln(w, "# HELP loganalyzer_total_entries Number of log entries analyzed.")
+	fmt.Fprintln(w, "# TYPE loganalyzer_total_entries gauge")
+	fmt.Fprintf(w, "loganalyzer_total_entries %d\n", report.TotalEntries)
+
+	fmt.Fprintln(w, "# HELP loganalyzer_level_total Number of log entries per level.")
+	fmt.Fprintln(w, "# TYPE loganalyzer_level_total counter")
+	for _, l := range []struct {
+		name  Level
+		count int
+	}{{LevelInfo, report.Info}, {LevelDebug, report.Debug}, {LevelWarn, report.Warn}, {LevelError, report.Error}} {
+		fmt.Fprintf(w, "loganalyzer_level_total{level=%q} %d\n", l.name, l.count)
+	}
+
+	if avg, ok := report.AverageResponseTime(); ok {
+		fmt.Fprintln(w, "# HELP loganalyzer_avg_response_time_ms Average response time in milliseconds.")
+		fmt.Fprintln(w, "# TYPE loganalyzer_avg_response_time_ms gauge")
+		fmt.Fprintf(w, "loganalyzer_avg_response_time_ms %g\n", avg)
+	}
+}
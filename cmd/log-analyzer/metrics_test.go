@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// metricLine is a sample line of the Prometheus text exposition format.
+var metricLine = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{[a-zA-Z_][a-zA-Z0-9_]*="[^"]*"(,[a-zA-Z_][a-zA-Z0-9_]*="[^"]*")*\})? (\S+)$`)
+
+// parseMetrics parse the exposition text, checking each sample follow the
+// HELP and TYPE of its metric, and return the samples by name and labels.
+func parseMetrics(t *testing.T, text string) map[string]float64 {
+	t.Helper()
+	samples := make(map[string]float64)
+	var help, typ string
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if name, ok := strings.CutPrefix(line, "# HELP "); ok {
+			help, _, _ = strings.Cut(name, " ")
+			continue
+		}
+		if name, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			name, kind, _ := strings.Cut(name, " ")
+			if name != help || (kind != "gauge" && kind != "counter") {
+				t.Errorf("got %q, want the gauge or counter type of %s", line, help)
+			}
+			typ = name
+			continue
+		}
+		m := metricLine.FindStringSubmatch(line)
+		if m == nil {
+			t.Errorf("got the invalid sample %q", line)
+			continue
+		}
+		if m[1] != typ {
+			t.Errorf("got the sample %q without the HELP and TYPE of its metric", line)
+		}
+		v, err := strconv.ParseFloat(m[4], 64)
+		if err != nil {
+			t.Errorf("got the sample %q: %v", line, err)
+		}
+		samples[m[1]+m[2]] = v
+	}
+	return samples
+}
+
+func TestMetricsHandler(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+		"2025-01-01 10:00:02 INFO Request processed in 15 ms",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	report, err := Analyze(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	MetricsHandler(report)(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("got the content type %q, want the text exposition format", ct)
+	}
+	samples := parseMetrics(t, rec.Body.String())
+	for name, want := range map[string]float64{
+		"loganalyzer_total_entries":              3,
+		`loganalyzer_level_total{level="info"}`:  2,
+		`loganalyzer_level_total{level="debug"}`: 0,
+		`loganalyzer_level_total{level="warn"}`:  0,
+		`loganalyzer_level_total{level="error"}`: 1,
+		"loganalyzer_avg_response_time_ms":       12.5,
+	} {
+		if got, ok := samples[name]; !ok || got != want {
+			t.Errorf("got %s %v, want %v in\n%s", name, got, want, rec.Body.String())
+		}
+	}
+
+	// Without response times there is no average.
+	rec = httptest.NewRecorder()
+	MetricsHandler(NewAnalysisReport())(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if samples := parseMetrics(t, rec.Body.String()); len(samples) != 5 {
+		t.Errorf("got %d samples for an empty report, want the total and the levels:\n%s", len(samples), rec.Body.String())
+	}
+}
+
+func TestEntryServerMetrics(t *testing.T) {
+	srv := newTestEntryServer(t)
+	status, _, body := get(t, srv, "/metrics", "")
+	if samples := parseMetrics(t, body); status != http.StatusOK || samples[`loganalyzer_level_total{level="error"}`] != 2 {
+		t.Errorf("got %d\n%s\nwant the metrics of the served report", status, body)
+	}
+}
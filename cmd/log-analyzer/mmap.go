@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+// MappedFile is the content of a file mapped in memory with -mmap. It is
+// an io.Reader, but scanLines iterate its lines straight from the mapping
+// rather than copying them to the buffer of a bufio.Scanner first.
+type MappedFile struct {
+	data  []byte
+	off   int // offset of the next byte to read
+	unmap func() error
+}
+
+// mapFile map the regular file f in memory, or return false when it can not
+// be mapped, e.g. a pipe, an empty or a compressed file or on the platforms
+// without mmap, the file being read as usual then.
+func mapFile(f *os.File) (*MappedFile, bool) {
+	if filepath.Ext(f.Name()) == ".gz" {
+		return nil, false
+	}
+	fi, err := f.Stat()
+	if err != nil || !fi.Mode().IsRegular() || fi.Size() == 0 || int64(int(fi.Size())) != fi.Size() {
+		return nil, false
+	}
+	data, unmap, err := mmap(f, int(fi.Size()))
+	if err != nil {
+		return nil, false
+	}
+	return &MappedFile{data: data, unmap: unmap}, true
+}
+
+func (m *MappedFile) Read(p []byte) (int, error) {
+	if m.off >= len(m.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[m.off:])
+	m.off += n
+	return n, nil
+}
+
+// Close unmap the file, the strings of its lines remaining valid.
+func (m *MappedFile) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+	err := m.unmap()
+	m.data, m.unmap = nil, nil
+	return err
+}
+
+// lines return the sequence of the lines not read yet, split as by
+// bufio.ScanLines but without any limit on their length.
+func (m *MappedFile) lines() iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for m.off < len(m.data) {
+			line := m.data[m.off:]
+			if i := bytes.IndexByte(line, '\n'); i >= 0 {
+				line = line[:i]
+				m.off += i + 1
+			} else {
+				m.off = len(m.data)
+			}
+			if !yield(string(bytes.TrimSuffix(line, []byte{'\r'})), nil) {
+				return
+			}
+		}
+	}
+}
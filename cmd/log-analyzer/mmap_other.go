@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// mmap return an error as mapping files is not supported on this
+// platform, the files are then read as usual.
+func mmap(f *os.File, size int) (data []byte, unmap func() error, err error) {
+	return nil, nil, errors.ErrUnsupported
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// mapped return the file named name holding text, mapped in memory.
+func mapped(t *testing.T, name, text string) *MappedFile {
+	t.Helper()
+	f, err := os.Open(writeText(t, name, text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	m, ok := mapFile(f)
+	if !ok {
+		t.Skip("mmap is not supported")
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+// The lines of a mapped file are split as the bufio.Scanner split them.
+func TestMappedFileLines(t *testing.T) {
+	for _, text := range []string{
+		"a\nb\n",
+		"a\r\nb\r\n\r\nc",
+		"\n\nlast without newline",
+		"a\n",
+	} {
+		var want, got []string
+		for line, err := range scanLines(strings.NewReader(text)) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			want = append(want, line)
+		}
+		for line, err := range scanLines(mapped(t, "app.log", text)) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, line)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%q: got the lines %q, want %q", text, got, want)
+		}
+	}
+}
+
+// Compressed and empty files are not mapped.
+func TestMapFileFallback(t *testing.T) {
+	for name, text := range map[string]string{"app.log.1.gz": "compressed", "empty.log": ""} {
+		f, err := os.Open(writeText(t, name, text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m, ok := mapFile(f); ok {
+			m.Close()
+			t.Errorf("%s: got the file mapped", name)
+		}
+		f.Close()
+	}
+}
+
+func TestMmapFlag(t *testing.T) {
+	path := writeText(t, "app.log", logText(generateEntries(500, 0)))
+	want, _, _ := runMain(t, "-level", "info,warn,error", path)
+	stdout, stderr, status := runMain(t, "-mmap", "-level", "info,warn,error", path)
+	if status != 0 || stdout != want {
+		t.Errorf("got %q, %q, exit %d, want %q", stdout, stderr, status, want)
+	}
+}
+
+// BenchmarkScanLines compare iterating the lines of a file with a
+// bufio.Scanner and from its mapping. The file is generated unless
+// LOG_ANALYZER_BENCH_FILE names one, e.g. a multi-GB production log.
+func BenchmarkScanLines(b *testing.B) {
+	path := os.Getenv("LOG_ANALYZER_BENCH_FILE")
+	if path == "" {
+		path = writeText(b, "bench.log", logText(generateEntries(500_000, 0)))
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, useMmap := range []bool{false, true} {
+		name := "bufio"
+		if useMmap {
+			name = "mmap"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(fi.Size())
+			for i := 0; i < b.N; i++ {
+				f, err := os.Open(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var r io.Reader = f
+				if useMmap {
+					m, ok := mapFile(f)
+					if !ok {
+						b.Skip("mmap is not supported")
+					}
+					r = m
+				}
+				for _, err := range scanLines(r) {
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				if m, ok := r.(*MappedFile); ok {
+					m.Close()
+				}
+				f.Close()
+			}
+		})
+	}
+}
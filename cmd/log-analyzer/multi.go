@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// FileReport is the report of one of the files analyzed by AnalyzeFiles,
+// or the error analyzing it.
+type FileReport struct {
+	Path   string
+	Report *AnalysisReport
+	Err    error
+}
+
+// AnalyzeFiles analyze the files concurrently, a worker analyzing a file at
+// a time with up to workers of them, each file into its own report. The
+// gzip compressed files are decompressed as the -rotated ones. The reports
+// are returned in the order of files, whatever the order the analyses
+// complete in.
+//
+// A file which can not be analyzed does not stop the others, its error
+// being set in its FileReport, unless failFast is set: the first error then
+// cancel the analyses in progress and is returned, along with the reports.
+func AnalyzeFiles(ctx context.Context, files []string, workers int, failFast bool, opts ...Option) ([]FileReport, error) {
+	if _, err := newOptions(opts...); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each file is parsed by its worker alone, the workers of
+	// the files running on the cores instead.
+	opts = append(opts[:len(opts):len(opts)], WithWorkers(1))
+	reports := make([]FileReport, len(files))
+	for i, path := range files {
+		reports[i].Path = path
+	}
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		next     = make(chan int)
+	)
+	for w := 0; w < min(max(workers, 1), len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				report, err := analyzeFile(ctx, files[i], opts)
+				reports[i].Report, reports[i].Err = report, err
+				if err != nil && failFast {
+					once.Do(func() {
+						firstErr = fmt.Errorf("%s: %w", files[i], err)
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+feed:
+	for i := range files {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed // the files left are not analyzed
+		}
+	}
+	close(next)
+	wg.Wait()
+	return reports, firstErr
+}
+
+// analyzeFile analyze the file at path, decompressing it
+// when its extension is '.gz'.
+func analyzeFile(ctx context.Context, path string, opts []Option) (*AnalysisReport, error) {
+	rr := OpenRotated([]string{path})
+	defer rr.Close()
+	report, err := AnalyzeContext(ctx, newFileReader(path, rr, 0), opts...)
+	if errors.As(err, new(*StoppedError)) {
+		return report, nil // reported in the report Stopped
+	}
+	if errors.Is(err, ErrNoEntries) {
+		return report, nil // e.g. a log just rotated, reported with 0 entries
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// MergeFileReports return the report merging the reports of the files
+// analyzed with opts, leaving out the files which failed.
+func MergeFileReports(reports []FileReport, opts ...Option) (*AnalysisReport, error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	merged := o.newReport()
+	for _, r := range reports {
+		if r.Report == nil {
+			continue
+		}
+		if err := merged.Merge(r.Report); err != nil {
+			return nil, err
+		}
+	}
+	merged.finish()
+	return merged, nil
+}
+
+// PrintFiles write the per file breakdown of the reports, in their order.
+func PrintFiles(w io.Writer, reports []FileReport) error {
+	ew := &errWriter{w: w}
+	fmt.Fprintln(ew, "Files:")
+	for _, r := range reports {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(ew, "  %s: failed: %v\n", r.Path, r.Err)
+		case r.Report == nil:
+			fmt.Fprintf(ew, "  %s: not analyzed\n", r.Path)
+		default:
+			fmt.Fprintf(ew, "  %s: %d entries, %d errors\n", r.Path, r.Report.TotalEntries, r.Report.Error)
+		}
+	}
+	return ew.err
+}
+
+// logFilesIn return the log files in dir and their rotations, e.g.
+// app.log, app.log.1 and app.log.2.gz, sorted by name.
+func logFilesIn(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range dirEntries {
+		if e.Type().IsRegular() && isLogOrRotation(e.Name()) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isLogOrRotation report whether the file at path is a log file, as
+// isLogFile, or one of its rotations, e.g. app.log.1 or app.log.2.gz.
+func isLogOrRotation(path string) bool {
+	_, ext, _ := strings.Cut(filepath.Base(path), ".")
+	ext, _, _ = strings.Cut(ext, ".")
+	return ext == "log" || ext == "txt"
+}
+
+// runFiles analyze the files concurrently, printing the per file breakdown
+// in text format and the report merging them. It exits with status 1 when
+// any file could not be analyzed.
+func runFiles(files []string, opts []Option) {
+	if *follow || *watch || *watchEvery > 0 || *statePath != "" || *rotated || *httpServer != "" ||
+		*windowSpec != "" || *topErrors > 0 || *groupByDay || *windowStats > 0 || *silence > 0 || *sourceSpec != "" || isEntryFormat(*format) ||
+		*dedupGlobal || *sortByTime || *dedup || *dumpPath != "" || *parseOnly || *dryRun {
+		log.Fatalln("several files are only analyzed into a single report, not followed, watched, exported or otherwise analyzed")
+	}
+	for _, file := range files {
+		if !isLogOrRotation(file) {
+			log.Fatalf("arg: %s is not a log file", file)
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	reports, err := AnalyzeFiles(ctx, files, *workers, *failFast, opts...)
+	invalidLines.Flush()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	report, err := MergeFileReports(reports, opts...)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if *format == FormatText && *output == "" && !*summaryLine {
+		if err := PrintFiles(os.Stdout, reports); err != nil {
+			log.Fatalln("failed to write report: ", err)
+		}
+	}
+	if *savePath != "" {
+		if err := report.Save(*savePath); err != nil {
+			log.Fatalln("failed to save report: ", err)
+		}
+	}
+	if *summaryLine {
+		fmt.Println(report.Summary())
+	} else if err := writeReport(report); err != nil {
+		log.Fatalln("failed to write report: ", err)
+	}
+	failed := false
+	for _, r := range reports {
+		if r.Err != nil {
+			log.Printf("%s: %v", r.Path, r.Err)
+			failed = true
+		}
+	}
+	if !checkMessages(report) {
+		failed = true
+	}
+	if failed || (*summaryLine && report.Error > 0) {
+		os.Exit(1)
+	}
+}
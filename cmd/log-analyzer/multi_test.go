@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := writeRotations(t, dir)
+	missing := filepath.Join(dir, "gone.log")
+	files = append(files[:1], missing, files[1], files[2])
+
+	opts := []Option{WithInvalidLineHandler(DiscardInvalidLine)}
+	reports, err := AnalyzeFiles(context.Background(), files, 4, false, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{30, -1, 20, 10} {
+		r := reports[i]
+		if r.Path != files[i] {
+			t.Errorf("got the report %d of %s, want %s", i, r.Path, files[i])
+		}
+		if want < 0 {
+			if r.Err == nil || r.Report != nil {
+				t.Errorf("%s: got %v, want an error", r.Path, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil || r.Report.TotalLines != want {
+			t.Errorf("%s: got %v, want %d lines", r.Path, r.Err, want)
+		}
+	}
+	merged, err := MergeFileReports(reports, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.TotalLines != 60 {
+		t.Errorf("got %d lines merged, want 60", merged.TotalLines)
+	}
+
+	if _, err := AnalyzeFiles(context.Background(), files, 4, true, opts...); err == nil || !strings.Contains(err.Error(), "gone.log") {
+		t.Errorf("got %v, want the error of gone.log with fail fast", err)
+	}
+}
+
+func TestAnalyzeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeRotations(t, dir)
+	stdout, stderr, status := runMain(t, "-level", "info,warn,error,debug", dir)
+	want := fmt.Sprintf("Files:\n  %[1]s/app.log: 10 entries", dir)
+	if status != 0 || !strings.HasPrefix(stdout, want) || !strings.Contains(stdout, "app.log.2.gz: 30 entries") || !strings.Contains(stdout, "Total Log Entries: 60\n") {
+		t.Errorf("got %q, %q, exit %d", stdout, stderr, status)
+	}
+
+	stdout, stderr, status = runMain(t, "-level", "info,warn,error,debug", filepath.Join(dir, "app.log"), filepath.Join(dir, "gone.log"))
+	if status != 1 || !strings.Contains(stdout, "gone.log: failed: ") || !strings.Contains(stdout, "Total Log Entries: 10\n") {
+		t.Errorf("got %q, %q, exit %d, want the report of app.log and exit 1", stdout, stderr, status)
+	}
+
+	// An empty log, e.g. just rotated, is analyzed into an empty report.
+	if err := os.WriteFile(filepath.Join(dir, "app.log"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, status = runMain(t, "-level", "info,warn,error,debug", dir)
+	if status != 0 || !strings.Contains(stdout, "app.log: 0 entries, 0 errors\n") || !strings.Contains(stdout, "Total Log Entries: 50\n") {
+		t.Errorf("got %q, %q, exit %d, want the empty app.log analyzed", stdout, stderr, status)
+	}
+}
+
+// BenchmarkAnalyzeFiles compare analyzing the files one after the other
+// and concurrently, a file per core.
+func BenchmarkAnalyzeFiles(b *testing.B) {
+	dir := b.TempDir()
+	var files []string
+	for i := 0; i < 16; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("app-%02d.log", i))
+		if err := os.WriteFile(path, []byte(logText(generateEntries(20_000, int64(i)))), 0o644); err != nil {
+			b.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	for name, workers := range map[string]int{"sequential": 1, "parallel": runtime.GOMAXPROCS(0)} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := AnalyzeFiles(context.Background(), files, workers, true, WithInvalidLineHandler(DiscardInvalidLine)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,579 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Option configure an analysis, see Analyze.
+type Option func(*options) error
+
+type options struct {
+	lines       LineParser
+	filters     []NamedFilter
+	workers     int
+	topN        int
+	percentiles []float64
+	interval    time.Duration
+	normalize   bool
+	group       *regexp.Regexp
+	maxMessages int
+	maxLen      int
+	accuracy    float64
+	sampleSize  int
+	seed        int64
+	empty       bool
+	strict      bool
+	maxInvalid  float64
+	maxErrors   int
+	onInvalid   InvalidLineHandler
+	skipHeader  bool
+	skipReasons bool
+	unparseable bool
+	humanize    bool
+	maxEntries  int
+	extractors  []MetricExtractor
+	key         FrequencyKey
+}
+
+// newOptions apply opts over the defaults, returning
+// the first error of an invalid option.
+func newOptions(opts ...Option) (*options, error) {
+	o := &options{workers: runtime.GOMAXPROCS(0), sampleSize: defaultSampleSize, seed: 1}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// WithLineParser parse the lines of the readers analyzed with p,
+// they are parsed in the default format otherwise.
+func WithLineParser(p LineParser) Option {
+	return func(o *options) error {
+		o.lines = p
+		return nil
+	}
+}
+
+// WithFilters skip the entries any of the filter returns true for,
+// as WithNamedFilters with filters without name.
+func WithFilters(filter ...FilterFunc) Option {
+	named := make([]NamedFilter, len(filter))
+	for i, f := range filter {
+		named[i] = NamedFilter{Filter: f}
+	}
+	return WithNamedFilters(named...)
+}
+
+// WithNamedFilters skip the entries any of the filter returns true for.
+func WithNamedFilters(filter ...NamedFilter) Option {
+	return func(o *options) error {
+		for _, f := range filter {
+			if f.Filter == nil {
+				return fmt.Errorf("filter %q must not be nil", f.Name)
+			}
+		}
+		o.filters = append(o.filters, filter...)
+		return nil
+	}
+}
+
+// WithWorkers set the number of workers parsing lines concurrently
+// when analyzing a reader. It defaults to GOMAXPROCS.
+func WithWorkers(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid workers %d: must be at least 1", n)
+		}
+		o.workers = n
+		return nil
+	}
+}
+
+// WithTopN record the n most frequent messages in the report Top.
+func WithTopN(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid top n %d: must be at least 1", n)
+		}
+		o.topN = n
+		return nil
+	}
+}
+
+// WithPercentiles record the given response time percentiles in the
+// report Percentiles, e.g. WithPercentiles(50, 95, 99).
+func WithPercentiles(p ...float64) Option {
+	return func(o *options) error {
+		for _, v := range p {
+			if math.IsNaN(v) || v <= 0 || v > 100 {
+				return fmt.Errorf("invalid percentile %g: must be within (0, 100]", v)
+			}
+		}
+		o.percentiles = append(o.percentiles, p...)
+		return nil
+	}
+}
+
+// WithInterval count the entries per interval in the report Timeline.
+func WithInterval(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid interval %s: must be positive", d)
+		}
+		o.interval = d
+		return nil
+	}
+}
+
+// WithNormalization count message frequencies by their normalized form,
+// see NormalizeMessage, so messages differing only by numbers are grouped.
+func WithNormalization(normalize bool) Option {
+	return func(o *options) error {
+		o.normalize = normalize
+		return nil
+	}
+}
+
+// WithGroupRegex count message frequencies by the part of the messages
+// captured by re, its first named group or else its first group, e.g.
+// `(GET|POST) (?P<path>\S+)` counts the requests per path. Messages
+// re does not match are counted as GroupUnmatched.
+func WithGroupRegex(re *regexp.Regexp) Option {
+	return func(o *options) error {
+		if re.NumSubexp() == 0 {
+			return fmt.Errorf("invalid group regex %q: must have a capture group", re)
+		}
+		o.group = re
+		return nil
+	}
+}
+
+// WithFrequencyKey count message frequencies by the key returned for each
+// entry rather than by its message, e.g. PrefixKey(3), NormalizedKey,
+// LevelMessageKey or FieldKey("logger"). The group regex and normalization
+// apply to the key, and an empty key is only counted WithEmptyMessages.
+// Functions can not be compared, so only reports analyzed with the same
+// key function should be merged.
+func WithFrequencyKey(key FrequencyKey) Option {
+	return func(o *options) error {
+		if key == nil {
+			return fmt.Errorf("frequency key must not be nil")
+		}
+		o.key = key
+		return nil
+	}
+}
+
+// WithEmptyMessages count the frequency of empty messages as well. By
+// default entries without a message are counted in the totals and levels
+// but left out of the message frequencies, where they are mostly noise.
+func WithEmptyMessages(include bool) Option {
+	return func(o *options) error {
+		o.empty = include
+		return nil
+	}
+}
+
+// WithMaxUniqueMessages bound the memory used to count message frequencies
+// by tracking at most n distinct messages. Once more are seen the counts
+// become approximate, see AnalysisReport.ApproximateMessages.
+func WithMaxUniqueMessages(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid max unique messages %d: must be at least 1", n)
+		}
+		o.maxMessages = n
+		return nil
+	}
+}
+
+// WithMaxMessageLen truncate the messages to their first n characters before
+// counting their frequency, see TruncateMessage, so that the long messages
+// differing only in their tail, e.g. a dumped payload, are counted together
+// and do not flood the text report. Zero keeps the messages whole.
+func WithMaxMessageLen(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("invalid max message length %d: must not be negative", n)
+		}
+		o.maxLen = n
+		return nil
+	}
+}
+
+// WithQuantileAccuracy estimate the response time percentiles with a
+// streaming quantile sketch of the given relative accuracy, e.g. 0.01 for
+// percentiles within 1% of the exact ones, instead of keeping every response
+// time in the report ResponseTime. The memory used then grows with the range
+// of the response times rather than their number.
+func WithQuantileAccuracy(accuracy float64) Option {
+	return func(o *options) error {
+		if math.IsNaN(accuracy) || accuracy <= 0 || accuracy >= 1 {
+			return fmt.Errorf("invalid quantile accuracy %g: must be within (0, 1)", accuracy)
+		}
+		o.accuracy = accuracy
+		return nil
+	}
+}
+
+// WithResponseSample keep a uniform sample of size of the response times
+// counted by the quantile sketch of WithQuantileAccuracy, which keeps no
+// actual value, see AnalysisReport.ResponseTimeSample. The sample is drawn
+// from a generator seeded with seed, the same input then giving the same
+// sample. A size of zero keeps no sample. By default 10000 response times
+// are sampled.
+func WithResponseSample(size int, seed int64) Option {
+	return func(o *options) error {
+		if size < 0 {
+			return fmt.Errorf("invalid sample size %d: must not be negative", size)
+		}
+		o.sampleSize, o.seed = size, seed
+		return nil
+	}
+}
+
+// WithStrict abort the analysis with an *InvalidInputError
+// at the first line which can not be parsed.
+func WithStrict(strict bool) Option {
+	return func(o *options) error {
+		o.strict = strict
+		return nil
+	}
+}
+
+// WithMaxInvalidRatio abort the analysis with an *InvalidInputError once
+// more than ratio of the lines analyzed can not be parsed. The ratio is
+// only checked after the first invalidRatioMinLines lines.
+func WithMaxInvalidRatio(ratio float64) Option {
+	return func(o *options) error {
+		if math.IsNaN(ratio) || ratio <= 0 || ratio >= 1 {
+			return fmt.Errorf("invalid max invalid ratio %g: must be within (0, 1)", ratio)
+		}
+		o.maxInvalid = ratio
+		return nil
+	}
+}
+
+// WithMaxErrors stop the analysis after n lines which can not be parsed,
+// returning the report of the lines analyzed so far with a *StoppedError.
+func WithMaxErrors(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid max errors %d: must be at least 1", n)
+		}
+		o.maxErrors = n
+		return nil
+	}
+}
+
+// WithMaxEntries only analyze the first n entries kept by the filters. The
+// following lines are still read and counted in the report TotalLines, so
+// the report Coverage tell the fraction of the input analyzed.
+func WithMaxEntries(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid max entries %d: must be at least 1", n)
+		}
+		o.maxEntries = n
+		return nil
+	}
+}
+
+// InvalidLineHandler is called with the number, starting at 1, and the
+// content of each line which could not be parsed along with its parse error.
+// Returning a non-nil error abort the analysis, which return it.
+type InvalidLineHandler func(lineNo int, line string, err error) error
+
+// ReportInvalidLine is the default InvalidLineHandler, reporting
+// the invalid lines on stderr as selected by -quiet and -verbose.
+func ReportInvalidLine(lineNo int, line string, err error) error {
+	invalidLines.Report(err)
+	return nil
+}
+
+// DiscardInvalidLine is an InvalidLineHandler ignoring the invalid lines,
+// for embedding the analysis where nothing should be written to stderr.
+func DiscardInvalidLine(lineNo int, line string, err error) error {
+	return nil
+}
+
+// WithInvalidLineHandler call h for every line which can not be parsed instead
+// of ReportInvalidLine, e.g. to write the rejected lines to a file. The line
+// is still counted in the report SkippedLines.
+func WithInvalidLineHandler(h InvalidLineHandler) Option {
+	return func(o *options) error {
+		if h == nil {
+			return fmt.Errorf("invalid line handler must not be nil")
+		}
+		o.onInvalid = h
+		return nil
+	}
+}
+
+// WithSkipHeader skip the lines before the first line which can be parsed,
+// such as the banner some programs write at the top of their logs. These
+// lines are neither reported to the InvalidLineHandler nor counted in the
+// report SkippedLines, the lines after the first valid one are as usual.
+func WithSkipHeader(skip bool) Option {
+	return func(o *options) error {
+		o.skipHeader = skip
+		return nil
+	}
+}
+
+// WithUnparseableRate show the report UnparseableRate in the text report,
+// even when every line could be parsed, for the inputs which are expected
+// to be partly malformed. The unparseable lines are counted in SkippedLines
+// either way.
+func WithUnparseableRate(include bool) Option {
+	return func(o *options) error {
+		o.unparseable = include
+		return nil
+	}
+}
+
+// WithSkipReasons count the skipped lines and entries by reason in the report
+// SkipReasons, the lines by parse error such as "too few fields" and the
+// entries by the name of the filter skipping them such as "filtered by level".
+func WithSkipReasons(count bool) Option {
+	return func(o *options) error {
+		o.skipReasons = count
+		return nil
+	}
+}
+
+// WithHumanize group the digits of the counts of the text report by
+// thousands, e.g. "5,000,000", which are plain by default for the scripts
+// parsing the report.
+func WithHumanize(humanize bool) Option {
+	return func(o *options) error {
+		o.humanize = humanize
+		return nil
+	}
+}
+
+// WithExtractors record the metrics of the extractors in the report Metrics
+// along with the response time of the built-in ResponseTimeExtractor, e.g.
+// to feed domain metrics such as a cache hit ratio through the analysis.
+func WithExtractors(extractors ...MetricExtractor) Option {
+	return func(o *options) error {
+		for _, extract := range extractors {
+			if extract == nil {
+				return fmt.Errorf("metric extractor must not be nil")
+			}
+		}
+		o.extractors = append(o.extractors, extractors...)
+		return nil
+	}
+}
+
+// newCheck return the check of the invalid lines configured by the options.
+func (o *options) newCheck() *invalidCheck {
+	return &invalidCheck{strict: o.strict, ratio: o.maxInvalid, maxErrors: o.maxErrors, handler: o.onInvalid, header: o.skipHeader}
+}
+
+// newReport return an empty report configured by the options.
+func (o *options) newReport() *AnalysisReport {
+	report := NewAnalysisReport()
+	report.normalize = o.normalize
+	report.group = o.group
+	report.includeEmpty = o.empty
+	report.extractors = o.extractors
+	report.key = o.key
+	report.interval = o.interval
+	report.topN = o.topN
+	report.percentiles = o.percentiles
+	report.maxEntries = o.maxEntries
+	report.humanize = o.humanize
+	report.maxLen = o.maxLen
+	report.unparseable = o.unparseable
+	if o.skipReasons {
+		report.SkipReasons = make(map[string]int)
+	}
+	if o.maxMessages > 0 {
+		report.messages = newSpaceSaving(o.maxMessages)
+	}
+	if o.accuracy > 0 {
+		report.sketch = newQuantileSketch(o.accuracy)
+		if o.sampleSize > 0 {
+			report.sample = newReservoir(o.sampleSize, o.seed)
+		}
+	}
+	return report
+}
+
+// MessageCount is the number of occurrences of a message.
+type MessageCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// before report whether m ranks before other, being more frequent
+// or as frequent and alphabetically first.
+func (m MessageCount) before(other MessageCount) bool {
+	if m.Count != other.Count {
+		return m.Count > other.Count
+	}
+	return m.Message < other.Message
+}
+
+// topMessages return the n most frequent messages, the messages
+// with the same frequency ordered alphabetically. Only the n best
+// messages are kept in a heap rather than sorting them all.
+func topMessages(freq map[string]int, n int) []MessageCount {
+	if n <= 0 {
+		return nil
+	}
+	h := make(countHeap, 0, min(n, len(freq)))
+	for msg, count := range freq {
+		m := MessageCount{Message: msg, Count: count}
+		if len(h) < n {
+			heap.Push(&h, m)
+		} else if m.before(h[0]) {
+			h[0] = m
+			heap.Fix(&h, 0)
+		}
+	}
+	top := make([]MessageCount, len(h))
+	for i := len(top) - 1; i >= 0; i-- {
+		top[i] = heap.Pop(&h).(MessageCount)
+	}
+	return top
+}
+
+// Percentile is the response time at or below which P percent of
+// the response times fall.
+type Percentile struct {
+	P     float64 `json:"p"`
+	Value float64 `json:"value"` // in ms
+}
+
+// percentiles return the percentiles of values using the nearest rank method.
+func percentiles(values []float64, ps []float64) []Percentile {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	result := make([]Percentile, len(ps))
+	for i, p := range ps {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		result[i] = Percentile{P: p, Value: sorted[max(rank-1, 0)]}
+	}
+	return result
+}
+
+// GroupUnmatched is the message frequency key of the
+// messages not matched by the group regex, see WithGroupRegex.
+const GroupUnmatched = "(unmatched)"
+
+// GroupMessage return the part of msg captured by re,
+// or GroupUnmatched if re does not match msg.
+func GroupMessage(re *regexp.Regexp, msg string) string {
+	m := re.FindStringSubmatchIndex(msg)
+	if m == nil {
+		return GroupUnmatched
+	}
+	group := 1
+	for i, name := range re.SubexpNames() {
+		if i > 0 && name != "" {
+			group = i
+			break
+		}
+	}
+	if m[2*group] < 0 {
+		// The group did not participate in the match.
+		return GroupUnmatched
+	}
+	return msg[m[2*group]:m[2*group+1]]
+}
+
+// NormalizeMessage replace each run of digits in msg with a '#', so
+// "Request 42 processed in 120 ms" becomes "Request # processed in # ms".
+func NormalizeMessage(msg string) string {
+	var b strings.Builder
+	b.Grow(len(msg))
+	digits := false
+	for _, r := range msg {
+		if unicode.IsDigit(r) {
+			if !digits {
+				b.WriteByte('#')
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FrequencyKey return the key the entry is counted under in
+// the message frequencies, see WithFrequencyKey.
+type FrequencyKey func(LogEntry) string
+
+// MessageKey is the default FrequencyKey, the message of the entry.
+func MessageKey(entry LogEntry) string {
+	return entry.message
+}
+
+// NormalizedKey is a FrequencyKey counting the messages by their
+// template, see NormalizeMessage.
+func NormalizedKey(entry LogEntry) string {
+	return NormalizeMessage(entry.message)
+}
+
+// LevelMessageKey is a FrequencyKey counting the messages of
+// each level apart, e.g. "ERROR Connection lost".
+func LevelMessageKey(entry LogEntry) string {
+	return strings.ToUpper(entry.level.String()) + " " + entry.message
+}
+
+// PrefixKey return a FrequencyKey counting the messages by their first n
+// words, separated by a single space, so "Connection lost to db-1" and
+// "Connection lost to db-2" are both "Connection lost" with n = 2.
+func PrefixKey(n int) FrequencyKey {
+	return func(entry LogEntry) string {
+		words := strings.Fields(entry.message)
+		return strings.Join(words[:max(min(n, len(words)), 0)], " ")
+	}
+}
+
+// FieldKey return a FrequencyKey counting the entries by the value of
+// their structured data field name, e.g. the logger of rfc5424 lines.
+// The entries without the field have an empty key.
+func FieldKey(name string) FrequencyKey {
+	return func(entry LogEntry) string {
+		v, _ := entry.Field(name)
+		return v
+	}
+}
+
+// TruncateMessage return the first n characters of msg followed by "..."
+// when it has more. Characters are runes rather than bytes, so a multibyte
+// character is never split.
+func TruncateMessage(msg string, n int) string {
+	if utf8.RuneCountInString(msg) <= max(n, 0) {
+		return msg
+	}
+	// Find the byte offset following the n-th rune.
+	i, runes := 0, 0
+	for i = range msg {
+		if runes == n {
+			break
+		}
+		runes++
+	}
+	return msg[:i] + "..."
+}
@@ -0,0 +1,531 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// optionsLog is a log of the entries analyzed by the options tests.
+const optionsLog = `2025-01-01 10:00:00 INFO Request 1 processed in 10 ms
+2025-01-01 10:10:00 INFO Request 2 processed in 20 ms
+2025-01-01 10:20:00 ERROR Connection lost
+2025-01-01 11:05:00 INFO Request 3 processed in 30 ms
+2025-01-01 11:15:00 WARN Connection slow
+2025-01-01 11:30:00 INFO Request 4 processed in 40 ms
+`
+
+func optionsEntries(t *testing.T) []LogEntry {
+	t.Helper()
+	var entries []LogEntry
+	for _, line := range strings.Split(strings.TrimSpace(optionsLog), "\n") {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Each option affects the report, analyzing the entries or the log.
+func TestOptions(t *testing.T) {
+	entries := optionsEntries(t)
+	hour, _ := time.Parse(time.DateTime, "2025-01-01 10:00:00")
+	for _, tt := range []struct {
+		name  string
+		opt   Option
+		check func(*AnalysisReport) error
+	}{
+		{"filters", WithFilters(func(e LogEntry) bool { return e.level != LevelInfo }), func(r *AnalysisReport) error {
+			if r.TotalEntries != 4 || r.Error != 0 {
+				return fmt.Errorf("got %d entries, %d errors, want the 4 info entries", r.TotalEntries, r.Error)
+			}
+			return nil
+		}},
+		{"top", WithTopN(2), func(r *AnalysisReport) error {
+			want := []MessageCount{{"Connection lost", 1}, {"Connection slow", 1}}
+			if !reflect.DeepEqual(r.Top, want) {
+				return fmt.Errorf("got top messages %v, want %v", r.Top, want)
+			}
+			return nil
+		}},
+		{"percentiles", WithPercentiles(50, 100), func(r *AnalysisReport) error {
+			want := []Percentile{{50, 20}, {100, 40}}
+			if !reflect.DeepEqual(r.Percentiles, want) {
+				return fmt.Errorf("got percentiles %v, want %v", r.Percentiles, want)
+			}
+			return nil
+		}},
+		{"interval", WithInterval(time.Hour), func(r *AnalysisReport) error {
+			want := []TimeBucket{{hour, 3}, {hour.Add(time.Hour), 3}}
+			if len(r.Timeline) != 2 || !r.Timeline[0].Start.Equal(want[0].Start) || r.Timeline[0].Count != 3 || !r.Timeline[1].Start.Equal(want[1].Start) || r.Timeline[1].Count != 3 {
+				return fmt.Errorf("got timeline %v, want %v", r.Timeline, want)
+			}
+			return nil
+		}},
+		{"normalization", WithNormalization(true), func(r *AnalysisReport) error {
+			if n := r.MsgFrequency["Request # processed in # ms"]; n != 4 || len(r.MsgFrequency) != 3 {
+				return fmt.Errorf("got messages %v, want the 4 requests counted together", r.MsgFrequency)
+			}
+			return nil
+		}},
+	} {
+		report, err := Analyze(entries, tt.opt)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if err := tt.check(report); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		for _, workers := range []int{1, 3} {
+			report, err := AnalyzeReader(strings.NewReader(optionsLog), tt.opt, WithWorkers(workers))
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if err := tt.check(report); err != nil {
+				t.Errorf("%s reading with %d workers: %v", tt.name, workers, err)
+			}
+		}
+	}
+
+	// Without options there are no derived fields.
+	report, err := Analyze(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalEntries != 6 || report.Top != nil || report.Percentiles != nil || report.Timeline != nil || len(report.MsgFrequency) != 6 {
+		t.Errorf("got %+v without options, want the 6 entries only", report)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"workers":    WithWorkers(0),
+		"top":        WithTopN(0),
+		"percentile": WithPercentiles(50, 101),
+		"negative":   WithPercentiles(-1),
+		"interval":   WithInterval(0),
+		"group":      WithGroupRegex(regexp.MustCompile(`GET`)),
+	} {
+		if _, err := Analyze(nil, opt); err == nil {
+			t.Errorf("%s: Analyze succeeded with an invalid option", name)
+		}
+		if _, err := AnalyzeReader(strings.NewReader(optionsLog), opt); err == nil {
+			t.Errorf("%s: AnalyzeReader succeeded with an invalid option", name)
+		}
+	}
+}
+
+// Empty messages are counted in the totals and levels,
+// but not in the frequencies unless included.
+func TestEmptyMessages(t *testing.T) {
+	at, _ := time.Parse(time.DateTime, "2025-01-01 10:00:00")
+	entries := []LogEntry{
+		{time: at, level: LevelInfo, rawLevel: "INFO", message: "Started"},
+		{time: at, level: LevelError, rawLevel: "ERROR"},
+		{time: at, level: LevelInfo, rawLevel: "INFO"},
+	}
+	for _, include := range []bool{false, true} {
+		report, err := Analyze(entries, WithEmptyMessages(include))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.TotalEntries != 3 || report.Info != 2 || report.Error != 1 {
+			t.Errorf("include %t: got %d entries, %d info and %d errors, want 3, 2 and 1", include, report.TotalEntries, report.Info, report.Error)
+		}
+		n, ok := report.MsgFrequency[""]
+		if ok != include || (include && n != 2) || report.MsgFrequency["Started"] != 1 {
+			t.Errorf("include %t: got the frequencies %v", include, report.MsgFrequency)
+		}
+	}
+}
+
+// invalidLog return a log of n lines, every invalidEvery-th line being invalid.
+func invalidLog(n, invalidEvery int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if i%invalidEvery == 0 {
+			fmt.Fprintf(&b, "invalid line %d\n", i)
+		} else {
+			fmt.Fprintf(&b, "2025-01-01 10:00:00 INFO Request %d processed in 10 ms\n", i)
+		}
+	}
+	return b.String()
+}
+
+func TestInvalidInput(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		log            string
+		opt            Option
+		invalid, total int // of the *InvalidInputError, if any
+	}{
+		{"strict", invalidLog(10, 4), WithStrict(true), 1, 4},
+		{"strict valid", invalidLog(10, 11), WithStrict(true), 0, 0},
+		{"ratio", invalidLog(2000, 3), WithMaxInvalidRatio(0.2), 334, 1002},
+		{"ratio below", invalidLog(2000, 6), WithMaxInvalidRatio(0.2), 0, 0},
+		// The ratio is not checked over the first invalidRatioMinLines lines.
+		{"ratio prefix", invalidLog(invalidRatioMinLines-1, 1), WithMaxInvalidRatio(0.2), 0, 0},
+		{"ratio min lines", invalidLog(invalidRatioMinLines, 1), WithMaxInvalidRatio(0.2), invalidRatioMinLines, invalidRatioMinLines},
+		{"lenient", invalidLog(2000, 1), WithStrict(false), 0, 0},
+	} {
+		for _, workers := range []int{1, 3} {
+			_, err := AnalyzeReader(strings.NewReader(tt.log), tt.opt, WithWorkers(workers))
+			var ierr *InvalidInputError
+			if tt.total == 0 {
+				if errors.As(err, &ierr) {
+					t.Errorf("%s with %d workers: got %v, want the analysis to pass", tt.name, workers, err)
+				}
+				continue
+			}
+			if !errors.As(err, &ierr) || ierr.Invalid != tt.invalid || ierr.Total != tt.total || !errors.As(err, new(*ParseError)) {
+				t.Errorf("%s with %d workers: got %v, want %d of %d lines invalid", tt.name, workers, err, tt.invalid, tt.total)
+			}
+		}
+	}
+	if _, err := newOptions(WithMaxInvalidRatio(1)); err == nil {
+		t.Error("accepted a max invalid ratio of 1")
+	}
+}
+
+// The analysis aborted for invalid input exit with a dedicated status.
+func TestInvalidInputExitStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(invalidLog(10, 2)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"-strict", path}, {"-strict", "-format", "parquet", "-output", path + ".parquet", path}} {
+		_, stderr, status := runMain(t, args...)
+		if status != exitInvalidInputStatus || !strings.Contains(stderr, "analysis aborted, 1 of 2 lines are invalid") {
+			t.Errorf("%q: got %q, exit %d, want exit %d", args, stderr, status, exitInvalidInputStatus)
+		}
+	}
+	if _, stderr, status := runMain(t, path); status != 0 {
+		t.Errorf("got %q, exit %d without -strict, want the analysis to pass", stderr, status)
+	}
+}
+
+// The banner before the first valid line is skipped silently, the invalid
+// lines after it are reported as usual.
+func TestSkipHeader(t *testing.T) {
+	const log = `==============================
+ MyApp v2.3.1 starting up
+ Config: /etc/myapp.yaml
+==============================
+2025-01-01 10:00:00 INFO Started
+2025-01-01 10:00:01 INFO Request 1 processed in 10 ms
+not a log line
+2025-01-01 10:00:02 ERROR Connection lost
+`
+	for _, workers := range []int{1, 3} {
+		var invalid []int
+		handler := func(lineNo int, line string, err error) error {
+			invalid = append(invalid, lineNo)
+			return nil
+		}
+		report, err := AnalyzeReader(strings.NewReader(log), WithSkipHeader(true), WithStrict(false),
+			WithInvalidLineHandler(handler), WithWorkers(workers))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.TotalEntries != 3 || report.SkippedLines != 1 || !slices.Equal(invalid, []int{7}) {
+			t.Errorf("with %d workers: got %d entries, %d skipped lines and the invalid lines %v, want 3, 1 and [7]", workers, report.TotalEntries, report.SkippedLines, invalid)
+		}
+	}
+
+	path := writeLines(t, "app.log", strings.Split(strings.TrimSuffix(log, "\n"), "\n")...)
+	stdout, stderr, status := runMain(t, "-skip-header", "-level", "info,error", path)
+	if status != 0 || strings.Contains(stderr, "starting up") || strings.Count(stderr, "invalid log entry") != 1 {
+		t.Errorf("got %q, exit %d, want only the line after the header reported", stderr, status)
+	}
+	if !strings.Contains(stdout, "Total Log Entries: 3") {
+		t.Errorf("got %q, want the 3 entries after the header", stdout)
+	}
+	if _, stderr, _ := runMain(t, "-level", "info,error", path); strings.Count(stderr, "invalid log entry") != 5 {
+		t.Errorf("got %q without -skip-header, want the header reported", stderr)
+	}
+}
+
+func TestFrequencyKey(t *testing.T) {
+	const log = `2025-01-01 10:00:00 INFO Connection lost to db-1
+2025-01-01 10:00:01 ERROR Connection lost to db-2
+2025-01-01 10:00:02 ERROR Connection lost to db-2
+2025-01-01 10:00:03 INFO Request 1 processed in 10 ms
+2025-01-01 10:00:04 INFO Request 2 processed in 20 ms
+`
+	for _, tt := range []struct {
+		name string
+		key  FrequencyKey
+		want map[string]int
+	}{
+		{"message", MessageKey, map[string]int{
+			"Connection lost to db-1": 1, "Connection lost to db-2": 2,
+			"Request 1 processed in 10 ms": 1, "Request 2 processed in 20 ms": 1,
+		}},
+		{"normalized", NormalizedKey, map[string]int{
+			"Connection lost to db-#": 3, "Request # processed in # ms": 2,
+		}},
+		{"prefix", PrefixKey(2), map[string]int{"Connection lost": 3, "Request 1": 1, "Request 2": 1}},
+		{"long prefix", PrefixKey(10), map[string]int{
+			"Connection lost to db-1": 1, "Connection lost to db-2": 2,
+			"Request 1 processed in 10 ms": 1, "Request 2 processed in 20 ms": 1,
+		}},
+		{"level", LevelMessageKey, map[string]int{
+			"INFO Connection lost to db-1": 1, "ERROR Connection lost to db-2": 2,
+			"INFO Request 1 processed in 10 ms": 1, "INFO Request 2 processed in 20 ms": 1,
+		}},
+		{"custom", func(e LogEntry) string { return e.time.Format("15:04") }, map[string]int{"10:00": 5}},
+	} {
+		report, err := AnalyzeReader(strings.NewReader(log), WithFrequencyKey(tt.key), WithTopN(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(report.MsgFrequency, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, report.MsgFrequency, tt.want)
+		}
+		if report.TotalEntries != 5 || len(report.Top) != 1 {
+			t.Errorf("%s: got %d entries and the top %v", tt.name, report.TotalEntries, report.Top)
+		}
+	}
+
+	// The key is normalized like the messages are.
+	report, err := AnalyzeReader(strings.NewReader(log), WithFrequencyKey(LevelMessageKey), WithNormalization(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"INFO Connection lost to db-#": 1, "ERROR Connection lost to db-#": 2, "INFO Request # processed in # ms": 2}
+	if !reflect.DeepEqual(report.MsgFrequency, want) {
+		t.Errorf("got %v, want %v", report.MsgFrequency, want)
+	}
+	if _, err := newOptions(WithFrequencyKey(nil)); err == nil {
+		t.Error("accepted a nil frequency key")
+	}
+}
+
+// The entries without the field are only counted with the empty messages.
+func TestFieldKey(t *testing.T) {
+	entries := []LogEntry{
+		{level: LevelInfo, message: "Started", data: map[string]string{"logger": "app"}},
+		{level: LevelInfo, message: "Connected", data: map[string]string{"logger": "db"}},
+		{level: LevelInfo, message: "Queried", data: map[string]string{"logger": "db"}},
+		{level: LevelInfo, message: "Stopped"},
+	}
+	report, err := Analyze(entries, WithFrequencyKey(FieldKey("logger")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"app": 1, "db": 2}; !reflect.DeepEqual(report.MsgFrequency, want) {
+		t.Errorf("got %v, want %v", report.MsgFrequency, want)
+	}
+	report, err = Analyze(entries, WithFrequencyKey(FieldKey("logger")), WithEmptyMessages(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"app": 1, "db": 2, "": 1}; !reflect.DeepEqual(report.MsgFrequency, want) {
+		t.Errorf("got %v, want %v", report.MsgFrequency, want)
+	}
+	if got := PrefixKey(-1)(entries[0]); got != "" {
+		t.Errorf("got the prefix %q of no words", got)
+	}
+}
+
+func TestGroupMessage(t *testing.T) {
+	for _, tt := range []struct {
+		re, msg, want string
+	}{
+		{`(GET|POST) (\S+)`, "GET /api/users 200", "GET"},
+		{`(GET|POST) (?P<path>\S+)`, "GET /api/users 200", "/api/users"},
+		{`(GET|POST) (?P<path>\S+)`, "Connection lost", GroupUnmatched},
+		{`^(?:user (\w+)|anonymous)`, "anonymous login", GroupUnmatched},
+		{`user (\w*)`, "user  logged in", ""},
+	} {
+		if got := GroupMessage(regexp.MustCompile(tt.re), tt.msg); got != tt.want {
+			t.Errorf("GroupMessage(%q, %q) = %q, want %q", tt.re, tt.msg, got, tt.want)
+		}
+	}
+}
+
+// The frequencies are counted by the captured group.
+func TestGroupRegex(t *testing.T) {
+	log := `2025-01-01 10:00:00 INFO GET /api/users 200 in 10 ms
+2025-01-01 10:00:01 INFO POST /api/users 201 in 12 ms
+2025-01-01 10:00:02 INFO GET /api/orders 200 in 8 ms
+2025-01-01 10:00:03 ERROR Connection lost
+2025-01-01 10:00:04 INFO GET /api/users/42 200 in 9 ms
+`
+	re := regexp.MustCompile(`(GET|POST) (?P<path>/api/[a-z]+)`)
+	report, err := AnalyzeReader(strings.NewReader(log), WithGroupRegex(re), WithTopN(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"/api/users": 3, "/api/orders": 1, GroupUnmatched: 1}
+	if !reflect.DeepEqual(report.MsgFrequency, want) || report.Top[0] != (MessageCount{"/api/users", 3}) {
+		t.Errorf("got the frequencies %v and top %v, want %v", report.MsgFrequency, report.Top, want)
+	}
+}
+
+func TestInvalidLineHandler(t *testing.T) {
+	const log = "2025-01-01 10:00:00 INFO Started\nnot a log line\n2025-01-01 10:00:01 INFO Request 1\ntoo few\n2025-01-01 10:00:02 ERROR Connection lost\n"
+	analyses := map[string]func(...Option) (*AnalysisReport, error){
+		"reader": func(opts ...Option) (*AnalysisReport, error) {
+			return AnalyzeReader(strings.NewReader(log), opts...)
+		},
+		"reader with 3 workers": func(opts ...Option) (*AnalysisReport, error) {
+			return AnalyzeReader(strings.NewReader(log), append(opts, WithWorkers(3))...)
+		},
+		"source": func(opts ...Option) (*AnalysisReport, error) {
+			return AnalyzeSource(NewReader(strings.NewReader(log)), opts...)
+		},
+	}
+	for name, analyze := range analyses {
+		// The invalid lines are handed to the handler and counted.
+		var rejected []string
+		report, err := analyze(WithInvalidLineHandler(func(lineNo int, line string, err error) error {
+			rejected = append(rejected, fmt.Sprintf("%d:%s", lineNo, line))
+			return nil
+		}))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := strings.Join(rejected, ","); got != "2:not a log line,4:too few" || report.SkippedLines != 2 || report.Info != 2 || report.Error != 1 {
+			t.Errorf("%s: got the rejected lines %s and %d skipped lines, %d info, %d errors", name, got, report.SkippedLines, report.Info, report.Error)
+		}
+
+		// The error of the handler abort the analysis.
+		abort := errors.New("abort")
+		_, err = analyze(WithInvalidLineHandler(func(lineNo int, line string, err error) error {
+			if lineNo == 4 {
+				return abort
+			}
+			return nil
+		}))
+		if !errors.Is(err, abort) {
+			t.Errorf("%s: got %v, want the error of the handler", name, err)
+		}
+
+		if _, err := analyze(WithInvalidLineHandler(DiscardInvalidLine)); err != nil {
+			t.Errorf("%s: got %v discarding the invalid lines", name, err)
+		}
+	}
+	if _, err := newOptions(WithInvalidLineHandler(nil)); err == nil {
+		t.Error("accepted a nil invalid line handler")
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	for _, tt := range []struct {
+		msg  string
+		n    int
+		want string
+	}{
+		{"Request processed", 100, "Request processed"},
+		{"Request processed", 17, "Request processed"},
+		{"Request processed", 7, "Request..."},
+		{"Größenänderung fehlgeschlagen", 5, "Größe..."},
+		{"日本語のメッセージ", 3, "日本語..."},
+		{"日本語", 3, "日本語"},
+		{"日本語", 1, "日..."},
+		{"日本語", 0, "..."},
+		{"", 0, ""},
+	} {
+		got := TruncateMessage(tt.msg, tt.n)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("TruncateMessage(%q, %d) = %q, want %q", tt.msg, tt.n, got, tt.want)
+		}
+	}
+}
+
+// The messages are truncated to the maximum length in characters before
+// counting them, the multibyte ones never split, and in the text report.
+func TestMaxMessageLen(t *testing.T) {
+	entries := []LogEntry{
+		{level: LevelInfo, message: "日本語のメッセージ 1"},
+		{level: LevelInfo, message: "日本語のメッセージ 2"},
+		{level: LevelInfo, message: "日本語"},
+	}
+	report, err := Analyze(entries, WithMaxMessageLen(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"日本語...": 2, "日本語": 1}
+	if !reflect.DeepEqual(report.MsgFrequency, want) {
+		t.Errorf("got %v, want %v", report.MsgFrequency, want)
+	}
+	report.MsgFrequency["日本語のメッセージ 3"] = 5
+	var b strings.Builder
+	if err := report.Fprint(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Most frequent mesage: '日本語...'") {
+		t.Errorf("the most frequent message is not truncated in\n%s", b.String())
+	}
+	if _, err := newOptions(WithMaxMessageLen(-1)); err == nil {
+		t.Error("accepted a negative max message length")
+	}
+}
+
+// The longest message is measured in characters rather than bytes.
+func TestLongestMessage(t *testing.T) {
+	entries := []LogEntry{
+		{level: LevelInfo, message: "Request processed"},
+		{level: LevelInfo, message: "日本語のメッセージ"}, // 9 characters, 27 bytes
+		{level: LevelInfo, message: "Connection lost"},
+	}
+	report, err := Analyze(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.LongestMessage != "Request processed" || report.LongestMessageLen != 17 {
+		t.Errorf("got the longest message %q of %d characters, want %q of 17", report.LongestMessage, report.LongestMessageLen, "Request processed")
+	}
+	report, err = Analyze(append(entries, LogEntry{level: LevelInfo, message: strings.Repeat("é", 20)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.LongestMessageLen != 20 {
+		t.Errorf("got the longest message of %d characters, want 20", report.LongestMessageLen)
+	}
+}
+
+// The analysis limited to the first entries still count all the lines.
+func TestMaxEntries(t *testing.T) {
+	text := logText(generateEntries(1000, 0))
+	for _, workers := range []int{1, 4} {
+		report, err := AnalyzeReader(strings.NewReader(text), WithMaxEntries(100), WithWorkers(workers))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.TotalEntries != 100 || report.TotalLines != 1000 || report.Coverage(report.TotalLines) != 0.1 {
+			t.Errorf("with %d workers: got %d entries of %d lines, coverage %g, want 100 of 1000", workers, report.TotalEntries, report.TotalLines, report.Coverage(report.TotalLines))
+		}
+	}
+	if got := NewAnalysisReport().Coverage(0); got != 0 {
+		t.Errorf("got the coverage %g without lines, want 0", got)
+	}
+	if _, err := newOptions(WithMaxEntries(0)); err == nil {
+		t.Error("accepted max entries 0")
+	}
+
+	path := writeLines(t, "app.log", strings.Split(strings.TrimSuffix(text, "\n"), "\n")...)
+	for _, args := range [][]string{{"-max-entries", "100"}, {"-max-entries", "100", "-sort"}} {
+		stdout, stderr, status := runMain(t, append(args, "-no-level", path)...)
+		if status != 0 {
+			t.Fatalf("%v: got %q, exit %d", args, stderr, status)
+		}
+		if !strings.Contains(stdout, "Total Log Entries: 100\nCoverage: 10.0%\n") {
+			t.Errorf("%v: got %q, want 100 entries and a coverage of 10%%", args, stdout)
+		}
+	}
+	if stdout, _, _ := runMain(t, "-no-level", path); strings.Contains(stdout, "Coverage") {
+		t.Errorf("got %q, want the coverage only with -max-entries", stdout)
+	}
+}
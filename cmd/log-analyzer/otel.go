@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// otelScope is the instrumentation scope of the log records written by
+// WriteOTelJSON.
+const otelScope = "log-analyzer"
+
+// otelSeverity map the levels to the OpenTelemetry severity numbers, the
+// first number of the range of each severity, e.g. 17 to 20 for ERROR.
+// Entries of any other level have an unspecified severity of 0.
+var otelSeverity = map[Level]int{
+	LevelDebug: 5,
+	LevelInfo:  9,
+	LevelWarn:  13,
+	LevelError: 17,
+}
+
+// otelValue is an OpenTelemetry AnyValue holding a string.
+type otelValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otelAttribute struct {
+	Key   string    `json:"key"`
+	Value otelValue `json:"value"`
+}
+
+// otelLogRecord is a log record of the OpenTelemetry log data model
+// in the OTLP/JSON encoding, where 64 bit integers are strings.
+type otelLogRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber,omitempty"`
+	SeverityText   string          `json:"severityText,omitempty"`
+	Body           otelValue       `json:"body"`
+	Attributes     []otelAttribute `json:"attributes,omitempty"`
+}
+
+type otelScopeLogs struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	LogRecords []otelLogRecord `json:"logRecords"`
+}
+
+type otelResourceLogs struct {
+	ScopeLogs []otelScopeLogs `json:"scopeLogs"`
+}
+
+// otelLogs is an OTLP/JSON ExportLogsServiceRequest.
+type otelLogs struct {
+	ResourceLogs []otelResourceLogs `json:"resourceLogs"`
+}
+
+// newOTelLogRecord return the OpenTelemetry log record of the entry, its
+// level name being the severity text in upper case, its message the body and
+// its source and structured data the attributes.
+func newOTelLogRecord(e LogEntry) otelLogRecord {
+	record := otelLogRecord{
+		TimeUnixNano:   strconv.FormatInt(e.time.UnixNano(), 10),
+		SeverityNumber: otelSeverity[e.level],
+		Body:           otelValue{e.message},
+	}
+	switch e.level {
+	case LevelNone:
+	case LevelUnknown:
+		record.SeverityText = strings.ToUpper(e.rawLevel)
+	default:
+		record.SeverityText = strings.ToUpper(e.level.String())
+	}
+	if e.source != "" {
+		record.Attributes = append(record.Attributes, otelAttribute{"source", otelValue{e.source}})
+	}
+	for _, k := range slices.Sorted(maps.Keys(e.data)) {
+		record.Attributes = append(record.Attributes, otelAttribute{k, otelValue{e.data[k]}})
+	}
+	return record
+}
+
+// WriteOTelJSON write the entries to w as OpenTelemetry log records in the
+// OTLP/JSON encoding, a single ExportLogsServiceRequest document on one line
+// as read by the OpenTelemetry Collector, e.g:
+//
+//	{"resourceLogs":[{"scopeLogs":[{"scope":{"name":"log-analyzer"},"logRecords":[
+//	{"timeUnixNano":"1705312496000000000","severityNumber":17,"severityText":"ERROR","body":{"stringValue":"Failed to connect"}}]}]}]}
+func WriteOTelJSON(w io.Writer, entries []LogEntry) error {
+	scope := otelScopeLogs{LogRecords: make([]otelLogRecord, len(entries))}
+	scope.Scope.Name = otelScope
+	for i, e := range entries {
+		scope.LogRecords[i] = newOTelLogRecord(e)
+	}
+	logs := otelLogs{ResourceLogs: []otelResourceLogs{{ScopeLogs: []otelScopeLogs{scope}}}}
+	return json.NewEncoder(w).Encode(logs)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteOTelJSON(t *testing.T) {
+	at := time.Date(2024, 1, 15, 9, 54, 56, 0, time.UTC)
+	var b strings.Builder
+	err := WriteOTelJSON(&b, []LogEntry{
+		{time: at, level: LevelError, rawLevel: "error", message: "Failed to connect"},
+		{time: at.Add(time.Nanosecond), level: LevelWarn, rawLevel: "WARN", source: "api", message: "Memory usage is high", data: map[string]string{"pid": "42"}},
+		{time: at, level: LevelNone, message: "no level"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"severityText":"ERROR"`) {
+		t.Errorf("got %s, want the error severity text", b.String())
+	}
+
+	var logs otelLogs
+	if err := json.Unmarshal([]byte(b.String()), &logs); err != nil {
+		t.Fatal(err)
+	}
+	records := logs.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 3 {
+		t.Fatalf("got %d log records, want 3", len(records))
+	}
+	if r := records[0]; r.SeverityNumber < 17 || r.SeverityNumber > 20 || r.SeverityText != "ERROR" ||
+		r.TimeUnixNano != "1705312496000000000" || r.Body.StringValue != "Failed to connect" {
+		t.Errorf("got %+v, want an ERROR record with a severity number within [17, 20]", r)
+	}
+	if r := records[1]; r.SeverityNumber < 13 || r.SeverityNumber > 16 || r.TimeUnixNano != "1705312496000000001" ||
+		len(r.Attributes) != 2 || r.Attributes[0] != (otelAttribute{"source", otelValue{"api"}}) || r.Attributes[1] != (otelAttribute{"pid", otelValue{"42"}}) {
+		t.Errorf("got %+v, want a WARN record with the source and pid attributes", r)
+	}
+	if r := records[2]; r.SeverityNumber != 0 || r.SeverityText != "" {
+		t.Errorf("got %+v, want an unspecified severity", r)
+	}
+}
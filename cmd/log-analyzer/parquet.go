@@ -0,0 +1,31 @@
+package main
+
+import (
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetEntry is the parquet schema of a log entry.
+type parquetEntry struct {
+	Timestamp int64  `parquet:"timestamp"` // unix nano
+	Level     string `parquet:"level"`
+	Message   string `parquet:"message"`
+}
+
+// WriteParquet write the entries to w as an Apache Parquet file with
+// the columns timestamp (INT64 unix nano), level and message (BYTE_ARRAY).
+func WriteParquet(w io.Writer, entries []LogEntry) error {
+	pw := parquet.NewGenericWriter[parquetEntry](w)
+	rows := make([]parquetEntry, 0, min(len(entries), batchSize))
+	for i, e := range entries {
+		rows = append(rows, parquetEntry{Timestamp: e.time.UnixNano(), Level: e.levelToken(), Message: e.message})
+		if len(rows) == cap(rows) || i == len(entries)-1 {
+			if _, err := pw.Write(rows); err != nil {
+				return err
+			}
+			rows = rows[:0]
+		}
+	}
+	return pw.Close()
+}
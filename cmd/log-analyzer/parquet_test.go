@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestWriteParquet(t *testing.T) {
+	entries := generateEntries(3000, 0)
+	var b bytes.Buffer
+	if err := WriteParquet(&b, entries); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatalf("got a file starting with %q and ending with %q, want the PAR1 magic", data[:min(4, len(data))], data[max(len(data)-4, 0):])
+	}
+	rows, err := parquet.Read[parquetEntry](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(entries) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(entries))
+	}
+	for i, row := range rows {
+		e := entries[i]
+		if row.Timestamp != e.time.UnixNano() || row.Level != e.levelToken() || row.Message != e.message {
+			t.Fatalf("row %d: got %+v, want %+v", i, row, e)
+		}
+	}
+}
+
+func TestWriteParquetEmpty(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteParquet(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(b.Bytes(), []byte("PAR1")) {
+		t.Errorf("got %q, want a parquet file without rows", b.Bytes())
+	}
+}
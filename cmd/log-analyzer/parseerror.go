@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ParseReason is the kind of a parse failure.
+type ParseReason int
+
+const (
+	// TooFewFields is the reason of lines missing the timestamp, level or message.
+	TooFewFields ParseReason = iota + 1
+	// BadTimestamp is the reason of lines whose timestamp can not be parsed.
+	BadTimestamp
+	// Malformed is the reason of lines not following the input format.
+	Malformed
+)
+
+var (
+	ErrTooFewFields = errors.New("too few fields")
+	ErrBadTimestamp = errors.New("bad timestamp")
+	ErrMalformed    = errors.New("malformed line")
+)
+
+func (r ParseReason) String() string {
+	switch r {
+	case TooFewFields:
+		return "too few fields"
+	case BadTimestamp:
+		return "bad timestamp"
+	case Malformed:
+		return "malformed line"
+	default:
+		return fmt.Sprintf("ParseReason(%d)", int(r))
+	}
+}
+
+// ParseError is the error returned for a line which can not be parsed.
+// It matches the sentinel error of its reason with errors.Is,
+// e.g. errors.Is(err, ErrBadTimestamp).
+type ParseError struct {
+	Line   int // line number starting at 1, zero when unknown
+	Raw    string
+	Reason ParseReason
+	Err    error // underlying error, if any
+}
+
+func (e *ParseError) Error() string {
+	msg := e.Reason.String()
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, msg)
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParseError) Is(target error) bool {
+	switch e.Reason {
+	case TooFewFields:
+		return target == ErrTooFewFields
+	case BadTimestamp:
+		return target == ErrBadTimestamp
+	case Malformed:
+		return target == ErrMalformed
+	}
+	return false
+}
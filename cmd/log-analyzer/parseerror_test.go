@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	for _, tt := range []struct {
+		line   string
+		reason ParseReason
+		target error
+		other  error
+	}{
+		{"2025-01-01 10:00:00", TooFewFields, ErrTooFewFields, ErrBadTimestamp},
+		{"2025-13-01 10:00:00 INFO Request processed", BadTimestamp, ErrBadTimestamp, ErrTooFewFields},
+		{"yesterday at noon INFO Request processed", BadTimestamp, ErrBadTimestamp, ErrTooFewFields},
+	} {
+		_, err := NewLogEntry(tt.line)
+		var perr *ParseError
+		if !errors.As(fmt.Errorf("reading: %w", err), &perr) {
+			t.Fatalf("NewLogEntry(%q): got %v, want a *ParseError", tt.line, err)
+		}
+		if perr.Reason != tt.reason || perr.Raw != tt.line {
+			t.Errorf("NewLogEntry(%q): got the reason %v of %q, want %v", tt.line, perr.Reason, perr.Raw, tt.reason)
+		}
+		if !errors.Is(err, tt.target) || errors.Is(err, tt.other) {
+			t.Errorf("NewLogEntry(%q): got %v, want it to match only %v", tt.line, err, tt.target)
+		}
+	}
+
+	err := &ParseError{Line: 3, Reason: BadTimestamp, Err: errors.New("month out of range")}
+	if got, want := err.Error(), "line 3: bad timestamp: month out of range"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := (&ParseError{Reason: TooFewFields}).Error(), "too few fields"; got != want {
+		t.Errorf("got %q without a line, want %q", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime/pprof"
+	"time"
+)
+
+// ParseStats is the outcome of parsing lines without analyzing the entries,
+// isolating the cost of the parser from the cost of the aggregation.
+type ParseStats struct {
+	Parsed   int           // lines parsed into an entry
+	Invalid  int           // lines which could not be parsed
+	Duration time.Duration // time spent reading and parsing
+}
+
+// Lines return the number of lines parsed, valid or not.
+func (s ParseStats) Lines() int {
+	return s.Parsed + s.Invalid
+}
+
+// LinesPerSecond return the parsing throughput, zero when no time was spent.
+func (s ParseStats) LinesPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Lines()) / s.Duration.Seconds()
+}
+
+// Fprint write the parse statistics to w.
+func (s ParseStats) Fprint(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Parsed Lines: %d\nInvalid Lines: %d\nParse Time: %s\nLines/s: %.0f\n",
+		s.Parsed, s.Invalid, s.Duration.Round(time.Microsecond), s.LinesPerSecond())
+	return err
+}
+
+// ParseOnly parse the lines of r with p, as the analysis does, but
+// without adding the entries to any report.
+func ParseOnly(r io.Reader, p Parser) (ParseStats, error) {
+	var s ParseStats
+	start := time.Now()
+	for _, err := range Entries(r, p) {
+		var perr *ParseError
+		switch {
+		case err == nil:
+			s.Parsed++
+		case errors.As(err, &perr):
+			s.Invalid++
+		default:
+			s.Duration = time.Since(start)
+			return s, err
+		}
+	}
+	s.Duration = time.Since(start)
+	return s, nil
+}
+
+// startCPUProfile write a pprof CPU profile to the file at path until the
+// returned function is called, e.g. go tool pprof log-analyzer cpu.prof.
+func startCPUProfile(path string) (stop func(), err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		if err := f.Close(); err != nil {
+			log.Println("failed to write cpu profile: ", err)
+		}
+	}, nil
+}
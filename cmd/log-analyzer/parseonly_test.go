@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOnly(t *testing.T) {
+	text := logText(generateEntries(1000, 0)) + "not a log line\ntruncated\n"
+	stats, err := ParseOnly(strings.NewReader(text), LineParser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Parsed != 1000 || stats.Invalid != 2 || stats.Lines() != 1002 {
+		t.Errorf("got %d lines parsed and %d invalid, want 1000 and 2", stats.Parsed, stats.Invalid)
+	}
+	if stats.Duration <= 0 || stats.LinesPerSecond() <= 0 {
+		t.Errorf("got a parse time of %s, %g lines/s, want a positive one", stats.Duration, stats.LinesPerSecond())
+	}
+	if got := (ParseStats{Parsed: 10}).LinesPerSecond(); got != 0 {
+		t.Errorf("got %g lines/s without duration, want 0", got)
+	}
+}
+
+// The -parse-only run print the parse statistics rather than the report,
+// writing the -cpuprofile as well.
+func TestParseOnlyFlag(t *testing.T) {
+	path := writeLines(t, "app.log", strings.Split(strings.TrimSuffix(logText(generateEntries(500, 0)), "\n"), "\n")...)
+	profile := filepath.Join(t.TempDir(), "cpu.prof")
+	stdout, stderr, status := runMain(t, "-parse-only", "-cpuprofile", profile, path)
+	if status != 0 {
+		t.Fatalf("got %q, exit %d", stderr, status)
+	}
+	if !strings.HasPrefix(stdout, "Parsed Lines: 500\nInvalid Lines: 0\nParse Time: ") || strings.Contains(stdout, "Total Log Entries") {
+		t.Errorf("got %q, want the parse statistics of the 500 lines", stdout)
+	}
+	if fi, err := os.Stat(profile); err != nil || fi.Size() == 0 {
+		t.Errorf("got the profile %v, %v, want it written", fi, err)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InputAuto is the -input trying every registered parser in
+// turn, see RegistryParser.
+const InputAuto = "auto"
+
+// InputNoLevel is the -input of the lines without a level token, the
+// same as -no-level, see NewLogEntryNoLevel.
+const InputNoLevel = "no-level"
+
+// DefaultParser parse the lines of the default 'timestamp level message'
+// format, as NewLogEntry.
+type DefaultParser struct{}
+
+func (DefaultParser) Parse(line string) (LogEntry, error) {
+	return NewLogEntry(line)
+}
+
+// JSONParser parse the lines holding a json object, e.g:
+//
+//	{"time":"2025-01-01T10:00:00Z","level":"error","msg":"Connection lost","host":"web-1"}
+//
+// See structuredEntry for the keys of the time, level and message, the
+// other keys being the structured data of the entry.
+type JSONParser struct{}
+
+func (JSONParser) Parse(line string) (LogEntry, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return LogEntry{}, &ParseError{Raw: line, Reason: Malformed, Err: err}
+	}
+	fields := make(map[string]string, len(raw))
+	for k, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			s = string(v) // a number, boolean, array or object as is
+		}
+		fields[k] = s
+	}
+	return structuredEntry(line, fields)
+}
+
+// LogfmtParser parse the lines of key=value pairs separated by spaces,
+// a value being double quoted when it has spaces, e.g:
+//
+//	time=2025-01-01T10:00:00Z level=error msg="Connection lost" host=web-1
+//
+// See structuredEntry for the keys of the time, level and message, the
+// other keys being the structured data of the entry.
+type LogfmtParser struct{}
+
+func (LogfmtParser) Parse(line string) (LogEntry, error) {
+	pairs, err := splitQuoted(line, " ")
+	if err != nil {
+		return LogEntry{}, &ParseError{Raw: line, Reason: Malformed, Err: err}
+	}
+	fields := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return LogEntry{}, &ParseError{Raw: line, Reason: Malformed, Err: fmt.Errorf("invalid pair %q", pair)}
+		}
+		fields[k] = v
+	}
+	return structuredEntry(line, fields)
+}
+
+// structuredEntry return the entry of the fields of a structured line,
+// its time being the field time, timestamp or ts, its level the field
+// level, lvl or severity, the entry being of LevelNone without any, and
+// its message the field msg or message. The remaining fields are the
+// structured data of the entry.
+func structuredEntry(line string, fields map[string]string) (LogEntry, error) {
+	entry := LogEntry{level: LevelNone}
+	var ts string
+	for k, v := range fields {
+		switch strings.ToLower(k) {
+		case "time", "timestamp", "ts":
+			ts = v
+		case "level", "lvl", "severity":
+			entry.level, _ = ParseLevel(v)
+			entry.rawLevel = v
+		case "msg", "message":
+			entry.message = v
+		default:
+			if entry.data == nil {
+				entry.data = make(map[string]string)
+			}
+			entry.data[k] = v
+		}
+	}
+	if ts == "" {
+		return LogEntry{}, &ParseError{Raw: line, Reason: TooFewFields}
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		t, err = parseTime(ts)
+	}
+	if err == nil {
+		t, err = checkTime(t)
+	}
+	if err != nil {
+		return LogEntry{}, &ParseError{Raw: line, Reason: BadTimestamp, Err: err}
+	}
+	entry.time = t
+	return entry, nil
+}
+
+// registry hold the parsers registered by name, in registration order.
+var registry = struct {
+	sync.RWMutex
+	names   []string
+	parsers map[string]Parser
+}{parsers: make(map[string]Parser)}
+
+func init() {
+	RegisterParser("default", DefaultParser{})
+	RegisterParser(InputRFC5424, ParserFunc(ParseRFC5424))
+	RegisterParser("json", JSONParser{})
+	RegisterParser("logfmt", LogfmtParser{})
+	RegisterParser(InputNoLevel, ParserFunc(NewLogEntryNoLevel))
+	RegisterParser(InputDelimited, DelimitedParser{Delimiter: ","})
+}
+
+// RegisterParser make the parser p available as the -input name, and to
+// RegistryParser. It panics if p is nil or name is already registered, so
+// library users register their parsers once, e.g. in an init function.
+func RegisterParser(name string, p Parser) {
+	registry.Lock()
+	defer registry.Unlock()
+	if p == nil {
+		panic("log-analyzer: RegisterParser parser is nil")
+	}
+	if _, dup := registry.parsers[name]; dup || name == InputAuto {
+		panic("log-analyzer: RegisterParser called twice for parser " + name)
+	}
+	registry.names = append(registry.names, name)
+	registry.parsers[name] = p
+}
+
+// LookupParser return the parser registered as name.
+func LookupParser(name string) (Parser, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	p, ok := registry.parsers[name]
+	return p, ok
+}
+
+// Parsers return the names of the registered parsers in registration order.
+func Parsers() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]string(nil), registry.names...)
+}
+
+// RegistryParser try the parsers registered as Names in order, or every
+// registered parser when Names is empty, returning the entry of the first
+// parsing the line. When none does the error of the first one is returned.
+type RegistryParser struct {
+	Names []string
+}
+
+func (p RegistryParser) Parse(line string) (LogEntry, error) {
+	names := p.Names
+	if len(names) == 0 {
+		names = Parsers()
+	}
+	var first error
+	for _, name := range names {
+		parser, ok := LookupParser(name)
+		if !ok {
+			return LogEntry{}, fmt.Errorf("unknown parser %q", name)
+		}
+		entry, err := parser.Parse(line)
+		if err == nil {
+			return entry, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	if first == nil {
+		first = &ParseError{Raw: line, Reason: Malformed, Err: fmt.Errorf("no parser registered")}
+	}
+	return LogEntry{}, first
+}
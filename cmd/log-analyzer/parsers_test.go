@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStructuredParsers(t *testing.T) {
+	at := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name   string
+		parser Parser
+		line   string
+	}{
+		{"json", JSONParser{}, `{"time":"2025-01-01T10:00:00Z","level":"ERROR","msg":"Connection lost","host":"web-1","retries":3}`},
+		{"logfmt", LogfmtParser{}, `ts="2025-01-01 10:00:00" level=ERROR msg="Connection lost" host=web-1 retries=3`},
+	} {
+		entry, err := tt.parser.Parse(tt.line)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		host, _ := entry.Field("host")
+		retries, _ := entry.Field("retries")
+		if !entry.time.Equal(at) || entry.level != LevelError || entry.rawLevel != "ERROR" || entry.message != "Connection lost" || host != "web-1" || retries != "3" {
+			t.Errorf("%s: got %+v", tt.name, entry)
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		parser Parser
+		line   string
+		want   error
+	}{
+		{"json not an object", JSONParser{}, `2025-01-01 10:00:00 INFO Started`, ErrMalformed},
+		{"json without time", JSONParser{}, `{"msg":"Started"}`, ErrTooFewFields},
+		{"logfmt bad time", LogfmtParser{}, `time=yesterday msg=Started`, ErrBadTimestamp},
+		{"logfmt unterminated", LogfmtParser{}, `time=2025-01-01T10:00:00Z msg="Started`, ErrMalformed},
+	} {
+		if _, err := tt.parser.Parse(tt.line); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// upperParser parse the lines as the default format, upper casing the message.
+type upperParser struct{}
+
+func (upperParser) Parse(line string) (LogEntry, error) {
+	rest, ok := strings.CutPrefix(line, "UPPER ")
+	if !ok {
+		return LogEntry{}, &ParseError{Raw: line, Reason: Malformed}
+	}
+	entry, err := NewLogEntry(rest)
+	entry.message = strings.ToUpper(entry.message)
+	return entry, err
+}
+
+func TestRegistryParser(t *testing.T) {
+	// Registered once, the test being run again with -count.
+	if _, ok := LookupParser("test-upper"); !ok {
+		RegisterParser("test-upper", upperParser{})
+	}
+	if _, ok := LookupParser("test-upper"); !ok {
+		t.Fatal("got no registered parser")
+	}
+	p := RegistryParser{}
+	for line, want := range map[string]string{
+		"2025-01-01 10:00:00 INFO Started":                            "Started",
+		`{"time":"2025-01-01T10:00:00Z","level":"info","msg":"Json"}`: "Json",
+		"time=2025-01-01T10:00:00Z level=info msg=Logfmt":             "Logfmt",
+		"UPPER 2025-01-01 10:00:00 INFO Started":                      "STARTED",
+	} {
+		entry, err := p.Parse(line)
+		if err != nil || entry.message != want {
+			t.Errorf("%q: got %q, %v, want %q", line, entry.message, err, want)
+		}
+	}
+	if _, err := p.Parse("garbage"); !errors.Is(err, ErrTooFewFields) {
+		t.Errorf("got %v, want the error of the default parser", err)
+	}
+	if _, err := (RegistryParser{Names: []string{"json"}}).Parse("2025-01-01 10:00:00 INFO Started"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("got %v, want only the json parser tried", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("got no panic registering a parser twice")
+		}
+	}()
+	RegisterParser("json", JSONParser{})
+}
+
+func TestInputJSONFlag(t *testing.T) {
+	path := writeLines(t, "app.log",
+		`{"time":"2025-01-01T10:00:00Z","level":"info","msg":"Started"}`,
+		`{"time":"2025-01-01T10:00:01Z","level":"error","msg":"Connection lost"}`)
+	stdout, stderr, status := runMain(t, "-input", "json", "-level", "info,error", "-format", "json", path)
+	if status != 0 || !strings.Contains(stdout, `"total_entries":2,"info":1,"warn":0,"error":1`) {
+		t.Errorf("got %q, %q, exit %d", stdout, stderr, status)
+	}
+	if _, stderr, status := runMain(t, "-input", "xml", path); status == 0 || !strings.Contains(stderr, "invalid input format: xml") {
+		t.Errorf("got %q, exit %d, want the unknown input rejected", stderr, status)
+	}
+}
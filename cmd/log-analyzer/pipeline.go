@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// batchSize is the number of lines handed to a parser worker at once.
+const batchSize = 1024
+
+var ErrNoEntries = errors.New("no log entries found")
+
+// InterruptError is returned when the analysis is cancelled before
+// the whole input was read. Line is the number of input lines analyzed
+// so far, including the lines skipped before parsing, the report returned
+// along with it cover exactly these lines.
+type InterruptError struct {
+	Line int
+	Err  error
+}
+
+func (e *InterruptError) Error() string {
+	return fmt.Sprintf("analysis interrupted at line %d", e.Line)
+}
+
+func (e *InterruptError) Unwrap() error {
+	return e.Err
+}
+
+// invalidRatioMinLines is the number of lines to analyze before
+// checking the ratio of invalid lines, so a few invalid lines at
+// the start of the input do not abort the analysis.
+const invalidRatioMinLines = 1000
+
+// InvalidInputError is returned when the analysis is aborted as too many
+// lines can not be parsed, the input likely not being in the expected
+// format. The report returned along with it must not be trusted.
+type InvalidInputError struct {
+	Invalid int   // invalid lines
+	Total   int   // lines analyzed
+	Err     error // parse error of the line aborting the analysis
+}
+
+func (e *InvalidInputError) Error() string {
+	return fmt.Sprintf("analysis aborted, %d of %d lines are invalid: %v", e.Invalid, e.Total, e.Err)
+}
+
+func (e *InvalidInputError) Unwrap() error {
+	return e.Err
+}
+
+// StoppedError is returned when the analysis stopped early after
+// Errors invalid lines, see WithMaxErrors. Unlike an *InvalidInputError
+// the report returned along with it is valid, covering the lines up to Line.
+type StoppedError struct {
+	Line   int `json:"line"`
+	Errors int `json:"errors"`
+}
+
+func (e *StoppedError) Error() string {
+	return fmt.Sprintf("analysis stopped early at line %d after %d parse errors", e.Line, e.Errors)
+}
+
+// invalidCheck hand the invalid lines to the handler and stop the analysis
+// when too many of them are invalid, see WithInvalidLineHandler, WithStrict,
+// WithMaxInvalidRatio and WithMaxErrors.
+type invalidCheck struct {
+	strict    bool
+	ratio     float64
+	maxErrors int
+	handler   InvalidLineHandler // ReportInvalidLine if nil
+	header    bool               // skip the invalid lines until a valid one
+	invalid   int
+	total     int
+}
+
+// inHeader report whether the line parsed with err is in the header to skip,
+// the invalid lines before the first valid one, see WithSkipHeader.
+func (c *invalidCheck) inHeader(err error) bool {
+	if c.header && err != nil {
+		return true
+	}
+	c.header = false
+	return false
+}
+
+// line record a line parsed with err, returning the error of the handler,
+// an *InvalidInputError once the invalid lines are beyond the limit or a
+// *StoppedError after the maximum number of invalid lines.
+func (c *invalidCheck) line(err error) error {
+	c.total++
+	if err == nil {
+		return nil
+	}
+	c.invalid++
+	handler := c.handler
+	if handler == nil {
+		handler = ReportInvalidLine
+	}
+	lineNo, raw := c.total, ""
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		raw = perr.Raw
+		if perr.Line > 0 {
+			lineNo = perr.Line
+		}
+	}
+	if herr := handler(lineNo, raw, err); herr != nil {
+		return herr
+	}
+	if c.strict || (c.ratio > 0 && c.total >= invalidRatioMinLines && float64(c.invalid)/float64(c.total) > c.ratio) {
+		return &InvalidInputError{Invalid: c.invalid, Total: c.total, Err: err}
+	}
+	if c.maxErrors > 0 && c.invalid >= c.maxErrors {
+		return &StoppedError{Line: lineNo, Errors: c.invalid}
+	}
+	return nil
+}
+
+// batch is a group of raw lines tagged with its position in the input,
+// so the aggregator can restore the original order after parsing.
+type batch struct {
+	seq     int
+	first   int // line number of the first line
+	lines   []string
+	entries []LogEntry
+	errs    []error
+}
+
+// AnalyzeReader analyze logs read from r and return the analysis report.
+// It is the same as AnalyzeContext with a background context.
+func AnalyzeReader(r io.Reader, opts ...Option) (*AnalysisReport, error) {
+	return AnalyzeContext(context.Background(), r, opts...)
+}
+
+// AnalyzeContext analyze logs read from r and return the analysis report.
+// A reader goroutine splits the input into line batches, a pool of workers
+// parse them into entries and a single aggregator applies the filter and
+// adds the entries to the report in input order, so the result is the same
+// regardless of the number of workers, and the same as the serial parsing
+// of AnalyzeSource even for the statistics depending on the order of the
+// entries. As time.Parse dominates the parsing, the workers speed up the
+// analysis of a single large file on multicore machines, see
+// BenchmarkAnalyzeReader. The options are the same as Analyze.
+//
+// The context is checked between batches, once it is done the partial
+// report is returned along with an *InterruptError.
+func AnalyzeContext(ctx context.Context, r io.Reader, opts ...Option) (*AnalysisReport, error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	workers := o.workers
+
+	// Stop the reader and the workers when returning early, waiting for
+	// the reader, which must not read r once the caller closes it, and
+	// the workers.
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	lines := make(chan *batch, workers)
+	parsed := make(chan *batch, workers)
+
+	var readErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(lines)
+		done := ctx.Done()
+		b := &batch{first: 1, lines: make([]string, 0, batchSize)}
+		for line, err := range scanLines(r) {
+			if err != nil {
+				readErr = err
+				break
+			}
+			select {
+			case <-done:
+				return
+			default:
+			}
+			b.lines = append(b.lines, line)
+			if len(b.lines) == batchSize {
+				select {
+				case lines <- b:
+				case <-ctx.Done():
+					return
+				}
+				b = &batch{seq: b.seq + 1, first: b.first + batchSize, lines: make([]string, 0, batchSize)}
+			}
+		}
+		if len(b.lines) > 0 {
+			select {
+			case lines <- b:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var b *batch
+				select {
+				case b = <-lines:
+				case <-ctx.Done():
+					return
+				}
+				if b == nil {
+					return // the whole input is read
+				}
+				b.entries = make([]LogEntry, 0, len(b.lines))
+				b.errs = make([]error, 0, len(b.lines))
+				for i, line := range b.lines {
+					if o.lines.skip(line) {
+						continue
+					}
+					entry, err := o.lines.parse(b.first+i, line)
+					b.entries = append(b.entries, entry)
+					b.errs = append(b.errs, err)
+				}
+				select {
+				case parsed <- b:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(parsed)
+	}()
+
+	report := o.newReport()
+	defer report.finish()
+	check := o.newCheck()
+	// line is the input line number of the last line aggregated,
+	// total the number of lines parsed.
+	var line, total int
+	next, pending := 0, make(map[int]*batch)
+	for b := range parsed {
+		if err := ctx.Err(); err != nil {
+			return report, &InterruptError{Line: line, Err: err}
+		}
+		pending[b.seq] = b
+		for b, ok := pending[next]; ok; b, ok = pending[next] {
+			delete(pending, next)
+			next++
+			for i, entry := range b.entries {
+				total++
+				if check.inHeader(b.errs[i]) {
+					continue
+				}
+				report.TotalLines++
+				if report.full() {
+					continue
+				}
+				if b.errs[i] != nil {
+					report.skipLine(b.errs[i])
+				}
+				if err := check.line(b.errs[i]); err != nil {
+					return report, report.stop(err)
+				}
+				if b.errs[i] != nil {
+					continue
+				}
+				if f, ok := skippedBy(entry, o.filters); ok {
+					report.filtered(f)
+					continue
+				}
+				report.Add(entry)
+			}
+			line = b.first + len(b.lines) - 1
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, &InterruptError{Line: line, Err: err}
+	}
+	if readErr != nil {
+		return report, readErr
+	}
+	if total == 0 {
+		return report, ErrNoEntries
+	}
+	return report, nil
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// reportJSON return the json of the report, comparing every exported field.
+func reportJSON(t *testing.T, r *AnalysisReport) string {
+	t.Helper()
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// The concurrent parsing of a single input gives the report of the serial
+// parsing, the entries being added in input order whatever the workers.
+func TestAnalyzeContextWorkers(t *testing.T) {
+	// The entries shuffled, so out of order with duplicates, are
+	// followed by batches with invalid lines.
+	log := logText(shuffledEntries(20*batchSize+17)) + invalidLog(2*batchSize, 97)
+	opts := []Option{WithTopN(5), WithPercentiles(50, 99), WithInterval(time.Hour), WithInvalidLineHandler(DiscardInvalidLine)}
+	serial, err := AnalyzeSource(NewReader(strings.NewReader(log)), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := reportJSON(t, serial)
+	if serial.SkippedLines == 0 || serial.TotalEntries < 20*batchSize {
+		t.Fatalf("got %d entries and %d skipped lines, want a log of many batches with invalid lines", serial.TotalEntries, serial.SkippedLines)
+	}
+	for _, workers := range []int{1, 2, 4, 8} {
+		report, err := AnalyzeReader(strings.NewReader(log), append(opts, WithWorkers(workers))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := reportJSON(t, report); got != want {
+			t.Errorf("with %d workers: got a report differing from the serial one\n%s\nwant\n%s", workers, got, want)
+		}
+	}
+}
+
+// countingParser return a parser of the default format counting in n
+// the lines parsed.
+func countingParser(n *atomic.Int64) Parser {
+	return ParserFunc(func(line string) (LogEntry, error) {
+		n.Add(1)
+		return NewLogEntry(line)
+	})
+}
+
+// noisyLog return a log of n lines, 9 of 10 being health checks.
+func noisyLog(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if i%10 == 0 {
+			fmt.Fprintf(&b, "2025-01-01 10:00:%02d INFO Request processed in %d ms\n", i%60, i%500)
+		} else {
+			fmt.Fprintf(&b, "2025-01-01 10:00:%02d DEBUG healthcheck ok\n", i%60)
+		}
+	}
+	return b.String()
+}
+
+// The lines matching the Skip pattern never reach the parser.
+func TestSkipMatching(t *testing.T) {
+	var parses atomic.Int64
+	p := LineParser{Parser: countingParser(&parses), Skip: regexp.MustCompile(`healthcheck`)}
+	report, err := AnalyzeReader(strings.NewReader(noisyLog(10*batchSize)), WithWorkers(4), WithLineParser(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parses.Load() != batchSize || report.TotalLines != batchSize || report.Debug != 0 || report.Info != batchSize {
+		t.Errorf("got %d lines parsed, %d analyzed, %d debug, want the %d requests alone", parses.Load(), report.TotalLines, report.Debug, batchSize)
+	}
+}
+
+// endlessLog is a log of lines which never ends, cancelling the analysis
+// once n lines are read. Every other line is a comment.
+type endlessLog struct {
+	n, read int
+	cancel  context.CancelFunc
+	pending []byte
+}
+
+func (l *endlessLog) Read(p []byte) (int, error) {
+	if len(l.pending) == 0 {
+		l.read++
+		if l.read == l.n {
+			l.cancel()
+		}
+		if l.read%2 == 0 {
+			l.pending = []byte("# comment\n")
+		} else {
+			l.pending = []byte("2025-01-01 10:00:00 INFO Request processed in 10 ms\n")
+		}
+	}
+	n := copy(p, l.pending)
+	l.pending = l.pending[n:]
+	return n, nil
+}
+
+// The cancelled analysis returns promptly the report of the whole batches
+// aggregated before, the line of the interruption counting the lines
+// skipped before parsing.
+func TestAnalyzeContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const n = 100 * batchSize
+	var (
+		report *AnalysisReport
+		err    error
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		report, err = AnalyzeContext(ctx, &endlessLog{n: n, cancel: cancel}, WithWorkers(1), WithLineParser(LineParser{Skip: regexp.MustCompile(`^#`)}))
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the analysis did not return once cancelled")
+	}
+
+	var ierr *InterruptError
+	if !errors.As(err, &ierr) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want an *InterruptError of the cancellation", err)
+	}
+	// The batches read ahead of the aggregator are at most those in the
+	// channels and held by the reader and the worker.
+	if ierr.Line%batchSize != 0 || ierr.Line > n || ierr.Line < n-8*batchSize {
+		t.Errorf("got the interruption at line %d, want the end of a batch of the %d lines read", ierr.Line, n)
+	}
+	if report.TotalLines != ierr.Line/2 || report.TotalEntries != report.TotalLines || report.Info != report.TotalEntries {
+		t.Errorf("got %d lines, %d entries, want the %d entries of the %d lines before the interruption", report.TotalLines, report.TotalEntries, ierr.Line/2, ierr.Line)
+	}
+}
+
+// The analysis stops at the line of the -max-errors-th parse error with
+// the report of the lines before it, the command exiting with the status
+// of an invalid input after printing it.
+func TestMaxErrors(t *testing.T) {
+	lines := []string{
+		"2025-01-01 10:00:00 INFO Starting the application",
+		"2025-01-01 10:00:01 WARN Memory usage is high",
+		"not a log line",
+		"2025-01-01 10:00:02 ERROR Connection lost",
+		"not a log line",
+		"not a log line",
+		"2025-01-01 10:00:03 ERROR Connection lost",
+		"not a log line",
+	}
+	text := strings.Join(lines, "\n") + "\n"
+	for _, workers := range []int{1, 4} {
+		report, err := AnalyzeReader(strings.NewReader(text), WithWorkers(workers), WithMaxErrors(3), WithInvalidLineHandler(DiscardInvalidLine))
+		var stopped *StoppedError
+		if !errors.As(err, &stopped) || *stopped != (StoppedError{Line: 6, Errors: 3}) {
+			t.Fatalf("workers=%d: got %v, want to stop at line 6 after 3 errors", workers, err)
+		}
+		if report.TotalEntries != 3 || report.Stopped == nil || *report.Stopped != *stopped {
+			t.Errorf("workers=%d: got %d entries stopped at %+v, want the 3 entries before line 6", workers, report.TotalEntries, report.Stopped)
+		}
+		if _, err := AnalyzeReader(strings.NewReader(text), WithWorkers(workers), WithMaxErrors(5), WithInvalidLineHandler(DiscardInvalidLine)); err != nil {
+			t.Errorf("workers=%d: got %v with 4 errors of 5 at most", workers, err)
+		}
+	}
+
+	path := writeLines(t, "app.log", lines...)
+	const msg = "analysis stopped early at line 6 after 3 parse errors"
+	stdout, stderr, status := runMain(t, "-quiet", "-level", "info,warn,error", "-max-errors", "3", path)
+	if status != exitInvalidInputStatus || !strings.Contains(stderr, msg) {
+		t.Errorf("got the exit status %d and %q, want %d and %q", status, stderr, exitInvalidInputStatus, msg)
+	}
+	if !strings.Contains(stdout, "Total Log Entries: 3\n") || !strings.Contains(stdout, "Analysis stopped early at line 6 after 3 parse errors") {
+		t.Errorf("got the report\n%s\nwant the 3 entries and the early stop", stdout)
+	}
+	stdout, _, status = runMain(t, "-quiet", "-level", "info,warn,error", "-max-errors", "3", "-format", "json", path)
+	var got struct {
+		Stopped *StoppedError `json:"stopped_early"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil || status != exitInvalidInputStatus || got.Stopped == nil || *got.Stopped != (StoppedError{Line: 6, Errors: 3}) {
+		t.Errorf("got the exit status %d and the json report %s (%v), want %d and stopped_early", status, stdout, err, exitInvalidInputStatus)
+	}
+	if _, stderr, status := runMain(t, "-quiet", "-max-errors", "5", path); status != 0 {
+		t.Errorf("got the exit status %d (%s) with 4 errors of 5 at most", status, stderr)
+	}
+}
+
+// closedLog is a log failing the test when read once closed, as a file
+// whose reader outlives the analysis would.
+type closedLog struct {
+	t      *testing.T
+	r      io.Reader
+	closed bool
+}
+
+func (l *closedLog) Read(p []byte) (int, error) {
+	if l.closed {
+		l.t.Error("read the log after it was closed")
+		return 0, os.ErrClosed
+	}
+	return l.r.Read(p)
+}
+
+// The analysis stopping early does not read its input once returned, so
+// the caller can close it, run with -race to check the reader is waited for.
+func TestAnalyzeContextStopped(t *testing.T) {
+	log := invalidLog(100*batchSize, 10)
+	for _, opt := range []Option{WithMaxErrors(1), WithStrict(true)} {
+		for _, workers := range []int{1, 4} {
+			l := &closedLog{t: t, r: strings.NewReader(log)}
+			_, err := AnalyzeContext(context.Background(), l, opt, WithWorkers(workers), WithInvalidLineHandler(DiscardInvalidLine))
+			if err == nil {
+				t.Fatalf("workers=%d: got no error, want to stop at the first invalid line", workers)
+			}
+			l.closed = true
+		}
+	}
+	time.Sleep(10 * time.Millisecond) // for a reader left running to read
+}
+
+func BenchmarkAnalyzeReader(b *testing.B) {
+	log := invalidLog(200_000, 1000)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(log)))
+			for i := 0; i < b.N; i++ {
+				if _, err := AnalyzeReader(strings.NewReader(log), WithWorkers(workers), WithInvalidLineHandler(DiscardInvalidLine)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSkipMatching compare parsing every line of a noisy log with
+// skipping the noise before parsing, reporting the lines parsed per op.
+func BenchmarkSkipMatching(b *testing.B) {
+	log := noisyLog(100_000)
+	for _, skip := range []string{"", "healthcheck"} {
+		name := "parse all"
+		if skip != "" {
+			name = "skip " + skip
+		}
+		b.Run(name, func(b *testing.B) {
+			var parses atomic.Int64
+			p := LineParser{Parser: countingParser(&parses)}
+			if skip != "" {
+				p.Skip = regexp.MustCompile(skip)
+			}
+			b.SetBytes(int64(len(log)))
+			for i := 0; i < b.N; i++ {
+				if _, err := AnalyzeReader(strings.NewReader(log), WithLineParser(p)); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(parses.Load())/float64(b.N), "parses/op")
+		})
+	}
+}
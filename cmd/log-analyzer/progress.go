@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Progress display the progress of reading the input on a terminal,
+// refreshing a single status line every second.
+type Progress struct {
+	w     io.Writer
+	total int64 // zero when the input size is unknown
+	start time.Time
+	bytes atomic.Int64
+	lines atomic.Int64
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewProgress return a progress writing to w for an input of total bytes.
+// A total of zero means the size is unknown, in which case only the bytes
+// and lines read so far are displayed.
+func NewProgress(w io.Writer, total int64) *Progress {
+	return &Progress{w: w, total: total}
+}
+
+// Reader wrap r counting the bytes and lines read through it.
+func (p *Progress) Reader(r io.Reader) io.Reader {
+	return &progressReader{r: r, bytes: &p.bytes, lines: &p.lines}
+}
+
+// ByteReader wrap r counting the bytes read through it alone, e.g. the
+// compressed bytes under a decompressor whose lines are counted by
+// LineReader, so the total is the size of the compressed input.
+func (p *Progress) ByteReader(r io.Reader) io.Reader {
+	return &progressReader{r: r, bytes: &p.bytes}
+}
+
+// LineReader wrap r counting the lines read through it alone.
+func (p *Progress) LineReader(r io.Reader) io.Reader {
+	return &progressReader{r: r, lines: &p.lines}
+}
+
+// Start refreshing the status line until Stop is called.
+func (p *Progress) Start() {
+	p.start = time.Now()
+	p.stop = make(chan struct{})
+	p.done.Add(1)
+	go func() {
+		defer p.done.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprintf(p.w, "\r\033[K%s", p.status())
+			case <-p.stop:
+				fmt.Fprint(p.w, "\r\033[K")
+				return
+			}
+		}
+	}()
+}
+
+// Stop refreshing and clear the status line. It is safe to call
+// on a nil or stopped progress.
+func (p *Progress) Stop() {
+	if p == nil || p.stop == nil {
+		return
+	}
+	close(p.stop)
+	p.done.Wait()
+	p.stop = nil
+}
+
+func (p *Progress) status() string {
+	n, lines := p.bytes.Load(), p.lines.Load()
+	elapsed := time.Since(p.start).Seconds()
+	rate := float64(lines) / elapsed
+	if p.total <= 0 {
+		return fmt.Sprintf("%s, %d lines, %.0f lines/s", formatBytes(n), lines, rate)
+	}
+	pct := float64(n) / float64(p.total) * 100
+	eta := "-"
+	if n > 0 {
+		remaining := time.Duration(float64(p.total-n) / (float64(n) / elapsed) * float64(time.Second))
+		eta = remaining.Round(time.Second).String()
+	}
+	return fmt.Sprintf("%s / %s (%.1f%%), %d lines, %.0f lines/s, ETA %s",
+		formatBytes(n), formatBytes(p.total), pct, lines, rate, eta)
+}
+
+type progressReader struct {
+	r            io.Reader
+	bytes, lines *atomic.Int64 // nil when not counted
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if r.bytes != nil {
+		r.bytes.Add(int64(n))
+	}
+	if r.lines != nil {
+		r.lines.Add(int64(bytes.Count(b[:n], []byte{'\n'})))
+	}
+	return n, err
+}
+
+// isTerminal report whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// formatBytes format n bytes using binary units, e.g. 1.5 GiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
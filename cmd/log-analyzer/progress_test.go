@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:          "0 B",
+		1023:       "1023 B",
+		1024:       "1.0 KiB",
+		1536 << 20: "1.5 GiB",
+		5 << 40:    "5.0 TiB",
+		1<<20 - 1:  "1024.0 KiB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// The status line shows the share of the total read and the time left at
+// the rate so far, or the bytes and lines read alone when the size is unknown.
+func TestProgressStatus(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		total, bytes int64
+		want         string
+	}{
+		{"half", 1 << 20, 512 << 10, "512.0 KiB / 1.0 MiB (50.0%), 1000 lines, 100 lines/s, ETA 10s"},
+		{"nothing read", 1 << 20, 0, "0 B / 1.0 MiB (0.0%), 1000 lines, 100 lines/s, ETA -"},
+		{"unknown size", 0, 512 << 10, "512.0 KiB, 1000 lines, 100 lines/s"},
+	} {
+		p := NewProgress(io.Discard, tt.total)
+		p.start = time.Now().Add(-10 * time.Second)
+		p.bytes.Store(tt.bytes)
+		p.lines.Store(1000)
+		if got := p.status(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	const text = "line 1\nline 2\nline 3\n"
+	p := NewProgress(io.Discard, 0)
+	if _, err := io.Copy(io.Discard, p.Reader(strings.NewReader(text))); err != nil {
+		t.Fatal(err)
+	}
+	if p.bytes.Load() != int64(len(text)) || p.lines.Load() != 3 {
+		t.Errorf("got %d bytes and %d lines, want %d and 3", p.bytes.Load(), p.lines.Load(), len(text))
+	}
+
+	var out bytes.Buffer
+	p = NewProgress(&out, 0)
+	p.Start()
+	p.Stop()
+	p.Stop()
+	if out.String() != "\r\033[K" {
+		t.Errorf("got %q once stopped, want the status line cleared", out.String())
+	}
+	(*Progress)(nil).Stop()
+}
+
+// The progress of the rotations counts the bytes read from the files, so
+// the compressed ones, and the lines decompressed.
+func TestProgressRotated(t *testing.T) {
+	files := writeRotations(t, t.TempDir())
+	var size int64
+	for _, name := range files {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size += fi.Size()
+	}
+	p := NewProgress(io.Discard, size)
+	rr := &rotatedReader{files: files, wrap: p.ByteReader}
+	defer rr.Close()
+	got, err := io.ReadAll(p.LineReader(rr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.bytes.Load() != size || int64(len(got)) <= size {
+		t.Errorf("got %d bytes counted of %d decompressed, want the %d bytes of the files", p.bytes.Load(), len(got), size)
+	}
+	if p.lines.Load() != 60 {
+		t.Errorf("got %d lines, want the 60 entries of the rotations", p.lines.Load())
+	}
+}
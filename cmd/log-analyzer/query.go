@@ -0,0 +1,62 @@
+package main
+
+import "time"
+
+// TopMessages return the n most frequent messages, the messages with the
+// same frequency ordered alphabetically. Unlike the Top field it is not
+// limited to the number of messages given to WithTopN.
+func (r *AnalysisReport) TopMessages(n int) []MessageCount {
+	return topMessages(r.MsgFrequency, n)
+}
+
+// LevelCount return the number of entries of the level, parsed with
+// ParseLevel, or 0 for a level which is not counted.
+func (r *AnalysisReport) LevelCount(level string) int {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return 0
+	}
+	return r.count(l)
+}
+
+// count return the number of entries of the level.
+func (r *AnalysisReport) count(level Level) int {
+	switch level {
+	case LevelInfo:
+		return r.Info
+	case LevelWarn:
+		return r.Warn
+	case LevelError:
+		return r.Error
+	case LevelDebug:
+		return r.Debug
+	case LevelNone:
+		return r.None
+	}
+	return 0
+}
+
+// ResponseTimePercentile return the p percentile of the response times in
+// ms, p within [0, 100], or false if no entry had a response time. It is
+// the same as Quantile(p / 100).
+func (r *AnalysisReport) ResponseTimePercentile(p float64) (float64, bool) {
+	return r.Quantile(p / 100)
+}
+
+// ErrorRate return the fraction of the entries which are errors,
+// within [0, 1], or 0 for a report without entries.
+func (r *AnalysisReport) ErrorRate() float64 {
+	if r.TotalEntries == 0 {
+		return 0
+	}
+	return float64(r.Error) / float64(r.TotalEntries)
+}
+
+// TimeRange return the time of the first and last entries,
+// or false for a report without entries.
+func (r *AnalysisReport) TimeRange() (start, end time.Time, ok bool) {
+	if r.TotalEntries == 0 || r.FirstEntry.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	return r.FirstEntry, r.LastEntry, true
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// LogSource is a sequence of entries to analyze. Next return io.EOF once
+// there are no more entries, any other error is either a *ParseError for
+// an invalid line, after which the source can be read further, or a
+// failure of the source itself.
+type LogSource interface {
+	Next() (LogEntry, error)
+}
+
+// Reader lazily parse entries from an underlying reader, one line at a
+// time, similar to sql.Rows. It pulls the entries from the Entries
+// sequence, see Close.
+type Reader struct {
+	r    io.Reader
+	p    Parser
+	next func() (LogEntry, error, bool)
+	stop func()
+}
+
+// NewReader return a reader of the entries of r in the default format.
+func NewReader(r io.Reader) *Reader {
+	return NewParserReader(r, LineParser{})
+}
+
+// NewParserReader return a reader of the entries parsed by p from the
+// lines of r, e.g. by a LineParser skipping some of them.
+func NewParserReader(r io.Reader, p Parser) *Reader {
+	rd := &Reader{r: r, p: p}
+	rd.next, rd.stop = iter.Pull2(Entries(r, p))
+	return rd
+}
+
+// Next parse and return the next entry. For an invalid line the entry
+// is returned along with a *ParseError and must not be analyzed.
+func (r *Reader) Next() (LogEntry, error) {
+	entry, err, ok := r.next()
+	if !ok {
+		return LogEntry{}, io.EOF
+	}
+	return entry, err
+}
+
+// Close release the resources of the reader when it is not read to
+// the end, it does not close the underlying reader.
+func (r *Reader) Close() error {
+	r.stop()
+	return nil
+}
+
+// Reset start reading again from the beginning, which is only
+// possible when the underlying reader is an io.Seeker.
+func (r *Reader) Reset() error {
+	seeker, ok := r.r.(io.Seeker)
+	if !ok {
+		return errors.New("reset: reader is not seekable")
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r.stop()
+	r.next, r.stop = iter.Pull2(Entries(r.r, r.p))
+	return nil
+}
+
+// SliceSource is a LogSource over already parsed entries.
+type SliceSource []LogEntry
+
+func (s *SliceSource) Next() (LogEntry, error) {
+	if len(*s) == 0 {
+		return LogEntry{}, io.EOF
+	}
+	entry := (*s)[0]
+	*s = (*s)[1:]
+	return entry, nil
+}
+
+// AnalyzeSource analyze the entries of src, see Analyze for the options.
+// Invalid lines are handed to the InvalidLineHandler and counted, reading
+// stops at the first other error which is returned with the partial report.
+func AnalyzeSource(src LogSource, opts ...Option) (*AnalysisReport, error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	report := o.newReport()
+	defer report.finish()
+	check := o.newCheck()
+	for {
+		entry, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		var perr *ParseError
+		if err != nil && !errors.As(err, &perr) {
+			return report, err
+		}
+		if check.inHeader(err) {
+			continue
+		}
+		report.TotalLines++
+		if report.full() {
+			continue
+		}
+		if err != nil {
+			report.skipLine(err)
+		}
+		if err := check.line(err); err != nil {
+			return report, report.stop(err)
+		}
+		if err != nil {
+			continue
+		}
+		if f, ok := skippedBy(entry, o.filters); ok {
+			report.filtered(f)
+			continue
+		}
+		report.Add(entry)
+	}
+}
+
+// ReadError is the error reading the file Name, Offset being
+// the offset in bytes within the file at which reading failed.
+type ReadError struct {
+	Name   string
+	Offset int64
+	Err    error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("failed to read %s at byte %d: %v", e.Name, e.Offset, e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// fileReader read the content of the file name counting the bytes read,
+// so a failing read is returned as a *ReadError at the offset reached.
+type fileReader struct {
+	r      io.Reader
+	name   string
+	offset int64
+}
+
+// newFileReader return a fileReader of r, the content of the
+// file name from offset, e.g. the Checkpoint Start.
+func newFileReader(name string, r io.Reader, offset int64) *fileReader {
+	return &fileReader{r: r, name: name, offset: offset}
+}
+
+func (fr *fileReader) Read(p []byte) (int, error) {
+	n, err := fr.r.Read(p)
+	fr.offset += int64(n)
+	if err != nil && err != io.EOF {
+		return n, &ReadError{Name: fr.name, Offset: fr.offset, Err: err}
+	}
+	return n, err
+}
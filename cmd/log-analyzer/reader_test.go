@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// readAllEntries return the entries of r up to the end of its input,
+// failing the test on any error.
+func readAllEntries(t *testing.T, r *Reader) []LogEntry {
+	t.Helper()
+	var entries []LogEntry
+	for {
+		entry, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return entries
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func TestReaderReadFile(t *testing.T) {
+	text := logText(generateEntries(200, 0))
+	want, err := ReadFile(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(strings.NewReader(text))
+	got := readAllEntries(t, r)
+	if len(got) != 200 || !EqualSlice(got, want) {
+		t.Fatalf("got %d entries from the Reader, want the %d entries of ReadFile", len(got), len(want))
+	}
+	if err := r.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if again := readAllEntries(t, r); !EqualSlice(again, want) {
+		t.Errorf("got %d entries once reset, want the %d entries again", len(again), len(want))
+	}
+	if err := NewReader(io.MultiReader(strings.NewReader(text))).Reset(); err == nil {
+		t.Error("reset a reader which is not seekable")
+	}
+}
+
+func TestReaderInvalidLine(t *testing.T) {
+	r := NewReader(strings.NewReader("2025-01-01 10:00:00 INFO Started\nnot a log line\n2025-01-01 10:00:01 INFO Stopped\n"))
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := r.Next()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Fatalf("got %v, want a *ParseError of line 2", err)
+	}
+	if entry, err := r.Next(); err != nil || entry.message != "Stopped" {
+		t.Errorf("got %+v, %v, want the line after the invalid one", entry, err)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v at the end, want io.EOF", err)
+	}
+}
+
+func TestAnalyzeSource(t *testing.T) {
+	entries := generateEntries(300, 0)
+	want, err := Analyze(entries, WithTopN(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := SliceSource(entries)
+	got, err := AnalyzeSource(&src, WithTopN(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromReader, err := AnalyzeSource(NewReader(strings.NewReader(logText(entries))), WithTopN(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, _ := json.Marshal(want)
+	for name, r := range map[string]*AnalysisReport{"slice": got, "reader": fromReader} {
+		if b, _ := json.Marshal(r); string(b) != string(w) {
+			t.Errorf("%s: got the report\n%s\nwant the report of Analyze\n%s", name, b, w)
+		}
+	}
+}
+
+// A failing read is returned with the file name and the offset reached,
+// along with the entries read before it.
+func TestReadError(t *testing.T) {
+	text := logText(generateEntries(50, 0))
+	failing := func() io.Reader {
+		r := io.MultiReader(strings.NewReader(text), iotest.ErrReader(errors.New("stale file handle")))
+		return newFileReader("app.log", r, 100)
+	}
+	want := fmt.Sprintf("failed to read app.log at byte %d: stale file handle", 100+len(text))
+	entries, err := ReadFile(failing())
+	var rerr *ReadError
+	if !errors.As(err, &rerr) || err.Error() != want || len(entries) != 50 {
+		t.Errorf("got %d entries and %v, want 50 and %q", len(entries), err, want)
+	}
+	for _, workers := range []int{1, 4} {
+		if _, err := AnalyzeReader(failing(), WithWorkers(workers)); !errors.As(err, &rerr) || rerr.Offset != int64(100+len(text)) {
+			t.Errorf("with %d workers: got %v, want %q", workers, err, want)
+		}
+	}
+}
+
+// The analysis of a file which can not be read fail with its name.
+func TestReadErrorExit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app.log")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{dir}, {"-sort", dir}} {
+		stdout, stderr, status := runMain(t, args...)
+		if want := "failed to read " + dir + " at byte 0"; status == 0 || stdout != "" || !strings.Contains(stderr, want) {
+			t.Errorf("%v: got %q, %q, exit %d, want %q", args, stdout, stderr, status, want)
+		}
+	}
+}
@@ -0,0 +1,765 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// AnalysisReport is the result of the analysis of log entries.
+//
+// A report is not safe for concurrent use: Add and Merge modify it without
+// any lock, so a report must only be added to by one goroutine at a time.
+// Rather than sharing a report behind a mutex, the concurrent analyses give
+// each goroutine its own report and combine them with Merge once done, see
+// ShardedReport. Merge only reads other, which may thus be merged into several
+// reports at once as long as it is not being modified. A report no longer
+// modified, such as the snapshot served by ReportServer, may be read by any
+// number of goroutines.
+type AnalysisReport struct {
+	TotalEntries int            `json:"total_entries"`
+	Info         int            `json:"info"`
+	Warn         int            `json:"warn"`
+	Error        int            `json:"error"`
+	Debug        int            `json:"debug"`
+	None         int            `json:"none,omitempty"`
+	ResponseTime []float64      `json:"response_time_ms,omitempty"` // in ms
+	MsgFrequency map[string]int `json:"message_frequency"`
+	Sources      map[string]int `json:"sources,omitempty"`
+	Top          []MessageCount `json:"top_messages,omitempty"` // see WithTopN
+	Percentiles  []Percentile   `json:"percentiles,omitempty"`
+	Timeline     []TimeBucket   `json:"timeline,omitempty"`
+	FirstEntry   time.Time      `json:"first_entry"`
+	LastEntry    time.Time      `json:"last_entry"`
+	// MaxSameTime is the largest number of entries in a row sharing
+	// a single timestamp, the earliest such timestamp being MaxSameTimeAt.
+	MaxSameTime   int       `json:"max_same_timestamp"`
+	MaxSameTimeAt time.Time `json:"max_same_timestamp_at"`
+	// ApproximateMessages is set when more distinct messages than the
+	// configured bound were seen, MsgFrequency then holds the guaranteed
+	// count of the most frequent ones and OtherMessages the remaining entries.
+	ApproximateMessages bool `json:"approximate_messages,omitempty"`
+	OtherMessages       int  `json:"other_messages,omitempty"`
+	// LongestMessage is the longest message, LongestMessageLen its
+	// length in characters, i.e. runes rather than bytes.
+	LongestMessage    string `json:"longest_message,omitempty"`
+	LongestMessageLen int    `json:"longest_message_len,omitempty"`
+	// UniqueMessageCount is the number of distinct messages counted
+	// in MsgFrequency, a lower bound when they are approximate.
+	UniqueMessageCount int `json:"unique_messages,omitempty"`
+	// ResponseCount, ResponseSum, ResponseMin and ResponseMax summarize
+	// the response times, which are not kept in ResponseTime when they are
+	// counted by a quantile sketch, see WithQuantileAccuracy.
+	ResponseCount int     `json:"response_count"`
+	ResponseSum   float64 `json:"response_sum_ms"`
+	ResponseMin   float64 `json:"response_min_ms"`
+	ResponseMax   float64 `json:"response_max_ms"`
+	// ResponseSample is a uniform sample of the response times when they
+	// are counted by a quantile sketch, see WithResponseSample.
+	ResponseSample []float64 `json:"response_time_sample_ms,omitempty"`
+	// Metrics are the metrics of the extractors given WithExtractors
+	// by name, the response time being summarized above.
+	Metrics map[string]*Metric `json:"metrics,omitempty"`
+	// TotalLines is the number of lines read, including the invalid lines
+	// and the entries skipped by the filters, see Coverage.
+	TotalLines int `json:"total_lines,omitempty"`
+	// SkippedLines is the number of lines which could not be parsed.
+	SkippedLines int `json:"skipped_lines,omitempty"`
+	// SkipReasons is the number of skipped lines and entries by reason,
+	// the parse error of the lines or the filter of the entries, e.g.
+	// "bad timestamp" or "filtered by level", see WithSkipReasons.
+	SkipReasons map[string]int `json:"skip_reasons,omitempty"`
+	// Stopped is set when the analysis stopped early
+	// after too many parse errors, see WithMaxErrors.
+	Stopped *StoppedError `json:"stopped_early,omitempty"`
+
+	normalize    bool
+	group        *regexp.Regexp // frequency key of the messages, if set
+	includeEmpty bool           // count the frequency of empty messages
+	interval     time.Duration
+	topN         int
+	percentiles  []float64
+	buckets      map[time.Time]int
+	timeRun      TimeBucket      // the last entries in a row sharing a timestamp
+	messages     *spaceSaving    // bounded message frequency, if enabled
+	sketch       *quantileSketch // response time quantiles, if enabled
+	sample       *reservoir      // response time sample along with the sketch
+	extractors   []MetricExtractor
+	key          FrequencyKey // frequency key of the entries, the message if nil
+	maxEntries   int          // entries to analyze, all if zero
+	humanize     bool         // group the digits of the counts in Fprint
+	maxLen       int          // characters of the counted messages, all if zero
+	unparseable  bool         // print the UnparseableRate in Fprint
+}
+
+// TimeBucket is the number of entries in the interval starting at Start.
+type TimeBucket struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// longestMessageWidth is the number of characters of the longest
+// message shown in the human readable report.
+const longestMessageWidth = 60
+
+// msgFrequencyHint is the initial capacity of the message frequency map,
+// sparing the first rounds of growth on files with many distinct messages.
+const msgFrequencyHint = 1024
+
+func NewAnalysisReport() *AnalysisReport {
+	return &AnalysisReport{
+		MsgFrequency: make(map[string]int, msgFrequencyHint),
+		Sources:      make(map[string]int),
+	}
+}
+
+func (report *AnalysisReport) Add(entry LogEntry) {
+	report.TotalEntries++
+
+	// Record the time range.
+	if report.FirstEntry.IsZero() || entry.time.Before(report.FirstEntry) {
+		report.FirstEntry = entry.time
+	}
+	if entry.time.After(report.LastEntry) {
+		report.LastEntry = entry.time
+	}
+
+	// Record the entries in a row sharing the same timestamp.
+	if report.timeRun.Count > 0 && entry.time.Equal(report.timeRun.Start) {
+		report.timeRun.Count++
+	} else {
+		report.timeRun = TimeBucket{Start: entry.time, Count: 1}
+	}
+	report.sameTime(report.timeRun.Start, report.timeRun.Count)
+
+	// Record the log level count.
+	switch entry.level {
+	case LevelInfo:
+		report.Info++
+	case LevelWarn:
+		report.Warn++
+	case LevelError:
+		report.Error++
+	case LevelDebug:
+		report.Debug++
+	case LevelNone:
+		report.None++
+	}
+
+	// Record the response time and the extracted metrics.
+	report.extract(entry, ResponseTimeExtractor)
+	for _, extract := range report.extractors {
+		report.extract(entry, extract)
+	}
+
+	// Record the longest message. A message has at least as many bytes
+	// as runes, so only those with more bytes need their runes counted.
+	if len(entry.message) > report.LongestMessageLen {
+		if n := utf8.RuneCountInString(entry.message); n > report.LongestMessageLen {
+			report.LongestMessage, report.LongestMessageLen = entry.message, n
+		}
+	}
+
+	// Record the frequency of each message.
+	msg := entry.message
+	if report.key != nil {
+		msg = report.key(entry)
+	}
+	if msg != "" || report.includeEmpty {
+		if report.group != nil {
+			msg = GroupMessage(report.group, msg)
+		}
+		if report.normalize {
+			msg = NormalizeMessage(msg)
+		}
+		msg = report.truncate(msg)
+		if report.messages != nil {
+			report.messages.Add(msg, 1, 0)
+		} else {
+			report.MsgFrequency[msg]++
+		}
+	}
+
+	// Record the entry count per interval.
+	if report.interval > 0 {
+		if report.buckets == nil {
+			report.buckets = make(map[time.Time]int)
+		}
+		report.buckets[entry.time.Truncate(report.interval)]++
+	}
+
+	// Record the source count.
+	if entry.source != "" {
+		report.Sources[entry.source]++
+	}
+}
+
+// truncate return msg cut to the maximum message length, if any.
+func (r *AnalysisReport) truncate(msg string) string {
+	if r.maxLen > 0 {
+		return TruncateMessage(msg, r.maxLen)
+	}
+	return msg
+}
+
+// Clone return a deep copy of the report.
+func (r *AnalysisReport) Clone() *AnalysisReport {
+	c := *r
+	c.ResponseTime = append([]float64(nil), r.ResponseTime...)
+	c.MsgFrequency = make(map[string]int, len(r.MsgFrequency))
+	for k, v := range r.MsgFrequency {
+		c.MsgFrequency[k] = v
+	}
+	c.Sources = make(map[string]int, len(r.Sources))
+	for k, v := range r.Sources {
+		c.Sources[k] = v
+	}
+	c.Top = append([]MessageCount(nil), r.Top...)
+	c.Percentiles = append([]Percentile(nil), r.Percentiles...)
+	c.Timeline = append([]TimeBucket(nil), r.Timeline...)
+	if r.SkipReasons != nil {
+		c.SkipReasons = make(map[string]int, len(r.SkipReasons))
+		for k, v := range r.SkipReasons {
+			c.SkipReasons[k] = v
+		}
+	}
+	if r.Metrics != nil {
+		c.Metrics = make(map[string]*Metric, len(r.Metrics))
+		for k, m := range r.Metrics {
+			c.Metrics[k] = m.clone()
+		}
+	}
+	if r.buckets != nil {
+		c.buckets = make(map[time.Time]int, len(r.buckets))
+		for k, v := range r.buckets {
+			c.buckets[k] = v
+		}
+	}
+	if r.messages != nil {
+		c.messages = r.messages.clone()
+	}
+	if r.sketch != nil {
+		c.sketch = r.sketch.clone()
+	}
+	if r.sample != nil {
+		c.sample = r.sample.clone()
+		c.ResponseSample = c.sample.Values
+	}
+	return &c
+}
+
+// groupPattern return the pattern of the group regex re, empty if nil.
+func groupPattern(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+	return re.String()
+}
+
+// Merge add the entries analyzed in other to the report, as if they had
+// been added to it. The derived statistics, such as the top messages and
+// percentiles, are computed again over the merged entries, while MaxSameTime
+// is the largest of both reports. Reports analyzed with a different
+// normalization, grouping, message length, interval, quantile accuracy or
+// response sample size can not be merged.
+func (r *AnalysisReport) Merge(other *AnalysisReport) error {
+	if r.normalize != other.normalize {
+		return fmt.Errorf("merge: incompatible normalization %t and %t", r.normalize, other.normalize)
+	}
+	if groupPattern(r.group) != groupPattern(other.group) {
+		return fmt.Errorf("merge: incompatible group regex %q and %q", groupPattern(r.group), groupPattern(other.group))
+	}
+	if r.includeEmpty != other.includeEmpty {
+		return fmt.Errorf("merge: incompatible empty message counting %t and %t", r.includeEmpty, other.includeEmpty)
+	}
+	if r.maxLen != other.maxLen {
+		return fmt.Errorf("merge: incompatible max message lengths %d and %d", r.maxLen, other.maxLen)
+	}
+	if r.interval != other.interval {
+		return fmt.Errorf("merge: incompatible intervals %s and %s", r.interval, other.interval)
+	}
+	if (r.messages == nil) != (other.messages == nil) {
+		return fmt.Errorf("merge: incompatible bounded and exact message frequencies")
+	}
+	if (r.sketch == nil) != (other.sketch == nil) {
+		return fmt.Errorf("merge: incompatible estimated and exact response time quantiles")
+	}
+	if r.sketch != nil && r.sketch.Accuracy != other.sketch.Accuracy {
+		return fmt.Errorf("merge: incompatible quantile accuracies %g and %g", r.sketch.Accuracy, other.sketch.Accuracy)
+	}
+	if r.sample != nil && other.sample != nil && r.sample.Size != other.sample.Size {
+		return fmt.Errorf("merge: incompatible response sample sizes %d and %d", r.sample.Size, other.sample.Size)
+	}
+
+	r.TotalEntries += other.TotalEntries
+	r.Info += other.Info
+	r.Warn += other.Warn
+	r.Error += other.Error
+	r.Debug += other.Debug
+	r.None += other.None
+	r.TotalLines += other.TotalLines
+	r.SkippedLines += other.SkippedLines
+	if len(other.SkipReasons) > 0 && r.SkipReasons == nil {
+		r.SkipReasons = make(map[string]int, len(other.SkipReasons))
+	}
+	for k, v := range other.SkipReasons {
+		r.SkipReasons[k] += v
+	}
+	if other.LongestMessageLen > r.LongestMessageLen {
+		r.LongestMessage, r.LongestMessageLen = other.LongestMessage, other.LongestMessageLen
+	}
+	if r.Stopped == nil {
+		r.Stopped = other.Stopped
+	}
+	r.ResponseTime = append(r.ResponseTime, other.ResponseTime...)
+	if r.sketch != nil {
+		r.sketch.Merge(other.sketch)
+	}
+	switch {
+	case r.sample == nil:
+	case other.sample != nil:
+		r.sample.Merge(other.sample)
+	case other.ResponseCount > 0:
+		r.sample = nil // the sample would miss the response times of other
+		r.ResponseSample = nil
+	}
+	if other.ResponseCount > 0 {
+		if r.ResponseCount == 0 || other.ResponseMin < r.ResponseMin {
+			r.ResponseMin = other.ResponseMin
+		}
+		if r.ResponseCount == 0 || other.ResponseMax > r.ResponseMax {
+			r.ResponseMax = other.ResponseMax
+		}
+		r.ResponseCount += other.ResponseCount
+		r.ResponseSum += other.ResponseSum
+	}
+	if r.MsgFrequency == nil {
+		r.MsgFrequency = make(map[string]int, len(other.MsgFrequency))
+	}
+	if r.Sources == nil {
+		r.Sources = make(map[string]int, len(other.Sources))
+	}
+	if r.messages != nil {
+		for _, it := range other.messages.heap {
+			r.messages.Add(it.key, it.count, it.err)
+		}
+		r.messages.evicted = r.messages.evicted || other.messages.evicted
+	} else {
+		for k, v := range other.MsgFrequency {
+			r.MsgFrequency[k] += v
+		}
+	}
+	for k, v := range other.Sources {
+		r.Sources[k] += v
+	}
+	for _, name := range other.metricNames() {
+		r.metric(name).merge(other.Metrics[name])
+	}
+	if len(other.buckets) > 0 && r.buckets == nil {
+		r.buckets = make(map[time.Time]int, len(other.buckets))
+	}
+	for k, v := range other.buckets {
+		r.buckets[k] += v
+	}
+	if other.MaxSameTime > 0 {
+		r.sameTime(other.MaxSameTimeAt, other.MaxSameTime)
+	}
+	if !other.FirstEntry.IsZero() && (r.FirstEntry.IsZero() || other.FirstEntry.Before(r.FirstEntry)) {
+		r.FirstEntry = other.FirstEntry
+	}
+	if other.LastEntry.After(r.LastEntry) {
+		r.LastEntry = other.LastEntry
+	}
+	r.finish()
+	return nil
+}
+
+// Coverage return the fraction of the total lines analyzed, e.g. the
+// TotalLines of an analysis limited by WithMaxEntries, zero without lines.
+func (r *AnalysisReport) Coverage(total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(r.TotalEntries) / float64(total)
+}
+
+// UnparseableRate return the fraction of the lines which could not be parsed,
+// the SkippedLines of the TotalLines parsed or not, zero without lines.
+func (r *AnalysisReport) UnparseableRate() float64 {
+	if r.TotalLines <= 0 {
+		return 0
+	}
+	return float64(r.SkippedLines) / float64(r.TotalLines)
+}
+
+// full report whether the report has the maximum number of entries
+// of WithMaxEntries, the following lines being only counted.
+func (r *AnalysisReport) full() bool {
+	return r.maxEntries > 0 && r.TotalEntries >= r.maxEntries
+}
+
+// skipLine count the line skipped for the parse error err,
+// by its reason when the skip reasons are counted.
+func (r *AnalysisReport) skipLine(err error) {
+	r.SkippedLines++
+	var perr *ParseError
+	if r.SkipReasons != nil && errors.As(err, &perr) {
+		r.SkipReasons[perr.Reason.String()]++
+	}
+}
+
+// filtered count the entry skipped by the filter f
+// when the skip reasons are counted.
+func (r *AnalysisReport) filtered(f NamedFilter) {
+	if r.SkipReasons == nil {
+		return
+	}
+	reason := "filtered"
+	if f.Name != "" {
+		reason += " by " + f.Name
+	}
+	r.SkipReasons[reason]++
+}
+
+// skipReasons return the skip reasons, the most frequent first.
+func (r *AnalysisReport) skipReasons() []MessageCount {
+	reasons := make([]MessageCount, 0, len(r.SkipReasons))
+	for k, v := range r.SkipReasons {
+		reasons = append(reasons, MessageCount{Message: k, Count: v})
+	}
+	sort.Slice(reasons, func(i, j int) bool { return reasons[i].before(reasons[j]) })
+	return reasons
+}
+
+// responseTime return the response time in ms of the message,
+// the last word before the ms suffix, or false if it has none.
+func responseTime(msg string) (float64, bool) {
+	msg, ok := strings.CutSuffix(msg, " ms")
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(msg[strings.LastIndexByte(msg, ' ')+1:], 64)
+	return v, err == nil
+}
+
+// addResponseTime record a response time of v ms.
+func (r *AnalysisReport) addResponseTime(v float64) {
+	if r.ResponseCount == 0 || v < r.ResponseMin {
+		r.ResponseMin = v
+	}
+	if r.ResponseCount == 0 || v > r.ResponseMax {
+		r.ResponseMax = v
+	}
+	r.ResponseCount++
+	r.ResponseSum += v
+	if r.sketch != nil {
+		r.sketch.Add(v, 1)
+		if r.sample != nil {
+			r.sample.Add(v)
+		}
+	} else {
+		r.ResponseTime = append(r.ResponseTime, v)
+	}
+}
+
+// ResponseTimeSample return the response times in ms, all of them when they
+// are kept in ResponseTime, or else a uniform sample of them, see
+// WithResponseSample, for the analyses wanting actual values rather than
+// estimated quantiles. It is nil when they are neither kept nor sampled.
+func (r *AnalysisReport) ResponseTimeSample() []float64 {
+	if r.sketch == nil {
+		return r.ResponseTime
+	}
+	return r.ResponseSample
+}
+
+// AverageResponseTime return the average response time in ms,
+// or false if no entry had a response time.
+func (r *AnalysisReport) AverageResponseTime() (float64, bool) {
+	if r.ResponseCount == 0 {
+		return 0, false
+	}
+	return r.ResponseSum / float64(r.ResponseCount), true
+}
+
+// Quantile return the q quantile of the response times in ms, q within
+// [0, 1], or false if no entry had a response time. It is exact unless
+// the report was analyzed WithQuantileAccuracy, in which case it is within
+// the relative error of that accuracy of the exact quantile.
+func (r *AnalysisReport) Quantile(q float64) (float64, bool) {
+	if r.sketch != nil {
+		return r.sketch.Quantile(q)
+	}
+	if len(r.ResponseTime) == 0 {
+		return 0, false
+	}
+	return percentiles(r.ResponseTime, []float64{q * 100})[0].Value, true
+}
+
+// stop record that the analysis stopped early if err is a *StoppedError,
+// returning err.
+func (r *AnalysisReport) stop(err error) error {
+	var stopped *StoppedError
+	if errors.As(err, &stopped) {
+		r.Stopped = stopped
+	}
+	return err
+}
+
+// sameTime record that n entries share the timestamp t.
+func (r *AnalysisReport) sameTime(t time.Time, n int) {
+	if n > r.MaxSameTime || (n == r.MaxSameTime && t.Before(r.MaxSameTimeAt)) {
+		r.MaxSameTime, r.MaxSameTimeAt = n, t
+	}
+}
+
+// finish compute the report fields derived from the added entries.
+func (r *AnalysisReport) finish() {
+	if r.messages != nil {
+		var tracked int
+		r.MsgFrequency, tracked = r.messages.Counts()
+		r.ApproximateMessages = r.messages.evicted
+		r.OtherMessages = r.TotalEntries - tracked
+	}
+	r.UniqueMessageCount = len(r.MsgFrequency)
+	if r.sample != nil {
+		r.ResponseSample = r.sample.Values
+	}
+	if r.topN > 0 {
+		r.Top = r.TopMessages(r.topN)
+	}
+	if len(r.percentiles) > 0 {
+		r.Percentiles = r.quantiles(r.percentiles)
+		for _, m := range r.Metrics {
+			m.Percentiles = m.quantiles(r.percentiles)
+		}
+	}
+	if r.interval > 0 {
+		r.Timeline = r.timeline()
+	}
+}
+
+// quantiles return the response time percentiles ps.
+func (r *AnalysisReport) quantiles(ps []float64) []Percentile {
+	if r.sketch == nil {
+		return percentiles(r.ResponseTime, ps)
+	}
+	var result []Percentile
+	for _, p := range ps {
+		v, ok := r.sketch.Quantile(p / 100)
+		if !ok {
+			return nil
+		}
+		result = append(result, Percentile{P: p, Value: v})
+	}
+	return result
+}
+
+// timeline return the entry count per interval in chronological order.
+func (r *AnalysisReport) timeline() []TimeBucket {
+	return timeBuckets(r.buckets)
+}
+
+// Summary return the report as a single line of space separated key=value
+// pairs, suitable for shell scripts. e.g:
+// total=5000 info=3000 debug=1200 warn=500 error=300 avg_response_ms=245.00
+func (r AnalysisReport) Summary() string {
+	s := fmt.Sprintf("total=%d info=%d debug=%d warn=%d error=%d",
+		r.TotalEntries, r.Info, r.Debug, r.Warn, r.Error)
+	if avg, ok := r.AverageResponseTime(); ok {
+		s += fmt.Sprintf(" avg_response_ms=%.2f", avg)
+	}
+	return s
+}
+
+// Total Log Entries: 5000
+// DEBUG: 1200
+// INFO: 3000
+// WARN: 500
+// ERROR: 300
+// Average Response Time: 245 ms
+func (r AnalysisReport) Print() error {
+	return r.Fprint(os.Stdout)
+}
+
+// String return the human readable report as written by Fprint.
+func (r AnalysisReport) String() string {
+	var b strings.Builder
+	r.Fprint(&b)
+	return b.String()
+}
+
+// levelCount is the number of entries of a level.
+type levelCount struct {
+	level Level
+	count int
+}
+
+// name return the upper case name of the level, as in the reports.
+func (l levelCount) name() string {
+	return strings.ToUpper(l.level.String())
+}
+
+// levelCounts return the number of entries of each level
+// in severity order, see LevelSeverity.
+func (r *AnalysisReport) levelCounts() []levelCount {
+	return []levelCount{{LevelDebug, r.Debug}, {LevelInfo, r.Info}, {LevelWarn, r.Warn}, {LevelError, r.Error}}
+}
+
+// formatCount return n as written by Fprint, its digits grouped
+// by thousands with WithHumanize.
+func (r *AnalysisReport) formatCount(n int) string {
+	if r.humanize {
+		return groupDigits(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// groupDigits return n with its digits grouped by thousands
+// with commas, e.g. "-5,000,000".
+func groupDigits(n int) string {
+	s := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// errWriter record the first error writing to w, the following
+// writes being discarded.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(b []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(b)
+	ew.err = err
+	return n, err
+}
+
+// Fprint write the human readable report to w, highlighting the warnings
+// and errors when w is colored, see paletteFor. It returns the first error
+// writing to w.
+func (r AnalysisReport) Fprint(w io.Writer) error {
+	p := paletteFor(w)
+	ew := &errWriter{w: w}
+	w = ew
+	fmt.Fprintf(w, "Total Log Entries: %s\n", r.formatCount(r.TotalEntries))
+	if r.maxEntries > 0 && r.TotalLines > 0 {
+		fmt.Fprintf(w, "Coverage: %.1f%%\n", r.Coverage(r.TotalLines)*100)
+	}
+	// Per level breakdown is meaningless when lines have no level.
+	if r.None != r.TotalEntries {
+		for _, l := range r.levelCounts() {
+			line := fmt.Sprintf("%s: %s", l.name(), r.formatCount(l.count))
+			switch {
+			case l.level == LevelWarn && l.count > 0:
+				line = p.paint(colorYellow, line)
+			case l.level == LevelError && l.count > 0:
+				line = p.paint(colorRed, line)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	if r.SkippedLines > 0 {
+		fmt.Fprintf(w, "Skipped Lines: %s\n", r.formatCount(r.SkippedLines))
+	}
+	if r.unparseable && r.TotalLines > 0 {
+		fmt.Fprintf(w, "Unparseable Rate: %.1f%%\n", r.UnparseableRate()*100)
+	}
+	if len(r.SkipReasons) > 0 {
+		fmt.Fprintln(w, "Skip Reasons:")
+		for _, reason := range r.skipReasons() {
+			fmt.Fprintf(w, "  %-24s %s\n", reason.Message, r.formatCount(reason.Count))
+		}
+	}
+	if r.Stopped != nil {
+		fmt.Fprintln(w, p.paint(colorRed, fmt.Sprintf("Analysis stopped early at line %d after %d parse errors", r.Stopped.Line, r.Stopped.Errors)))
+	}
+	if first, last, ok := r.TimeRange(); ok {
+		fmt.Fprintf(w, "Time Range: %s - %s\n", first.Format(time.DateTime), last.Format(time.DateTime))
+	}
+	if r.MaxSameTime > 1 {
+		fmt.Fprintf(w, "Max Entries Sharing a Timestamp: %d at %s\n", r.MaxSameTime, r.MaxSameTimeAt.Format(time.DateTime))
+	}
+	if avg, ok := r.AverageResponseTime(); ok {
+		fmt.Fprintf(w, "Average Response Time: %.2f ms\n", avg)
+	}
+
+	// Messages with the same frequency are ordered alphabetically
+	// so the output does not depend on the map iteration order. The line
+	// is left out without messages, or when the most frequent is empty.
+	if top := r.TopMessages(1); len(top) > 0 && top[0].Message != "" {
+		fmt.Fprintf(w, "Most frequent mesage: '%s'\n", r.truncate(top[0].Message))
+	}
+	if r.LongestMessageLen > 0 {
+		fmt.Fprintf(w, "Longest message: %d characters '%s'\n", r.LongestMessageLen, TruncateMessage(r.LongestMessage, longestMessageWidth))
+	}
+	if r.ApproximateMessages {
+		fmt.Fprintf(w, "Message counts are approximate: %d most frequent messages tracked, %d other entries\n", len(r.MsgFrequency), r.OtherMessages)
+	}
+
+	if len(r.Sources) > 0 {
+		sources := make([]string, 0, len(r.Sources))
+		for k := range r.Sources {
+			sources = append(sources, k)
+		}
+		sort.Strings(sources)
+		fmt.Fprintln(w, "Sources:")
+		for _, src := range sources {
+			fmt.Fprintf(w, "  %-20s %s\n", src, r.formatCount(r.Sources[src]))
+		}
+	}
+
+	if len(r.Percentiles) > 0 {
+		fmt.Fprintln(w, "Response Time Percentiles:")
+		for _, p := range r.Percentiles {
+			fmt.Fprintf(w, "  p%-6g %.2f ms\n", p.P, p.Value)
+		}
+	}
+	if len(r.Metrics) > 0 {
+		fmt.Fprintln(w, "Metrics:")
+		for _, name := range r.metricNames() {
+			m := r.Metrics[name]
+			avg, _ := m.Average()
+			fmt.Fprintf(w, "  %-20s count=%d avg=%.2f min=%.2f max=%.2f", name, m.Count, avg, m.Min, m.Max)
+			for _, p := range m.Percentiles {
+				fmt.Fprintf(w, " p%g=%.2f", p.P, p.Value)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	if len(r.Top) > 0 {
+		fmt.Fprintln(w, "Top Messages:")
+		for _, m := range r.Top {
+			fmt.Fprintf(w, "  %-8s %s\n", r.formatCount(m.Count), r.truncate(m.Message))
+		}
+	}
+	if len(r.Timeline) > 0 {
+		fmt.Fprintf(w, "Timeline (%s):\n", r.interval)
+		for _, b := range r.Timeline {
+			fmt.Fprintf(w, "  %s %s\n", b.Start.Format(time.DateTime), r.formatCount(b.Count))
+		}
+	}
+	return ew.err
+}
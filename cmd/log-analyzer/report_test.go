@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Analyzing A+B is the same as merging the reports of A and B,
+// whatever the entries and the point they are split at.
+func TestReportMergeProperty(t *testing.T) {
+	opts := []Option{WithTopN(3), WithPercentiles(50, 99), WithInterval(time.Hour), WithNormalization(true)}
+	analyze := func(entries []LogEntry) *AnalysisReport {
+		t.Helper()
+		report, err := Analyze(entries, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	marshal := func(report *AnalysisReport) string {
+		t.Helper()
+		b, err := json.Marshal(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for seed := int64(0); seed < 20; seed++ {
+		entries := generateEntries(1+rnd.Intn(500), seed)
+		want := marshal(analyze(entries))
+		for _, split := range []int{0, len(entries), rnd.Intn(len(entries) + 1)} {
+			merged := analyze(entries[:split])
+			if err := merged.Merge(analyze(entries[split:])); err != nil {
+				t.Fatal(err)
+			}
+			if got := marshal(merged); got != want {
+				t.Fatalf("seed %d split at %d of %d: got the merged report\n%s\nwant\n%s", seed, split, len(entries), got, want)
+			}
+		}
+	}
+}
+
+func TestReportMergeIncompatible(t *testing.T) {
+	entries := generateEntries(10, 0)
+	for _, opt := range []Option{WithNormalization(true), WithEmptyMessages(true), WithGroupRegex(regexp.MustCompile(`(\w+)`)), WithInterval(time.Minute)} {
+		r, err := Analyze(entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := Analyze(entries, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Merge(other); err == nil {
+			t.Errorf("merged reports analyzed with different options")
+		}
+	}
+}
+
+func TestReportQueries(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 info Request processed in 30 ms",
+		"2025-01-01 10:00:02 ERROR Connection lost",
+		"2025-01-01 10:00:03 WARN Memory usage is high",
+		"2025-01-01 10:00:04 ERROR Connection lost",
+		"2025-01-01 10:00:05 DEBUG Request processed in 20 ms",
+		"2025-01-01 10:00:06 ERROR Timeout",
+		"2025-01-01 10:00:07 INFO Started",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	report, err := Analyze(entries, WithTopN(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []MessageCount{{"Connection lost", 2}, {"Memory usage is high", 1}, {"Request processed in 10 ms", 1}}
+	if got := report.TopMessages(3); !slices.Equal(got, want) {
+		t.Errorf("got the top messages %v, want %v", got, want)
+	}
+	if !slices.Equal(report.Top, want[:1]) {
+		t.Errorf("got the report top %v, want %v", report.Top, want[:1])
+	}
+	if got := report.TopMessages(100); len(got) != 7 {
+		t.Errorf("got %d top messages of 100, want all the 7 messages", len(got))
+	}
+	for level, want := range map[string]int{"info": 3, "INFO": 3, "Error": 3, "warn": 1, "debug": 1, "fatal": 3, "[WARNING]": 1, "none": 0, "bogus": 0} {
+		if got := report.LevelCount(level); got != want {
+			t.Errorf("LevelCount(%q) = %d, want %d", level, got, want)
+		}
+	}
+	if got := report.ErrorRate(); got != 3.0/8 {
+		t.Errorf("got the error rate %g, want %g", got, 3.0/8)
+	}
+	for p, want := range map[float64]float64{50: 20, 100: 30, 1: 10} {
+		if got, ok := report.ResponseTimePercentile(p); !ok || got != want {
+			t.Errorf("ResponseTimePercentile(%g) = %g, %v, want %g", p, got, ok, want)
+		}
+	}
+	start, end, ok := report.TimeRange()
+	if !ok || !start.Equal(entries[0].time) || !end.Equal(entries[len(entries)-1].time) {
+		t.Errorf("got the time range %s - %s, %v, want %s - %s", start, end, ok, entries[0].time, entries[len(entries)-1].time)
+	}
+
+	empty, err := Analyze(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := empty.TopMessages(3); len(got) != 0 {
+		t.Errorf("got the top messages %v of an empty report", got)
+	}
+	if got := empty.LevelCount("error"); got != 0 {
+		t.Errorf("got %d errors in an empty report", got)
+	}
+	if got := empty.ErrorRate(); got != 0 {
+		t.Errorf("got the error rate %g of an empty report, want 0", got)
+	}
+	if got, ok := empty.ResponseTimePercentile(50); ok {
+		t.Errorf("got the median response time %g of an empty report", got)
+	}
+	if _, _, ok := empty.TimeRange(); ok {
+		t.Errorf("got a time range for an empty report")
+	}
+}
+
+var update = flag.Bool("update", false, "update the golden files of the tests")
+
+// golden compare got to the content of the golden file testdata/name,
+// writing got to it instead with -update.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("got\n%s\nwant the content of %s\n%s", got, path, want)
+	}
+}
+
+// The human readable report only changes deliberately, by
+// updating the golden files with 'go test -run Fprint -update'.
+func TestFprintGolden(t *testing.T) {
+	setColorMode(t, ColorNever)
+	noLevel := generateEntries(50, 2)
+	for i := range noLevel {
+		noLevel[i].level = LevelNone
+	}
+	for _, tt := range []struct {
+		name    string
+		entries []LogEntry
+		opts    []Option
+	}{
+		{"report.golden", generateEntries(200, 0), nil},
+		{"report_options.golden", generateEntries(200, 1), []Option{WithTopN(3), WithPercentiles(50, 95, 99), WithInterval(6 * time.Hour), WithNormalization(true)}},
+		{"report_no_level.golden", noLevel, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Analyze(tt.entries, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := report.Fprint(&b); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != report.String() {
+				t.Errorf("got String\n%s\nwant the output of Fprint\n%s", report.String(), b.String())
+			}
+			golden(t, tt.name, b.String())
+		})
+	}
+}
+
+// failingWriter fail every write after the first n bytes.
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if len(b) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errors.New("disk full")
+	}
+	w.n -= len(b)
+	return len(b), nil
+}
+
+func TestFprintError(t *testing.T) {
+	report, err := Analyze(generateEntries(100, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{0, 10, 100} {
+		if err := report.Fprint(&failingWriter{n: n}); err == nil || err.Error() != "disk full" {
+			t.Errorf("after %d bytes: got %v, want the error of the writer", n, err)
+		}
+	}
+}
+
+// An empty report is printed without the most frequent message.
+func TestFprintEmpty(t *testing.T) {
+	onlyEmpty := NewAnalysisReport()
+	onlyEmpty.MsgFrequency[""] = 3
+	for name, report := range map[string]*AnalysisReport{
+		"zero":       {},
+		"new":        NewAnalysisReport(),
+		"only empty": onlyEmpty,
+	} {
+		var b strings.Builder
+		if err := report.Fprint(&b); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); !strings.HasPrefix(got, "Total Log Entries: 0\n") || strings.Contains(got, "Most frequent") {
+			t.Errorf("%s: got %q, want the totals without the most frequent message", name, got)
+		}
+	}
+}
+
+// Nothing matching the filters is not an error.
+func TestNoEntriesMatched(t *testing.T) {
+	empty := writeLines(t, "empty.log")
+	path := writeLines(t, "app.log", "2025-01-01 10:00:00 INFO Started", "not a log line")
+	for _, args := range [][]string{{empty}, {"-level", "error", path}, {"-sort", "-level", "error", path}} {
+		stdout, stderr, status := runMain(t, args...)
+		if status != 0 || stdout != "0 entries matched the filters\n" {
+			t.Errorf("%v: got %q, %q, exit %d", args, stdout, stderr, status)
+		}
+	}
+	if stdout, _, status := runMain(t, "-format", "json", "-level", "error", path); status != 0 || !strings.HasPrefix(stdout, `{"total_entries":0,`) {
+		t.Errorf("got %q, exit %d, want the empty json report", stdout, status)
+	}
+}
+
+func TestGroupDigits(t *testing.T) {
+	for n, want := range map[int]string{
+		0:         "0",
+		999:       "999",
+		1000:      "1,000",
+		123456:    "123,456",
+		5000000:   "5,000,000",
+		-1234567:  "-1,234,567",
+		100000000: "100,000,000",
+	} {
+		if got := groupDigits(n); got != want {
+			t.Errorf("groupDigits(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// The counts are grouped by thousands with WithHumanize only.
+func TestFprintHumanize(t *testing.T) {
+	for _, humanize := range []bool{false, true} {
+		o, err := newOptions(WithHumanize(humanize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		report := o.newReport()
+		report.TotalEntries, report.Info = 5000000, 5000000
+		want := "Total Log Entries: 5000000\nDEBUG: 0\nINFO: 5000000\n"
+		if humanize {
+			want = "Total Log Entries: 5,000,000\nDEBUG: 0\nINFO: 5,000,000\n"
+		}
+		if got := report.String(); !strings.HasPrefix(got, want) {
+			t.Errorf("humanize %v: got %q, want it to start with %q", humanize, got, want)
+		}
+	}
+}
+
+// Every other line of the fixture is malformed, half the lines then being
+// unparseable, the rate being printed only with WithUnparseableRate.
+func TestUnparseableRate(t *testing.T) {
+	text := "2025-01-01 10:00:00 INFO Request processed in 10 ms\n" +
+		"not a log line\n" +
+		"2025-01-01 10:00:01 ERROR Connection lost\n" +
+		"2025-01-01 10:00\n" +
+		"2025-01-01 10:00:02 WARN Disk almost full\n" +
+		"2025-13-01 10:00:03 INFO Bad month\n"
+	for _, include := range []bool{false, true} {
+		report, err := AnalyzeReader(strings.NewReader(text), WithUnparseableRate(include), WithInvalidLineHandler(DiscardInvalidLine))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.SkippedLines != 3 || report.TotalLines != 6 || report.UnparseableRate() != 0.5 {
+			t.Errorf("got %d of %d lines skipped, rate %g, want 3 of 6, rate 0.5", report.SkippedLines, report.TotalLines, report.UnparseableRate())
+		}
+		if got := strings.Contains(report.String(), "Unparseable Rate: 50.0%\n"); got != include {
+			t.Errorf("include %v: got the rate printed %v in\n%s", include, got, report.String())
+		}
+	}
+
+	if rate := NewAnalysisReport().UnparseableRate(); rate != 0 {
+		t.Errorf("got rate %g without lines, want 0", rate)
+	}
+}
+
+// A sustained error is spread across the buckets, a spike is in one.
+func TestTopErrorsByTime(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:05:00 ERROR Connection lost",
+		"2025-01-01 10:10:00 ERROR Connection lost",
+		"2025-01-01 10:30:00 ERROR Disk full",
+		"2025-01-01 10:30:00 ERROR Disk full",
+		"2025-01-01 10:31:00 ERROR Disk full",
+		"2025-01-01 11:20:00 ERROR Connection lost",
+		"2025-01-01 12:00:00 WARN Connection lost",
+		"2025-01-01 12:00:00 ERROR Timeout",
+		"2025-01-01 13:59:59 ERROR Connection lost",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	got := TopErrorsByTime(entries, 2, time.Hour)
+	want := []ErrorTimeline{
+		{Message: "Connection lost", Total: 4, Buckets: []int{2, 1, 0, 1}},
+		{Message: "Disk full", Total: 3, Buckets: []int{3, 0, 0, 0}},
+	}
+	if !got.Start.Equal(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)) || !reflect.DeepEqual(got.Timelines, want) {
+		t.Errorf("got the timelines %+v from %v, want %+v from 10:00", got.Timelines, got.Start, want)
+	}
+
+	var b strings.Builder
+	if err := got.Print(&b); err != nil {
+		t.Fatal(err)
+	}
+	wantText := `Top errors by time (from 2025-01-01 10:00:00, 1h0m0s buckets):
+TOTAL  DISTRIBUTION  MESSAGE
+4      2 1 0 1       Connection lost
+3      3 0 0 0       Disk full
+`
+	if b.String() != wantText {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), wantText)
+	}
+
+	if got := TopErrorsByTime(entries[6:7], 2, time.Hour); len(got.Timelines) != 0 {
+		t.Errorf("got the timelines %+v without error entries", got.Timelines)
+	}
+}
+
+// The entries in a row sharing a timestamp are counted, the earliest of
+// the longest runs being reported, through Merge and Load too.
+func TestMaxSameTime(t *testing.T) {
+	lines := []string{
+		"2025-01-01 10:00:02 INFO Request processed",
+		"2025-01-01 10:00:02 INFO Request processed",
+		"2025-01-01 10:00:02 WARN Slow request",
+		"2025-01-01 10:00:03 INFO Request processed",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+		"2025-01-01 10:00:02 INFO Request processed", // not in a row with the first ones
+		"2025-01-01 10:00:04 INFO Request processed",
+		"2025-01-01 10:00:04 INFO Request processed",
+	}
+	var entries []LogEntry
+	for _, line := range lines {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	at := func(sec int) time.Time { return time.Date(2025, 1, 1, 10, 0, sec, 0, time.UTC) }
+	for _, tt := range []struct {
+		entries []LogEntry
+		n       int
+		at      time.Time
+	}{
+		{entries, 3, at(1)},
+		{entries[:4], 3, at(2)},
+		{entries[3:4], 1, at(3)},
+		{entries[7:], 2, at(4)},
+		{nil, 0, time.Time{}},
+	} {
+		report, err := Analyze(tt.entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.MaxSameTime != tt.n || !report.MaxSameTimeAt.Equal(tt.at) {
+			t.Errorf("%d entries: got %d sharing %v, want %d sharing %v", len(tt.entries), report.MaxSameTime, report.MaxSameTimeAt, tt.n, tt.at)
+		}
+	}
+
+	// Merge keep the largest, the run going on when the report was saved
+	// being continued by the entries added after Load.
+	report, _ := Analyze(entries[7:9])
+	other, _ := Analyze(entries[:4])
+	if err := report.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+	if report.MaxSameTime != 3 || !report.MaxSameTimeAt.Equal(at(2)) {
+		t.Errorf("got %d entries sharing %v after Merge, want 3 sharing %v", report.MaxSameTime, report.MaxSameTimeAt, at(2))
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range append(entries[8:], entries[8:]...) {
+		loaded.Add(entry)
+	}
+	if loaded.MaxSameTime != 5 || !loaded.MaxSameTimeAt.Equal(at(4)) {
+		t.Errorf("got %d entries sharing %v after Load, want 5 sharing %v", loaded.MaxSameTime, loaded.MaxSameTimeAt, at(4))
+	}
+
+	var b strings.Builder
+	if err := loaded.Fprint(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Max Entries Sharing a Timestamp: 5 at 2025-01-01 10:00:04\n"; !strings.Contains(b.String(), want) {
+		t.Errorf("got the report\n%s\nwithout %q", b.String(), want)
+	}
+}
+
+// BenchmarkReportAdd add 1M entries of 5000 distinct messages and mixed
+// case levels to a report, the allocations per op being those of the
+// report rather than of each entry.
+func BenchmarkReportAdd(b *testing.B) {
+	levels := []string{"INFO", "info", "Warn", "ERROR", "debug"}
+	entries := make([]LogEntry, 1_000_000)
+	for i := range entries {
+		line := fmt.Sprintf("2025-01-01 10:%02d:%02d %s Request %d processed in %d ms", i/60%60, i%60, levels[i%len(levels)], i%5000, i%1000)
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			b.Fatal(err)
+		}
+		entries[i] = entry
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		report := NewAnalysisReport()
+		for _, entry := range entries {
+			report.Add(entry)
+		}
+	}
+}
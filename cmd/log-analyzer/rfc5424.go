@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InputRFC5424 is the -input of syslog lines following RFC 5424.
+const InputRFC5424 = "rfc5424"
+
+// ParseRFC5424 parse a syslog line of the RFC 5424 format, i.e:
+// <pri>version timestamp host app procid msgid [structured-data] msg
+// The severity of the priority is mapped to a level, emergency to error
+// being error, warning warn, notice and informational info and debug
+// debug. The app name is the entry source and the structured data params
+// are available with LogEntry.Field.
+func ParseRFC5424(line string) (LogEntry, error) {
+	var entry LogEntry
+	malformed := func(format string, args ...any) error {
+		return &ParseError{Raw: line, Reason: Malformed, Err: fmt.Errorf(format, args...)}
+	}
+
+	rest, ok := strings.CutPrefix(line, "<")
+	if !ok {
+		return LogEntry{}, malformed("missing priority")
+	}
+	pri, rest, ok := strings.Cut(rest, ">")
+	if !ok {
+		return LogEntry{}, malformed("missing priority")
+	}
+	p, err := strconv.Atoi(pri)
+	if err != nil || p < 0 || p > 191 || len(pri) > 3 {
+		return LogEntry{}, malformed("invalid priority %q", pri)
+	}
+	entry.level = syslogLevel(p % 8)
+	entry.rawLevel = strings.ToUpper(entry.level.String())
+
+	// version timestamp host app procid msgid, then the structured data
+	// and the optional message.
+	fields := strings.SplitN(rest, " ", 7)
+	if len(fields) < 7 {
+		return LogEntry{}, &ParseError{Raw: line, Reason: TooFewFields}
+	}
+	if fields[0] != "1" {
+		return LogEntry{}, malformed("unsupported version %q", fields[0])
+	}
+	if entry.time, err = time.Parse(time.RFC3339Nano, fields[1]); err == nil {
+		entry.time, err = checkTime(entry.time)
+	}
+	if err != nil {
+		return LogEntry{}, &ParseError{Raw: line, Reason: BadTimestamp, Err: err}
+	}
+	if app := fields[3]; app != "-" {
+		entry.source = app
+	}
+
+	data, msg, err := parseStructuredData(fields[6])
+	if err != nil {
+		return LogEntry{}, malformed("%s", err)
+	}
+	entry.data = data
+	entry.message = strings.TrimPrefix(msg, "\ufeff")
+	return entry, nil
+}
+
+// syslogLevel return the level of a syslog severity.
+func syslogLevel(severity int) Level {
+	switch {
+	case severity <= 3:
+		return LevelError
+	case severity == 4:
+		return LevelWarn
+	case severity <= 6:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+// syslogSeverity return the syslog severity of the level, the inverse of
+// syslogLevel, or false for a level without severity such as LevelNone.
+func syslogSeverity(level Level) (int, bool) {
+	switch level {
+	case LevelError:
+		return 3, true
+	case LevelWarn:
+		return 4, true
+	case LevelInfo:
+		return 6, true
+	case LevelDebug:
+		return 7, true
+	}
+	return 0, false
+}
+
+// parseStructuredData parse the structured data at the start of s,
+// returning its params and the message following it.
+func parseStructuredData(s string) (map[string]string, string, error) {
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		return nil, strings.TrimPrefix(rest, " "), nil
+	}
+	data := make(map[string]string)
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		i := strings.IndexAny(s, " ]")
+		if i <= 0 {
+			return nil, "", fmt.Errorf("invalid structured data element")
+		}
+		s = s[i:]
+		for strings.HasPrefix(s, " ") {
+			name, value, ok := strings.Cut(s[1:], `="`)
+			if !ok || name == "" {
+				return nil, "", fmt.Errorf("invalid structured data param")
+			}
+			var b strings.Builder
+			s = value
+			for {
+				j := strings.IndexAny(s, `\"`)
+				if j < 0 {
+					return nil, "", fmt.Errorf("unterminated structured data param %s", name)
+				}
+				b.WriteString(s[:j])
+				if s[j] == '"' {
+					s = s[j+1:]
+					break
+				}
+				// Only ", \ and ] are escaped, a backslash
+				// before any other character is kept.
+				if j+1 < len(s) && strings.IndexByte(`"\]`, s[j+1]) >= 0 {
+					b.WriteByte(s[j+1])
+					s = s[j+2:]
+				} else {
+					b.WriteByte('\\')
+					s = s[j+1:]
+				}
+			}
+			data[name] = b.String()
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, "", fmt.Errorf("unterminated structured data element")
+		}
+		s = s[1:]
+	}
+	if len(data) == 0 {
+		return nil, "", fmt.Errorf("missing structured data")
+	}
+	if s != "" && s[0] != ' ' {
+		return nil, "", fmt.Errorf("missing space after structured data")
+	}
+	return data, strings.TrimPrefix(s, " "), nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	entry, err := ParseRFC5424(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"] ` + "\ufeff" + `An application event log entry`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2003, 10, 11, 22, 14, 15, 3e6, time.UTC)
+	if !entry.time.Equal(want) || entry.level != LevelInfo || entry.source != "evntslog" || entry.message != "An application event log entry" {
+		t.Errorf("got %+v, want the notice of evntslog at %v", entry, want)
+	}
+	for name, value := range map[string]string{"iut": "3", "eventSource": "Application", "eventID": "1011"} {
+		if got, ok := entry.Field(name); !ok || got != value {
+			t.Errorf("got the param %s %q, %v, want %q", name, got, ok, value)
+		}
+	}
+
+	// Without structured data, app name and message.
+	entry, err = ParseRFC5424(`<34>1 2003-10-11T22:14:15+02:00 host - - - -`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.level != LevelError || entry.source != "" || entry.message != "" || entry.data != nil {
+		t.Errorf("got %+v, want a critical entry without source, message and params", entry)
+	}
+
+	entry, err = ParseRFC5424(`<12>1 2003-10-11T22:14:15Z host app 42 - [a@1 b="\"quoted\" \] \x"][c@1 d=""] two elements`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := entry.Field("b"); b != `"quoted" ] \x` || entry.level != LevelWarn || entry.message != "two elements" {
+		t.Errorf("got %+v with the param b %q, want the escapes removed", entry, b)
+	}
+	if d, ok := entry.Field("d"); !ok || d != "" {
+		t.Errorf("got the param d %q, %v, want it empty", d, ok)
+	}
+}
+
+func TestSyslogLevel(t *testing.T) {
+	want := []Level{LevelError, LevelError, LevelError, LevelError, LevelWarn, LevelInfo, LevelInfo, LevelDebug}
+	for severity, level := range want {
+		if got := syslogLevel(severity); got != level {
+			t.Errorf("syslogLevel(%d) = %s, want %s", severity, got, level)
+		}
+	}
+}
+
+func TestParseRFC5424Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		line   string
+		reason ParseReason
+	}{
+		{"2025-01-01 10:00:00 INFO not syslog", Malformed},
+		{"<>1 2003-10-11T22:14:15Z host app - - -", Malformed},
+		{"<192>1 2003-10-11T22:14:15Z host app - - -", Malformed},
+		{"<13", Malformed},
+		{"<13>1 2003-10-11T22:14:15Z host app", TooFewFields},
+		{"<13>2 2003-10-11T22:14:15Z host app - - -", Malformed},
+		{"<13>1 2003-10-11 host app - - -", BadTimestamp},
+		{`<13>1 2003-10-11T22:14:15Z host app - - [a@1 b="unterminated]`, Malformed},
+		{`<13>1 2003-10-11T22:14:15Z host app - - [a@1 b="c"]message`, Malformed},
+		{`<13>1 2003-10-11T22:14:15Z host app - - no structured data`, Malformed},
+	} {
+		_, err := ParseRFC5424(tt.line)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Reason != tt.reason || perr.Raw != tt.line {
+			t.Errorf("ParseRFC5424(%q): got %v, want a %v *ParseError", tt.line, err, tt.reason)
+		}
+	}
+}
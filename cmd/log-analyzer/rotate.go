@@ -0,0 +1,167 @@
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rotationSuffix match the suffix of a rotated file name,
+// e.g. '.1' and '.2.gz' of 'app.log.1' and 'app.log.2.gz'.
+var rotationSuffix = regexp.MustCompile(`^\.([0-9]+)(\.gz)?$`)
+
+// Rotations return path and its numbered rotations, plain or gzip compressed,
+// ordered from the oldest to the newest: the highest rotation index first and
+// path itself last. e.g. app.log.2.gz, app.log.1, app.log.
+func Rotations(path string) ([]string, error) {
+	matches, err := filepath.Glob(globEscape(path) + ".*")
+	if err != nil {
+		return nil, err
+	}
+	type rotation struct {
+		name  string
+		index int
+	}
+	var rotations []rotation
+	for _, name := range matches {
+		m := rotationSuffix.FindStringSubmatch(strings.TrimPrefix(name, path))
+		if m == nil {
+			continue
+		}
+		index, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		rotations = append(rotations, rotation{name, index})
+	}
+	sort.SliceStable(rotations, func(i, j int) bool {
+		return rotations[i].index > rotations[j].index
+	})
+
+	files := make([]string, 0, len(rotations)+1)
+	for _, r := range rotations {
+		files = append(files, r.name)
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if !errors.Is(err, os.ErrNotExist) || len(files) == 0 {
+		return nil, err
+	}
+	return files, nil
+}
+
+// globEscape escape the meta characters of a filepath.Glob pattern in s.
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`).Replace(s)
+}
+
+// OpenRotated return a reader of the concatenated content of files, in order,
+// decompressing the ones with a '.gz' extension. Closing it close the file
+// being read, files are opened one after the other as the previous ends.
+func OpenRotated(files []string) io.ReadCloser {
+	return &rotatedReader{files: files}
+}
+
+type rotatedReader struct {
+	files []string
+	f     *os.File
+	r     io.Reader
+
+	// wrap, when set, wrap every file before its decompression,
+	// e.g. to count the bytes read from the files.
+	wrap func(io.Reader) io.Reader
+}
+
+func (rr *rotatedReader) Read(p []byte) (int, error) {
+	for {
+		if rr.r == nil {
+			if len(rr.files) == 0 {
+				return 0, io.EOF
+			}
+			if err := rr.open(rr.files[0]); err != nil {
+				return 0, err
+			}
+			rr.files = rr.files[1:]
+		}
+		n, err := rr.r.Read(p)
+		if err == io.EOF {
+			if err := rr.Close(); err != nil {
+				return n, err
+			}
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (rr *rotatedReader) open(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	rr.f, rr.r = f, f
+	if rr.wrap != nil {
+		rr.r = rr.wrap(f)
+	}
+	if filepath.Ext(name) == ".gz" {
+		zr, err := gzip.NewReader(rr.r)
+		if err != nil {
+			f.Close()
+			rr.f = nil
+			return err
+		}
+		rr.r = zr
+	}
+	// A file not ending with a newline would merge its last
+	// line with the first line of the next file.
+	rr.r = &newlineReader{r: rr.r}
+	return nil
+}
+
+// Close close the file being read.
+func (rr *rotatedReader) Close() error {
+	if rr.f == nil {
+		return nil
+	}
+	err := rr.f.Close()
+	rr.f, rr.r = nil, nil
+	return err
+}
+
+// newlineReader read r, adding a newline at its end if it is missing.
+type newlineReader struct {
+	r    io.Reader
+	last byte
+	done bool
+}
+
+func (nr *newlineReader) Read(p []byte) (int, error) {
+	if nr.done {
+		return 0, io.EOF
+	}
+	n, err := nr.r.Read(p)
+	if n > 0 {
+		nr.last = p[n-1]
+	}
+	if err == io.EOF {
+		nr.done = true
+		if nr.last != '\n' && nr.last != 0 && n < len(p) {
+			p[n] = '\n'
+			n++
+		} else if nr.last != '\n' && nr.last != 0 {
+			nr.done = false
+			nr.last = '\n'
+			return n, nil
+		}
+	}
+	return n, err
+}
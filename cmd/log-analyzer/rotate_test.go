@@ -0,0 +1,84 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeRotations write the files app.log.2.gz, app.log.1 and app.log of
+// 30, 20 and 10 entries in dir, returning their paths oldest first.
+func writeRotations(tb testing.TB, dir string) []string {
+	tb.Helper()
+	files := []string{filepath.Join(dir, "app.log.2.gz"), filepath.Join(dir, "app.log.1"), filepath.Join(dir, "app.log")}
+	for i, path := range files {
+		text := logText(generateEntries(30-10*i, int64(i)))
+		f, err := os.Create(path)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if filepath.Ext(path) == ".gz" {
+			zw := gzip.NewWriter(f)
+			fmt.Fprint(zw, text)
+			err = zw.Close()
+		} else {
+			_, err = f.WriteString(text)
+		}
+		if err != nil {
+			tb.Fatal(err)
+		}
+		f.Close()
+	}
+	return files
+}
+
+// The rotations are listed and read oldest first, the highest index first,
+// the files of other names being left out.
+func TestRotations(t *testing.T) {
+	dir := t.TempDir()
+	want := writeRotations(t, dir)
+	for _, name := range []string{"app.log.old", "app.log.1.bak", "app.logs.3", "other.log.1"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("not a rotation\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := Rotations(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("got the rotations %q, want %q", files, want)
+	}
+
+	rr := OpenRotated(files)
+	defer rr.Close()
+	got, err := io.ReadAll(rr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text strings.Builder
+	for i := range files {
+		text.WriteString(logText(generateEntries(30-10*i, int64(i))))
+	}
+	if string(got) != text.String() {
+		t.Error("got the rotations content out of order")
+	}
+
+	if _, err := Rotations(filepath.Join(dir, "gone.log")); err == nil {
+		t.Error("got no error without the file nor its rotations")
+	}
+}
+
+func TestAnalyzeRotated(t *testing.T) {
+	dir := t.TempDir()
+	writeRotations(t, dir)
+	stdout, stderr, status := runMain(t, "-rotated", "-level", "info,warn,error,debug", filepath.Join(dir, "app.log"))
+	if status != 0 || !strings.Contains(stdout, "Total Log Entries: 60\n") {
+		t.Errorf("got %q, %q, exit %d, want the 60 entries of the rotations", stdout, stderr, status)
+	}
+}
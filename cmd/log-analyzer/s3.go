@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// isS3URI report whether the -output is an s3://bucket/key uri.
+func isS3URI(s string) bool {
+	return strings.HasPrefix(s, "s3://")
+}
+
+// parseS3URI return the bucket and key of an s3://bucket/key uri.
+func parseS3URI(uri string) (bucket, key string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid s3 uri %q: %w", uri, err)
+	}
+	key = strings.TrimPrefix(u.Path, "/")
+	if u.Scheme != "s3" || u.Host == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", fmt.Errorf("invalid s3 uri %q: expected 's3://bucket/key'", uri)
+	}
+	return u.Host, key, nil
+}
+
+// UploadToS3 upload data to the object of the s3://bucket/key uri, with
+// the credentials and region of the default AWS configuration, such as
+// the AWS_PROFILE or the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+// environment variables. -aws-region overrides the region and -s3-endpoint
+// the endpoint, which is then addressed with path style requests.
+func UploadToS3(ctx context.Context, uri string, data []byte) error {
+	bucket, key, err := parseS3URI(uri)
+	if err != nil {
+		return err
+	}
+	var opts []func(*config.LoadOptions) error
+	if *awsRegion != "" {
+		opts = append(opts, config.WithRegion(*awsRegion))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", uri, err)
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if *s3Endpoint != "" {
+			o.BaseEndpoint = aws.String(*s3Endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", uri, err)
+	}
+	return nil
+}
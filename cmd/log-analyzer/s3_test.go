@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// s3Mock is an S3 endpoint keeping the objects put to it by path.
+type s3Mock struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *s3Mock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDTEST/") {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	m.mu.Lock()
+	m.objects[r.URL.Path] = body
+	m.mu.Unlock()
+	w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+}
+
+// object return the content of the object put at path, nil if none was.
+func (m *s3Mock) object(path string) []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.objects[path]
+}
+
+// newS3Mock start a mock S3 endpoint as the -s3-endpoint, with
+// test credentials in the environment.
+func newS3Mock(t *testing.T) (*s3Mock, *httptest.Server) {
+	t.Helper()
+	m := &s3Mock{objects: make(map[string][]byte)}
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+	dir := t.TempDir()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	oldEndpoint, oldRegion := *s3Endpoint, *awsRegion
+	*s3Endpoint, *awsRegion = srv.URL, "eu-west-1"
+	t.Cleanup(func() { *s3Endpoint, *awsRegion = oldEndpoint, oldRegion })
+	return m, srv
+}
+
+func TestUploadToS3(t *testing.T) {
+	m, _ := newS3Mock(t)
+	if err := UploadToS3(context.Background(), "s3://reports/2025/01/report.json", []byte(`{"total_entries":3}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(m.object("/reports/2025/01/report.json")); got != `{"total_entries":3}` {
+		t.Errorf("got the object %q, want the report at /reports/2025/01/report.json", got)
+	}
+
+	*awsRegion = ""
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	if err := UploadToS3(context.Background(), "s3://reports/report.json", nil); err == nil {
+		t.Error("got no error uploading without a region")
+	}
+}
+
+func TestParseS3URI(t *testing.T) {
+	bucket, key, err := parseS3URI("s3://my-bucket/prefix/report.json")
+	if err != nil || bucket != "my-bucket" || key != "prefix/report.json" {
+		t.Errorf("got %q, %q, %v, want my-bucket and prefix/report.json", bucket, key, err)
+	}
+	for _, uri := range []string{"s3://", "s3://bucket", "s3://bucket/", "s3:///key", "s3://bucket/prefix/", "http://bucket/key", "s3://bu cket/key"} {
+		if _, _, err := parseS3URI(uri); err == nil {
+			t.Errorf("parseS3URI(%q): got no error", uri)
+		}
+	}
+}
+
+// The report written to an s3:// -output is uploaded rather than printed.
+func TestOutputS3(t *testing.T) {
+	m, srv := newS3Mock(t)
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO Started",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+	)
+	stdout, stderr, status := runMain(t, "-format", "json", "-level", "info,error", "-aws-region", "eu-west-1", "-s3-endpoint", srv.URL, "-output", "s3://audit/logs/report.json", path)
+	if status != 0 || stdout != "" {
+		t.Fatalf("got %q, %q, exit %d, want the report uploaded", stdout, stderr, status)
+	}
+	var report AnalysisReport
+	object := m.object("/audit/logs/report.json")
+	if err := json.Unmarshal(object, &report); err != nil || report.TotalEntries != 2 {
+		t.Errorf("got the object %q, %v, want the json report of 2 entries", object, err)
+	}
+
+	if _, stderr, status := runMain(t, "-format", "influxdb", "-level", "error", "-s3-endpoint", srv.URL, "-output", "s3://audit/errors.lp", path); status != 0 {
+		t.Fatalf("got %q, exit %d", stderr, status)
+	}
+	if got := string(m.object("/audit/errors.lp")); !strings.Contains(got, "Connection lost") {
+		t.Errorf("got the object %q, want the exported error entry", got)
+	}
+
+	if _, stderr, status := runMain(t, "-output", "s3://audit", path); status == 0 || !strings.Contains(stderr, "invalid s3 uri") {
+		t.Errorf("got %q, exit %d for an s3 uri without key, want an error", stderr, status)
+	}
+}
+
+// The report is written to a local -output file as well.
+func TestOutputFile(t *testing.T) {
+	path := writeLines(t, "app.log", "2025-01-01 10:00:00 INFO Started")
+	out := filepath.Join(t.TempDir(), "report.md")
+	if stdout, stderr, status := runMain(t, "-format", "markdown", "-output", out, path); status != 0 || stdout != "" {
+		t.Fatalf("got %q, %q, exit %d", stdout, stderr, status)
+	}
+	if b, err := os.ReadFile(out); err != nil || !strings.HasPrefix(string(b), "# Log Analysis Report") {
+		t.Errorf("got %q, %v, want the markdown report", b, err)
+	}
+}
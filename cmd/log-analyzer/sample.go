@@ -0,0 +1,86 @@
+package main
+
+import "math/rand/v2"
+
+// defaultSampleSize is the number of response times sampled by default
+// when they are counted by a quantile sketch, see WithResponseSample.
+const defaultSampleSize = 10_000
+
+// reservoir keep a uniform sample of at most Size values of a stream of
+// unknown length, each value seen having the same probability to be in the
+// sample. The values are drawn from a generator seeded with Seed, so the
+// same stream gives the same sample.
+type reservoir struct {
+	Size int   `json:"size"`
+	Seen int   `json:"seen"`
+	Seed int64 `json:"seed"`
+	// Values are the sampled values, saved as the report ResponseSample.
+	Values []float64 `json:"-"`
+	pcg    *rand.PCG
+	rng    *rand.Rand
+}
+
+func newReservoir(size int, seed int64) *reservoir {
+	return &reservoir{Size: size, Seed: seed}
+}
+
+// rand return the generator of the sample, seeded on first use.
+func (s *reservoir) rand() *rand.Rand {
+	if s.rng == nil {
+		s.pcg = rand.NewPCG(uint64(s.Seed), uint64(s.Seen))
+		s.rng = rand.New(s.pcg)
+	}
+	return s.rng
+}
+
+// Add add v to the stream, replacing a random value of the full sample with
+// probability Size/Seen (Vitter's algorithm R).
+func (s *reservoir) Add(v float64) {
+	s.Seen++
+	if len(s.Values) < s.Size {
+		s.Values = append(s.Values, v)
+		return
+	}
+	if i := s.rand().IntN(s.Seen); i < s.Size {
+		s.Values[i] = v
+	}
+}
+
+// Merge replace the sample by a sample of both streams, as if the values of
+// other had been added to s. Which stream each merged value comes from is
+// drawn in proportion of the values of the streams not drawn yet, as drawing
+// from the concatenated streams would, the values then being drawn at random
+// from the sample of their stream.
+func (s *reservoir) Merge(other *reservoir) {
+	a := append([]float64(nil), s.Values...)
+	b := append([]float64(nil), other.Values...)
+	na, nb := s.Seen, other.Seen
+	merged := make([]float64, 0, min(s.Size, len(a)+len(b)))
+	rng := s.rand()
+	for len(merged) < cap(merged) {
+		from := &b
+		if rng.IntN(na+nb) < na {
+			from, na = &a, na-1
+		} else {
+			nb--
+		}
+		i := rng.IntN(len(*from))
+		merged = append(merged, (*from)[i])
+		last := len(*from) - 1
+		(*from)[i] = (*from)[last]
+		*from = (*from)[:last]
+	}
+	s.Values = merged
+	s.Seen += other.Seen
+}
+
+// clone return a copy of the sample, drawing the same values from then on.
+func (s *reservoir) clone() *reservoir {
+	c := *s
+	c.Values = append([]float64(nil), s.Values...)
+	if s.pcg != nil {
+		pcg := *s.pcg
+		c.pcg, c.rng = &pcg, rand.New(&pcg)
+	}
+	return &c
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// The sample mean is close to the mean of the stream, both of the values and
+// of their positions, the sample favoring neither the first nor the last ones.
+func TestReservoirMean(t *testing.T) {
+	const n, size = 1_000_000, 10_000
+	rng := rand.New(rand.NewPCG(1, 2))
+	values := make([]float64, n)
+	positions := newReservoir(size, 7)
+	sample := newReservoir(size, 7)
+	for i := range values {
+		values[i] = rng.ExpFloat64() * 100 // response times of mean 100 ms
+		sample.Add(values[i])
+		positions.Add(float64(i))
+	}
+	if len(sample.Values) != size || sample.Seen != n {
+		t.Fatalf("got %d values of %d seen, want %d of %d", len(sample.Values), sample.Seen, size, n)
+	}
+	// The standard error of the mean is sigma/sqrt(size), 1 ms for the
+	// values and about 2887 for the positions, checked within 5 of them.
+	if got, want := mean(sample.Values), mean(values); math.Abs(got-want) > 5 {
+		t.Errorf("got a sample mean of %.2f, want %.2f ± 5", got, want)
+	}
+	if got := mean(positions.Values); math.Abs(got-n/2) > 15_000 {
+		t.Errorf("got a mean position of %.0f, want %d ± 15000", got, n/2)
+	}
+}
+
+func TestReservoirSeed(t *testing.T) {
+	sample := func(seed int64) []float64 {
+		s := newReservoir(100, seed)
+		for i := 0; i < 10_000; i++ {
+			s.Add(float64(i))
+		}
+		return s.Values
+	}
+	if !slices.Equal(sample(1), sample(1)) {
+		t.Error("got different samples with the same seed")
+	}
+	if slices.Equal(sample(1), sample(2)) {
+		t.Error("got the same sample with different seeds")
+	}
+	short := newReservoir(100, 1)
+	for i := 0; i < 10; i++ {
+		short.Add(float64(i))
+	}
+	if len(short.Values) != 10 {
+		t.Errorf("got %d values, want all the 10 values of a stream shorter than the sample", len(short.Values))
+	}
+}
+
+// The merged sample draws from each sample in proportion of its stream,
+// a stream three times longer giving about three quarters of the values.
+func TestReservoirMerge(t *testing.T) {
+	const size = 10_000
+	long, short := newReservoir(size, 1), newReservoir(size, 2)
+	for i := 0; i < 300_000; i++ {
+		long.Add(0)
+	}
+	for i := 0; i < 100_000; i++ {
+		short.Add(1)
+	}
+	long.Merge(short)
+	if len(long.Values) != size || long.Seen != 400_000 {
+		t.Fatalf("got %d values of %d seen, want %d of 400000", len(long.Values), long.Seen, size)
+	}
+	// The standard error of the fraction is about 0.0043.
+	if got := mean(long.Values); math.Abs(got-0.25) > 0.02 {
+		t.Errorf("got %.3f of the values from the short stream, want 0.25 ± 0.02", got)
+	}
+}
+
+func TestResponseTimeSample(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&b, "2025-01-01 10:00:00 INFO Request processed in %d ms\n", i)
+	}
+	exact, err := AnalyzeReader(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := exact.ResponseTimeSample(); len(got) != 1000 {
+		t.Errorf("got %d response times, want all the 1000 kept without a sketch", len(got))
+	}
+
+	opts := []Option{WithQuantileAccuracy(0.01), WithResponseSample(100, 3)}
+	report, err := AnalyzeReader(strings.NewReader(b.String()), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := report.ResponseTimeSample()
+	if len(sample) != 100 || len(report.ResponseTime) != 0 {
+		t.Fatalf("got %d sampled and %d kept response times, want 100 sampled", len(sample), len(report.ResponseTime))
+	}
+	again, _ := AnalyzeReader(strings.NewReader(b.String()), opts...)
+	if !slices.Equal(again.ResponseTimeSample(), sample) {
+		t.Error("got a different sample of the same input with the same seed")
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.ResponseTimeSample(), sample) || loaded.sample == nil || loaded.sample.Seen != 1000 {
+		t.Errorf("got the sample %v loaded, want the saved one of 1000 response times", loaded.ResponseTimeSample())
+	}
+	if err := loaded.Merge(again); err != nil || len(loaded.ResponseTimeSample()) != 100 || loaded.sample.Seen != 2000 {
+		t.Errorf("got %v merging the loaded sample, want 100 of 2000 response times", err)
+	}
+
+	// Samples of different sizes are not merged, a sample of the
+	// smaller size missing values to draw the larger one from.
+	small, err := AnalyzeReader(strings.NewReader(b.String()), WithQuantileAccuracy(0.01), WithResponseSample(2, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := again.Merge(small); err == nil || len(again.ResponseTimeSample()) != 100 || again.sample.Seen != 1000 {
+		t.Errorf("got %v merging samples of 100 and 2 response times, want an error leaving the report as is", err)
+	}
+
+	if report, _ := AnalyzeReader(strings.NewReader(b.String()), WithQuantileAccuracy(0.01), WithResponseSample(0, 1)); report.ResponseTimeSample() != nil {
+		t.Error("got a sample of size 0")
+	}
+}
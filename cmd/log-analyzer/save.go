@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// reportVersion is the version of the saved report schema, it must be
+// incremented whenever a change makes older saved reports unreadable, or
+// the saved reports misread by older versions, e.g. losing a setting needed
+// to merge them. Version 2 added the max message length, version 3 the
+// bounded message counts.
+const reportVersion = 3
+
+// savedReport is the saved form of a report, holding along with it the
+// analysis settings needed to merge it with other reports.
+type savedReport struct {
+	Version     int             `json:"version"`
+	Report      *AnalysisReport `json:"report"`
+	Normalize   bool            `json:"normalize"`
+	Group       string          `json:"group_regex,omitempty"`
+	EmptyMsgs   bool            `json:"include_empty,omitempty"`
+	MaxLen      int             `json:"max_message_len,omitempty"`
+	Interval    time.Duration   `json:"interval"`
+	TopN        int             `json:"top_n"`
+	Percentiles []float64       `json:"percentiles"`
+	Buckets     []TimeBucket    `json:"buckets"`
+	// TimeRun is the last entries in a row sharing a timestamp, for the
+	// entries added after Load.
+	TimeRun TimeBucket      `json:"time_run"`
+	Sketch  *quantileSketch `json:"sketch,omitempty"`
+	// Messages are the bounded message counts, of which the report
+	// MsgFrequency holds the guaranteed ones, see WithMaxUniqueMessages.
+	Messages *spaceSaving `json:"bounded_messages,omitempty"`
+	// Sample is the response time sample, its values being the report
+	// ResponseSample.
+	Sample *reservoir `json:"response_sample,omitempty"`
+	// MetricSketches are the quantile sketches of the report Metrics.
+	MetricSketches map[string]*quantileSketch `json:"metric_sketches,omitempty"`
+}
+
+// Save write the report to the file at path, replacing it atomically,
+// so it can be read back with Load.
+func (r *AnalysisReport) Save(path string) error {
+	saved := savedReport{
+		Version:     reportVersion,
+		Report:      r,
+		Normalize:   r.normalize,
+		Group:       groupPattern(r.group),
+		EmptyMsgs:   r.includeEmpty,
+		MaxLen:      r.maxLen,
+		Interval:    r.interval,
+		TopN:        r.topN,
+		Percentiles: r.percentiles,
+		Buckets:     timeBuckets(r.buckets),
+		TimeRun:     r.timeRun,
+		Sketch:      r.sketch,
+		Messages:    r.messages,
+		Sample:      r.sample,
+	}
+	for name, m := range r.Metrics {
+		if saved.MetricSketches == nil {
+			saved.MetricSketches = make(map[string]*quantileSketch, len(r.Metrics))
+		}
+		saved.MetricSketches[name] = m.sketch
+	}
+	b, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load read a report written by Save. Fields unknown to this version
+// are ignored, but a report of a newer schema version is rejected.
+func Load(path string) (*AnalysisReport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	saved := savedReport{Report: NewAnalysisReport()}
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return nil, fmt.Errorf("load %s: corrupted report: %w", path, err)
+	}
+	switch {
+	case saved.Version == 0:
+		return nil, fmt.Errorf("load %s: not a saved report", path)
+	case saved.Version > reportVersion:
+		return nil, fmt.Errorf("load %s: unsupported report version %d, expected at most %d", path, saved.Version, reportVersion)
+	case saved.Report == nil || saved.Report.MsgFrequency == nil:
+		return nil, fmt.Errorf("load %s: corrupted report: missing report data", path)
+	case saved.Sketch != nil && (saved.Sketch.Accuracy <= 0 || saved.Sketch.Accuracy >= 1):
+		return nil, fmt.Errorf("load %s: corrupted report: invalid quantile accuracy %g", path, saved.Sketch.Accuracy)
+	case saved.Messages != nil && (saved.Messages.capacity < 1 || len(saved.Messages.items) != len(saved.Messages.heap) || len(saved.Messages.heap) > saved.Messages.capacity):
+		return nil, fmt.Errorf("load %s: corrupted report: invalid bounded message counts", path)
+	}
+
+	r := saved.Report
+	if r.Sources == nil {
+		r.Sources = make(map[string]int)
+	}
+	r.normalize = saved.Normalize
+	if saved.Group != "" {
+		if r.group, err = regexp.Compile(saved.Group); err != nil {
+			return nil, fmt.Errorf("load %s: corrupted report: %w", path, err)
+		}
+	}
+	r.includeEmpty = saved.EmptyMsgs
+	r.maxLen = saved.MaxLen
+	r.interval = saved.Interval
+	r.topN = saved.TopN
+	r.percentiles = saved.Percentiles
+	r.sketch = saved.Sketch
+	r.messages = saved.Messages
+	if s := saved.Sample; s != nil && saved.Sketch != nil && s.Size > 0 && len(r.ResponseSample) == min(s.Size, s.Seen) {
+		r.sample = s
+		r.sample.Values = r.ResponseSample
+	}
+	for name, m := range r.Metrics {
+		if s := saved.MetricSketches[name]; s != nil && s.Accuracy > 0 && s.Accuracy < 1 {
+			m.sketch = s
+		}
+	}
+	if r.ResponseCount == 0 && len(r.ResponseTime) > 0 {
+		// Saved before the response times were summarized.
+		for _, v := range r.ResponseTime {
+			r.ResponseCount++
+			r.ResponseSum += v
+			if r.ResponseCount == 1 || v < r.ResponseMin {
+				r.ResponseMin = v
+			}
+			r.ResponseMax = max(r.ResponseMax, v)
+		}
+	}
+	if len(saved.Buckets) > 0 {
+		r.buckets = make(map[time.Time]int, len(saved.Buckets))
+		for _, b := range saved.Buckets {
+			r.buckets[b.Start] = b.Count
+		}
+	}
+	r.timeRun = saved.TimeRun
+	return r, nil
+}
+
+// timeBuckets return the counts of m as time buckets in chronological order.
+func timeBuckets(m map[time.Time]int) []TimeBucket {
+	buckets := make([]TimeBucket, 0, len(m))
+	for t, n := range m {
+		buckets = append(buckets, TimeBucket{Start: t, Count: n})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
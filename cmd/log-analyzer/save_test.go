@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	opts := []Option{WithTopN(3), WithPercentiles(50, 99), WithInterval(time.Hour), WithNormalization(true), WithGroupRegex(regexp.MustCompile(`^(\w+ \w+)`)), WithMaxMessageLen(20)}
+	entries := generateEntries(300, 0)
+	report, err := Analyze(entries[:200], opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(report)
+	if got, _ := json.Marshal(loaded); string(got) != string(want) {
+		t.Errorf("got the loaded report\n%s\nwant\n%s", got, want)
+	}
+
+	// The settings of the analysis are loaded too, so the loaded
+	// report can be merged as the report saved.
+	rest, err := Analyze(entries[200:], opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Merge(rest); err != nil {
+		t.Fatal(err)
+	}
+	whole, err := Analyze(entries, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ = json.Marshal(whole)
+	if got, _ := json.Marshal(loaded); string(got) != string(want) {
+		t.Errorf("got the merged loaded report\n%s\nwant\n%s", got, want)
+	}
+}
+
+// The bounded message counts are saved, so the loaded report is
+// merged as the report saved, its counts staying approximate.
+func TestSaveLoadMaxUniqueMessages(t *testing.T) {
+	entries := highCardinalityEntries(10_000, 4)
+	opts := []Option{WithMaxUniqueMessages(50), WithTopN(5)}
+	report, err := Analyze(entries[:6000], opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.ApproximateMessages || reportJSON(t, loaded) != reportJSON(t, report) {
+		t.Fatalf("got the loaded report\n%s\nwant\n%s", reportJSON(t, loaded), reportJSON(t, report))
+	}
+
+	rest, err := Analyze(entries[6000:], opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Merge(rest); err != nil {
+		t.Fatal(err)
+	}
+	if err := report.Merge(rest); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reportJSON(t, loaded), reportJSON(t, report); got != want {
+		t.Errorf("got the merged loaded report\n%s\nwant\n%s", got, want)
+	}
+	for i := 0; i < 10; i++ {
+		if msg := fmt.Sprintf("Connection lost %d", i); loaded.MsgFrequency[msg] == 0 {
+			t.Errorf("%q is not tracked in the merged loaded report", msg)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	report, err := Analyze(generateEntries(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := filepath.Join(dir, "valid.json")
+	if err := report.Save(valid); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name, content, want string
+	}{
+		{"newer", strings.Replace(string(b), fmt.Sprintf(`"version":%d`, reportVersion), `"version":99`, 1), "unsupported report version 99"},
+		{"corrupt", string(b[:len(b)/2]), "corrupted report"},
+		{"missing data", `{"version":1,"report":null}`, "missing report data"},
+		{"not a report", `{"total_entries":3}`, "not a saved report"},
+		{"bounded messages", fmt.Sprintf(`{"version":%d,"report":{"message_frequency":{}},"bounded_messages":{"capacity":1,"items":[{"key":"a","count":1},{"key":"b","count":1}]}}`, reportVersion), "invalid bounded message counts"},
+	} {
+		path := filepath.Join(dir, tt.name+".json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error of %q", tt.name, err, tt.want)
+		}
+	}
+	if _, err := Load(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got %v for a missing file, want fs.ErrNotExist", err)
+	}
+}
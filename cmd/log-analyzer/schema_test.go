@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReportSchema(t *testing.T) {
+	var b strings.Builder
+	if err := WriteSchema(&b); err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Type != "object" {
+		t.Errorf("got the type %q, want object", schema.Type)
+	}
+	for _, name := range []string{"total_entries", "info", "warn", "error", "debug", "message_frequency", "first_entry", "last_entry"} {
+		if _, ok := schema.Properties[name]; !ok || !slices.Contains(schema.Required, name) {
+			t.Errorf("got no required property %q", name)
+		}
+	}
+	if got := string(schema.Properties["top_messages"]); !strings.Contains(got, `"count"`) || !strings.Contains(got, `"array"`) {
+		t.Errorf("got the top messages %s, want an array of messages with their count", got)
+	}
+
+	// Every field of a report with all the options is described.
+	report, err := Analyze(generateEntries(100, 0), WithTopN(3), WithPercentiles(50), WithSkipReasons(true), WithMaxEntries(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := report.WriteJSON(&out, false); err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(out.String()), &fields); err != nil {
+		t.Fatal(err)
+	}
+	for name := range fields {
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("got no property for the report field %q", name)
+		}
+	}
+}
+
+func TestPrintSchemaFlag(t *testing.T) {
+	stdout, stderr, status := runMain(t, "-print-schema")
+	if status != 0 || !strings.Contains(stdout, `"$schema"`) || !strings.Contains(stdout, `"total_entries"`) {
+		t.Errorf("got %q, %q, exit %d", stdout, stderr, status)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ReportServer serve the latest analysis report over HTTP.
+// The served report is a snapshot which is never modified once set,
+// so concurrent requests always get a consistent report.
+type ReportServer struct {
+	mu     sync.RWMutex
+	report *AnalysisReport
+}
+
+func NewReportServer() *ReportServer {
+	return &ReportServer{report: NewAnalysisReport()}
+}
+
+// Set replace the served report with a copy of r.
+func (s *ReportServer) Set(r *AnalysisReport) {
+	c := r.Clone()
+	s.mu.Lock()
+	s.report = c
+	s.mu.Unlock()
+}
+
+// Report return the served report snapshot, it must not be modified.
+func (s *ReportServer) Report() *AnalysisReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report
+}
+
+// Handler return the handler serving the report as json at /report,
+// in the human readable format at /report.txt, the Prometheus metrics
+// at /metrics and a health check at /healthz.
+func (s *ReportServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := s.Report().WriteJSON(w, r.URL.Query().Has("pretty")); err != nil {
+			log.Println("failed to write report: ", err)
+		}
+	})
+	mux.HandleFunc("GET /report.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		report := s.Report()
+		if report.TotalEntries == 0 {
+			fmt.Fprintln(w, "No log entries yet")
+			return
+		}
+		if err := report.Fprint(w); err != nil {
+			log.Println("failed to write report: ", err)
+		}
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		MetricsHandler(s.Report())(w, r)
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// serve run the serve subcommand, analyzing the file in follow
+// mode and serving the report until interrupted.
+func serve(args []string) {
+	fs := newFlagSet("serve", parseFlags, filterFlags, reportFlags)
+	listen := fs.String("listen", ":8080", "address to listen on")
+	refresh := fs.Duration("interval", time.Second, "interval at which the served report is refreshed")
+	fs.Parse(args)
+	_, opts := setup()
+
+	file := fs.Arg(0)
+	if file == "" {
+		log.Fatalln("arg: file name is required")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		log.Fatalln("failed to open file: ", err)
+	}
+	defer f.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rs := NewReportServer()
+	lines := make(chan string, batchSize)
+	go func() {
+		if err := Tail(ctx, f, lines); err != nil && !errors.Is(err, ctx.Err()) {
+			log.Println("failed to read file: ", err)
+		}
+	}()
+	ticker := time.NewTicker(*refresh)
+	defer ticker.Stop()
+	follower := &Follower{Options: opts, Emit: rs.Set}
+	go follower.Run(ctx, lines, ticker.C)
+
+	log.Printf("serving report of %s on %s", file, *listen)
+	if err := listenAndServe(ctx, *listen, rs.Handler()); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// listenAndServe serve handler on addr until ctx is done,
+// then shut the server down gracefully.
+func listenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
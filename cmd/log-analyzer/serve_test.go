@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestReportServer(t *testing.T) (*ReportServer, *httptest.Server) {
+	t.Helper()
+	rs := NewReportServer()
+	srv := httptest.NewServer(rs.Handler())
+	t.Cleanup(srv.Close)
+	return rs, srv
+}
+
+// testReport return the report of the lines.
+func testReport(t *testing.T, lines ...string) *AnalysisReport {
+	t.Helper()
+	var entries []LogEntry
+	for _, line := range lines {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	report, err := Analyze(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestReportServerReport(t *testing.T) {
+	rs, srv := newTestReportServer(t)
+	if status, contentType, body := get(t, srv, "/report", ""); status != http.StatusOK || contentType != "application/json" || !strings.HasPrefix(body, `{"total_entries":0,`) {
+		t.Errorf("got %d %q %q, want the json of an empty report", status, contentType, body)
+	}
+
+	rs.Set(testReport(t,
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+	))
+	status, _, body := get(t, srv, "/report", "")
+	var got struct {
+		TotalEntries int `json:"total_entries"`
+		Error        int `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(body), &got); status != http.StatusOK || err != nil || got.TotalEntries != 2 || got.Error != 1 {
+		t.Errorf("got %d %q, %v, want the json of the 2 entries", status, body, err)
+	}
+	if strings.Contains(body, "\n  ") {
+		t.Errorf("got the indented report %q without ?pretty", body)
+	}
+	if _, _, body := get(t, srv, "/report?pretty", ""); !strings.HasPrefix(body, "{\n  \"total_entries\": 2,") {
+		t.Errorf("got %q, want the indented report with ?pretty", body)
+	}
+}
+
+func TestReportServerText(t *testing.T) {
+	rs, srv := newTestReportServer(t)
+	if status, contentType, body := get(t, srv, "/report.txt", ""); status != http.StatusOK || contentType != "text/plain; charset=utf-8" || body != "No log entries yet\n" {
+		t.Errorf("got %d %q %q, want no entries yet", status, contentType, body)
+	}
+	rs.Set(testReport(t, "2025-01-01 10:00:00 INFO Request processed in 10 ms"))
+	if _, _, body := get(t, srv, "/report.txt", ""); !strings.HasPrefix(body, "Total Log Entries: 1\n") {
+		t.Errorf("got %q, want the human readable report", body)
+	}
+}
+
+func TestReportServerMetrics(t *testing.T) {
+	rs, srv := newTestReportServer(t)
+	rs.Set(testReport(t,
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+	))
+	status, _, body := get(t, srv, "/metrics", "")
+	if samples := parseMetrics(t, body); status != http.StatusOK || samples["loganalyzer_total_entries"] != 2 || samples[`loganalyzer_level_total{level="error"}`] != 1 {
+		t.Errorf("got %d\n%s\nwant the metrics of the served report", status, body)
+	}
+}
+
+func TestReportServerHealth(t *testing.T) {
+	_, srv := newTestReportServer(t)
+	if status, _, body := get(t, srv, "/healthz", ""); status != http.StatusOK || body != "ok\n" {
+		t.Errorf("got %d %q, want 200 ok", status, body)
+	}
+	if status, _, _ := get(t, srv, "/missing", ""); status != http.StatusNotFound {
+		t.Errorf("got %d for a missing page, want %d", status, http.StatusNotFound)
+	}
+	resp, err := srv.Client().Post(srv.URL+"/report", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("got %d posting the report, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+// The served report is a snapshot, unchanged by the report it was set from.
+func TestReportServerSnapshot(t *testing.T) {
+	rs, srv := newTestReportServer(t)
+	report := testReport(t, "2025-01-01 10:00:00 INFO Request processed in 10 ms")
+	rs.Set(report)
+	entry, err := NewLogEntry("2025-01-01 10:00:01 ERROR Connection lost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report.Add(entry)
+	if _, _, body := get(t, srv, "/report.txt", ""); !strings.HasPrefix(body, "Total Log Entries: 1\n") {
+		t.Errorf("got %q, want the report as it was set", body)
+	}
+}
+
+// The reports requested while being refreshed are whole reports.
+func TestReportServerConcurrent(t *testing.T) {
+	rs, srv := newTestReportServer(t)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		report := NewAnalysisReport()
+		for _, entry := range generateEntries(200, 0) {
+			report.Add(entry)
+			rs.Set(report)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		_, _, body := get(t, srv, "/report", "")
+		var got struct {
+			TotalEntries int `json:"total_entries"`
+			Info         int `json:"info"`
+			Warn         int `json:"warn"`
+			Error        int `json:"error"`
+			Debug        int `json:"debug"`
+		}
+		if err := json.Unmarshal([]byte(body), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Info+got.Warn+got.Error+got.Debug != got.TotalEntries {
+			t.Errorf("got the inconsistent report %+v", got)
+		}
+	}
+	<-done
+}
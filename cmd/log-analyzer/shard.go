@@ -0,0 +1,57 @@
+package main
+
+import "fmt"
+
+// ShardedReport accumulate the entries added by concurrent goroutines
+// without any lock, each goroutine adding to its own shard, see the
+// concurrency contract of AnalysisReport. Once all the goroutines are done,
+// Report merges the shards into the report of all the entries.
+//
+// Unlike the pipeline of AnalyzeContext the entries are not added in input
+// order, so the statistics depending on the order, such as which of the
+// longest messages of the same length is kept, may differ from those of a
+// serial analysis.
+type ShardedReport struct {
+	opts   *options
+	shards []*AnalysisReport
+}
+
+// NewShardedReport return a report of n shards analyzing the entries with
+// the options, see Analyze. The filters are not applied by the shards.
+func NewShardedReport(n int, opts ...Option) (*ShardedReport, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of shards %d: must be positive", n)
+	}
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &ShardedReport{opts: o, shards: make([]*AnalysisReport, n)}
+	for i := range s.shards {
+		s.shards[i] = o.newReport()
+	}
+	return s, nil
+}
+
+// Len return the number of shards.
+func (s *ShardedReport) Len() int {
+	return len(s.shards)
+}
+
+// Shard return the i-th shard, which must only be used by one goroutine
+// at a time until Report is called.
+func (s *ShardedReport) Shard(i int) *AnalysisReport {
+	return s.shards[i]
+}
+
+// Report return a new report merging all the shards. It must not be called
+// while the shards are being added to, the shards are left unchanged.
+func (s *ShardedReport) Report() (*AnalysisReport, error) {
+	report := s.opts.newReport()
+	for _, shard := range s.shards {
+		if err := report.Merge(shard); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
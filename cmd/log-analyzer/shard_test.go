@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Goroutines adding to their own shard and merging the same report into it
+// give the report of the serial analysis, run with -race to check the
+// concurrency contract of AnalysisReport.
+func TestShardedReport(t *testing.T) {
+	const shards, mergeEvery = 8, 100
+	entries := generateEntries(4000, 1)
+	opts := []Option{WithTopN(5), WithPercentiles(50, 99), WithInterval(time.Minute)}
+	base, err := Analyze(entries[:100], opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sharded, err := NewShardedReport(shards, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < sharded.Len(); i++ {
+		wg.Add(1)
+		go func(shard *AnalysisReport) {
+			defer wg.Done()
+			for j := i; j < len(entries); j += shards {
+				shard.Add(entries[j])
+				if j/shards%mergeEvery == 0 {
+					if err := shard.Merge(base); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}(sharded.Shard(i))
+	}
+	wg.Wait()
+	got, err := sharded.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The base report was merged once per mergeEvery entries of each shard.
+	all := append([]LogEntry(nil), entries...)
+	for i := 0; i < len(entries)/mergeEvery; i++ {
+		all = append(all, entries[:100]...)
+	}
+	want, err := Analyze(all, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalEntries != want.TotalEntries || got.Info != want.Info || got.Warn != want.Warn || got.Error != want.Error || got.Debug != want.Debug {
+		t.Errorf("got %d entries (%d info, %d warn, %d error, %d debug), want %d (%d, %d, %d, %d)",
+			got.TotalEntries, got.Info, got.Warn, got.Error, got.Debug, want.TotalEntries, want.Info, want.Warn, want.Error, want.Debug)
+	}
+	if !reflect.DeepEqual(got.MsgFrequency, want.MsgFrequency) || !reflect.DeepEqual(got.Top, want.Top) {
+		t.Errorf("got the top messages %v, want %v", got.Top, want.Top)
+	}
+	if !reflect.DeepEqual(got.Percentiles, want.Percentiles) || got.ResponseCount != want.ResponseCount || got.ResponseSum != want.ResponseSum {
+		t.Errorf("got the percentiles %v of %d response times, want %v of %d", got.Percentiles, got.ResponseCount, want.Percentiles, want.ResponseCount)
+	}
+	if !reflect.DeepEqual(got.Timeline, want.Timeline) || !got.FirstEntry.Equal(want.FirstEntry) || !got.LastEntry.Equal(want.LastEntry) {
+		t.Errorf("got the timeline %v, want %v", got.Timeline, want.Timeline)
+	}
+	// The runs of entries sharing a timestamp are split among the shards.
+	if got.MaxSameTime < 1 || got.MaxSameTime > want.MaxSameTime {
+		t.Errorf("got %d entries sharing %s, want 1 to %d", got.MaxSameTime, got.MaxSameTimeAt, want.MaxSameTime)
+	}
+
+	// The shards are left unchanged by Report.
+	var total int
+	for i := 0; i < sharded.Len(); i++ {
+		total += sharded.Shard(i).TotalEntries
+	}
+	if total != got.TotalEntries {
+		t.Errorf("got %d entries in the shards, want %d", total, got.TotalEntries)
+	}
+	if _, err := NewShardedReport(0); err == nil {
+		t.Error("accepted a report without shards")
+	}
+}
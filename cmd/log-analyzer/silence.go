@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// SilencePeriod is a period without any entry, from
+// the entry at Start to the next one at End.
+type SilencePeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration return the length of the period.
+func (p SilencePeriod) Duration() time.Duration {
+	return p.End.Sub(p.Start)
+}
+
+// SilenceDetector find the periods in which a service which should log
+// regularly, e.g. at least once per minute, did not log anything.
+type SilenceDetector struct {
+	// Threshold is the longest gap between two entries which is not
+	// a silence.
+	Threshold time.Duration
+}
+
+// Check return the periods between consecutive entries longer than the
+// threshold, in the order of the entries which are expected to be
+// chronological.
+func (d SilenceDetector) Check(entries []LogEntry) []SilencePeriod {
+	var periods []SilencePeriod
+	for i := 1; i < len(entries); i++ {
+		start, end := entries[i-1].time, entries[i].time
+		if end.Sub(start) > d.Threshold {
+			periods = append(periods, SilencePeriod{Start: start, End: end})
+		}
+	}
+	return periods
+}
+
+// PrintSilences write one line per silence period
+// longer than threshold with its duration.
+func PrintSilences(w io.Writer, periods []SilencePeriod, threshold time.Duration) error {
+	if len(periods) == 0 {
+		_, err := fmt.Fprintf(w, "No silence longer than %s\n", threshold)
+		return err
+	}
+	fmt.Fprintf(w, "Silences longer than %s:\n", threshold)
+	for _, p := range periods {
+		if _, err := fmt.Fprintf(w, "  %s - %s %s\n", p.Start.Format(time.DateTime), p.End.Format(time.DateTime), p.Duration()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// silenceLines return the lines of an hour of entries logged every minute
+// from 10:00, but for the 10 minutes of silence between 10:20 and 10:30.
+func silenceLines() []string {
+	var lines []string
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	for m := 0; m <= 60; m++ {
+		if m > 20 && m < 30 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s INFO Heartbeat", start.Add(time.Duration(m)*time.Minute).Format(time.DateTime)))
+	}
+	return lines
+}
+
+func TestSilenceDetector(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range silenceLines() {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	gap := SilencePeriod{
+		Start: time.Date(2025, 1, 1, 10, 20, 0, 0, time.UTC),
+		End:   time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC),
+	}
+	for _, tt := range []struct {
+		threshold time.Duration
+		want      []SilencePeriod
+	}{
+		{time.Minute, []SilencePeriod{gap}},
+		{5 * time.Minute, []SilencePeriod{gap}},
+		{10 * time.Minute, nil},
+		{time.Hour, nil},
+	} {
+		got := SilenceDetector{Threshold: tt.threshold}.Check(entries)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("threshold %s: got %v, want %v", tt.threshold, got, tt.want)
+		}
+	}
+	if got := (SilenceDetector{Threshold: time.Minute}).Check(entries[:1]); got != nil {
+		t.Errorf("got %v for a single entry, want no silence", got)
+	}
+}
+
+func TestFailOnSilence(t *testing.T) {
+	path := writeLines(t, "app.log", silenceLines()...)
+	for _, tt := range []struct {
+		args   []string
+		status int
+		out    string
+	}{
+		{[]string{"-silence-threshold", "5m"}, 0, "2025-01-01 10:20:00 - 2025-01-01 10:30:00 10m0s"},
+		{[]string{"-silence-threshold", "5m", "-fail-on-silence"}, exitSilenceStatus, "Silences longer than 5m0s"},
+		{[]string{"-silence-threshold", "15m", "-fail-on-silence"}, 0, "No silence longer than 15m0s"},
+	} {
+		stdout, stderr, status := runMain(t, append(tt.args, path)...)
+		if status != tt.status || !strings.Contains(stdout, tt.out) {
+			t.Errorf("%q: got %q, exit %d, want %q, exit %d\n%s", tt.args, stdout, status, tt.out, tt.status, stderr)
+		}
+	}
+}
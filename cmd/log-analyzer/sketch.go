@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// sketchMinValue is the smallest value the sketch distinguishes,
+// values below it, including zero, are counted as zero.
+const sketchMinValue = 1e-9
+
+// quantileSketch estimate the quantiles of a stream of positive values
+// in memory logarithmic in their range rather than linear in their number.
+// Values are counted in buckets of exponentially growing width so any
+// estimated quantile is within a relative error of Accuracy of the exact
+// one, e.g. with an accuracy of 0.01 the p99 of values whose exact p99 is
+// 200 ms is estimated between 198 and 202 ms. Counting 1 µs to 1 hour
+// response times takes at most about 1100 buckets at that accuracy.
+// Sketches of the same accuracy merge exactly, as if all the values had
+// been added to one.
+type quantileSketch struct {
+	Accuracy float64     `json:"accuracy"`
+	Zero     int         `json:"zero"`
+	Buckets  map[int]int `json:"buckets"`
+	count    int
+}
+
+func newQuantileSketch(accuracy float64) *quantileSketch {
+	return &quantileSketch{Accuracy: accuracy, Buckets: make(map[int]int)}
+}
+
+// gamma is the ratio between the bounds of a bucket.
+func (s *quantileSketch) gamma() float64 {
+	return (1 + s.Accuracy) / (1 - s.Accuracy)
+}
+
+// Add count n occurrences of v.
+func (s *quantileSketch) Add(v float64, n int) {
+	s.count += n
+	if v < sketchMinValue {
+		s.Zero += n
+		return
+	}
+	if s.Buckets == nil {
+		s.Buckets = make(map[int]int)
+	}
+	s.Buckets[int(math.Ceil(math.Log(v)/math.Log(s.gamma())))] += n
+}
+
+// Count return the number of values added.
+func (s *quantileSketch) Count() int {
+	if s.count == 0 && (s.Zero > 0 || len(s.Buckets) > 0) {
+		// Restored by Load, the count is not saved.
+		s.count = s.Zero
+		for _, n := range s.Buckets {
+			s.count += n
+		}
+	}
+	return s.count
+}
+
+// Quantile return the estimated q quantile, q within [0, 1], using the
+// nearest rank method, or false if no value was added.
+func (s *quantileSketch) Quantile(q float64) (float64, bool) {
+	count := s.Count()
+	if count == 0 {
+		return 0, false
+	}
+	rank := max(int(math.Ceil(q*float64(count))), 1)
+	if rank <= s.Zero {
+		return 0, true
+	}
+	indexes := make([]int, 0, len(s.Buckets))
+	for i := range s.Buckets {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	seen := s.Zero
+	gamma := s.gamma()
+	for _, i := range indexes {
+		seen += s.Buckets[i]
+		if seen >= rank {
+			// The bucket holds the values within (gamma^(i-1), gamma^i],
+			// this estimate is at most Accuracy away from any of them.
+			return 2 * math.Pow(gamma, float64(i)) / (gamma + 1), true
+		}
+	}
+	return 2 * math.Pow(gamma, float64(indexes[len(indexes)-1])) / (gamma + 1), true
+}
+
+// Merge add the values counted in other, of the same accuracy, to the sketch.
+func (s *quantileSketch) Merge(other *quantileSketch) {
+	s.Count()
+	s.count += other.Count()
+	s.Zero += other.Zero
+	if s.Buckets == nil {
+		s.Buckets = make(map[int]int, len(other.Buckets))
+	}
+	for i, n := range other.Buckets {
+		s.Buckets[i] += n
+	}
+}
+
+func (s *quantileSketch) clone() *quantileSketch {
+	c := *s
+	c.Buckets = make(map[int]int, len(s.Buckets))
+	for i, n := range s.Buckets {
+		c.Buckets[i] = n
+	}
+	return &c
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+)
+
+// responseTimes return n log-normally distributed response times in ms,
+// of median 100 ms and ranging from a fraction of a ms to seconds.
+func responseTimes(n int, seed int64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.Round(math.Exp(math.Log(100)+1.5*rnd.NormFloat64())*1000) / 1000
+	}
+	return values
+}
+
+// exactQuantile return the q quantile of the sorted values using the
+// nearest rank method, as quantileSketch.
+func exactQuantile(sorted []float64, q float64) float64 {
+	return sorted[max(int(math.Ceil(q*float64(len(sorted))))-1, 0)]
+}
+
+// sketchQuantiles are the quantiles checked against the exact ones.
+var sketchQuantiles = []float64{0, 0.01, 0.25, 0.5, 0.75, 0.9, 0.95, 0.99, 0.999, 1}
+
+// maxRelativeError return the largest relative error of the quantiles
+// estimated by the sketch, of the values sorted.
+func maxRelativeError(s *quantileSketch, sorted []float64) float64 {
+	var worst float64
+	for _, q := range sketchQuantiles {
+		got, _ := s.Quantile(q)
+		want := exactQuantile(sorted, q)
+		worst = max(worst, math.Abs(got-want)/want)
+	}
+	return worst
+}
+
+// The estimated quantiles are within the accuracy of the exact ones,
+// the sketches of parts of the values merging into that of them all.
+func TestQuantileSketch(t *testing.T) {
+	values := responseTimes(100_000, 1)
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	for _, accuracy := range []float64{0.001, 0.01, 0.05} {
+		whole, first, second := newQuantileSketch(accuracy), newQuantileSketch(accuracy), newQuantileSketch(accuracy)
+		for i, v := range values {
+			whole.Add(v, 1)
+			if i%3 == 0 {
+				first.Add(v, 1)
+			} else {
+				second.Add(v, 1)
+			}
+		}
+		if got := maxRelativeError(whole, sorted); got > accuracy {
+			t.Errorf("accuracy %g: got a relative error of %g", accuracy, got)
+		}
+		first.Merge(second)
+		for _, q := range sketchQuantiles {
+			got, _ := first.Quantile(q)
+			if want, _ := whole.Quantile(q); got != want {
+				t.Errorf("accuracy %g: got the merged %g quantile %g, want %g", accuracy, q, got, want)
+			}
+		}
+	}
+
+	s := newQuantileSketch(0.01)
+	if _, ok := s.Quantile(0.5); ok {
+		t.Error("got a quantile without values")
+	}
+	s.Add(0, 3)
+	s.Add(50, 1)
+	if got, _ := s.Quantile(0.75); got != 0 {
+		t.Errorf("got the 0.75 quantile %g of 3 zeros and 50, want 0", got)
+	}
+	if got, _ := s.Quantile(1); math.Abs(got-50) > 0.5 {
+		t.Errorf("got the maximum %g, want 50 within 1%%", got)
+	}
+}
+
+// liveHeap return the heap bytes still in use after f, held by the
+// value it returns.
+func liveHeap(f func() any) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	v := f()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(v)
+	return after.HeapAlloc - min(before.HeapAlloc, after.HeapAlloc)
+}
+
+// BenchmarkQuantileAccuracy compare the memory held by the report
+// keeping every response time with that of the quantile sketch, flat
+// whatever the number of response times, reported as live-B/op along
+// with the largest relative error of the estimated quantiles. The
+// messages are normalized so their frequencies take no memory either.
+func BenchmarkQuantileAccuracy(b *testing.B) {
+	const accuracy = 0.01
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, n := range []int{10_000, 100_000, 1_000_000} {
+		values := responseTimes(n, 1)
+		entries := make([]LogEntry, n)
+		for i, v := range values {
+			entries[i] = LogEntry{time: start, level: LevelInfo, rawLevel: "INFO", message: fmt.Sprintf("Request processed in %g ms", v)}
+		}
+		sort.Float64s(values)
+		for _, bench := range []struct {
+			name string
+			opts []Option
+		}{
+			{"exact", []Option{WithNormalization(true)}},
+			{"sketch", []Option{WithNormalization(true), WithQuantileAccuracy(accuracy)}},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", bench.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				var live uint64
+				var report *AnalysisReport
+				for i := 0; i < b.N; i++ {
+					report = nil // not to count the previous one
+					live += liveHeap(func() any {
+						var err error
+						if report, err = Analyze(entries, bench.opts...); err != nil {
+							b.Fatal(err)
+						}
+						return report
+					})
+				}
+				b.ReportMetric(float64(live)/float64(b.N), "live-B/op")
+				if report.sketch != nil {
+					worst := maxRelativeError(report.sketch, values)
+					if worst > accuracy {
+						b.Errorf("got a relative error of %g, want at most %g", worst, accuracy)
+					}
+					b.ReportMetric(worst, "max-rel-err")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortField is the order SortEntries sort the entries in.
+type SortField int
+
+const (
+	// SortByTime sort the entries by time, then severity and message.
+	SortByTime SortField = iota
+	// SortBySeverity sort the most severe entries first, as ranked by
+	// LevelSeverity, then by time.
+	SortBySeverity
+)
+
+// SortEntries sort the entries in place by the field, keeping the order of
+// equal entries. Sorted by time, the entries of rotated files or of several
+// hosts are in the chronological order the analyses of gaps and trends expect.
+func SortEntries(entries []LogEntry, by SortField) {
+	if by == SortBySeverity {
+		slices.SortStableFunc(entries, func(a, b LogEntry) int {
+			if c := cmp.Compare(LevelSeverity(b.levelToken()), LevelSeverity(a.levelToken())); c != 0 {
+				return c
+			}
+			return a.time.Compare(b.time)
+		})
+		return
+	}
+	slices.SortStableFunc(entries, func(a, b LogEntry) int {
+		if c := a.time.Compare(b.time); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.level, b.level); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.rawLevel, b.rawLevel); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.message, b.message)
+	})
+}
+
+// entryKey identify the entries which are exact duplicates.
+type entryKey struct {
+	time    int64 // unix nanoseconds, the same instant on any location
+	level   string
+	message string
+}
+
+// DedupEntries return the entries without the exact duplicates, the entries
+// with the same time, level and message as an earlier one, which occur when
+// logs are shipped twice. The first occurrence of each entry is kept in
+// place, entries is reused for the result.
+func DedupEntries(entries []LogEntry) []LogEntry {
+	seen := make(map[entryKey]struct{}, len(entries))
+	unique := entries[:0]
+	for _, entry := range entries {
+		key := entryKey{entry.time.UnixNano(), entry.levelToken(), entry.message}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, entry)
+	}
+	clear(entries[len(unique):])
+	return unique
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// benchmarkEntries is the number of entries sorted and deduplicated by
+// the benchmarks, the size of a busy day of logs.
+const benchmarkEntries = 2_000_000
+
+// shuffledEntries return count generated entries in random order,
+// every tenth one being shipped twice.
+func shuffledEntries(count int) []LogEntry {
+	entries := generateEntries(count, 0)
+	for i := 0; i < count; i += 10 {
+		entries = append(entries, entries[i])
+	}
+	rand.New(rand.NewSource(0)).Shuffle(len(entries), func(i, j int) {
+		entries[i], entries[j] = entries[j], entries[i]
+	})
+	return entries
+}
+
+func TestSortDedupEntries(t *testing.T) {
+	entries := shuffledEntries(1000)
+	SortEntries(entries, SortByTime)
+	for i := 1; i < len(entries); i++ {
+		if entries[i].time.Before(entries[i-1].time) {
+			t.Fatalf("entry %d at %s before the previous one at %s", i, entries[i].time, entries[i-1].time)
+		}
+	}
+	// The generated entries are in chronological order, but
+	// not by level and message among those at the same time.
+	want := generateEntries(1000, 0)
+	SortEntries(want, SortByTime)
+	if got := DedupEntries(entries); !EqualSlice(got, want) {
+		t.Errorf("got %d entries once sorted and deduplicated, want the %d generated ones", len(got), len(want))
+	}
+}
+
+// Entries at the same time are ordered by level then message,
+// keeping the entries sharing those in their original order.
+func TestSortEntriesTies(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:01 INFO b",
+		"2025-01-01 10:00:00 WARN a",
+		"2025-01-01 10:00:00 INFO b",
+		"2025-01-01 10:00:00 INFO a",
+		"2025-01-01 10:00:00 INFO a",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	entries[3].source = "first"
+	SortEntries(entries, SortByTime)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.levelToken()+" "+e.message)
+	}
+	if want := "INFO a,INFO a,INFO b,WARN a,INFO b"; strings.Join(got, ",") != want {
+		t.Errorf("got %s, want %s", strings.Join(got, ","), want)
+	}
+	if entries[0].source != "first" {
+		t.Errorf("got the equal entries swapped")
+	}
+	if got := DedupEntries(entries); len(got) != 4 || got[0].source != "first" {
+		t.Errorf("got %d entries, first from %q, want 4 keeping the first", len(got), got[0].source)
+	}
+}
+
+func TestDedupFlag(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:01 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:00 INFO Started",
+		"2025-01-01 10:00:01 INFO Request processed in 10 ms",
+		"2025-01-01 10:00:02 ERROR Connection lost")
+	stdout, stderr, status := runMain(t, "-sort", "-dedup", "-level", "info,error", path)
+	if status != 0 || !strings.Contains(stderr, "removed 1 duplicate entries") || !strings.Contains(stdout, "Total Log Entries: 3") {
+		t.Errorf("got %q, %q, exit %d, want 3 entries once a duplicate is removed", stdout, stderr, status)
+	}
+}
+
+func BenchmarkSortEntries(b *testing.B) {
+	entries := shuffledEntries(benchmarkEntries)
+	buf := make([]LogEntry, len(entries))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(buf, entries)
+		SortEntries(buf, SortByTime)
+	}
+}
+
+func BenchmarkDedupEntries(b *testing.B) {
+	entries := shuffledEntries(benchmarkEntries)
+	buf := make([]LogEntry, len(entries))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(buf, entries)
+		DedupEntries(buf)
+	}
+}
+
+// Sorted by severity, the fatal entries come first and the entries
+// of the same severity stay in chronological order.
+func TestSortBySeverity(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:03 INFO started",
+		"2025-01-01 10:00:02 ERROR lost",
+		"2025-01-01 10:00:00 DEBUG polling",
+		"2025-01-01 10:00:04 FATAL crashed",
+		"2025-01-01 10:00:01 WARN slow",
+		"2025-01-01 10:00:00 ERR refused",
+		"2025-01-01 10:00:05 AUDIT login",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	SortEntries(entries, SortBySeverity)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.levelToken()+" "+e.message)
+	}
+	if want := "FATAL crashed,ERR refused,ERROR lost,WARN slow,INFO started,DEBUG polling,AUDIT login"; strings.Join(got, ",") != want {
+		t.Errorf("got %s, want %s", strings.Join(got, ","), want)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// noSource is the section of the entries without a source with -by-source.
+const noSource = "(none)"
+
+// SourceKey return the key identifying the source of the entries for
+// the -by-source spec: "source" for the entry source, e.g. the app of the
+// rfc5424 lines or the -source-prefix, a position N for the Nth word of
+// the message, e.g. 1 for a host name leading it, or else the name of a
+// structured data field, e.g. of the -delimiter lines.
+func SourceKey(spec string) (FrequencyKey, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("invalid source: must not be empty")
+	}
+	if spec == "source" {
+		return func(entry LogEntry) string { return entry.source }, nil
+	}
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n < 1 {
+			return nil, fmt.Errorf("invalid source position %d: must be at least 1", n)
+		}
+		return func(entry LogEntry) string {
+			if words := strings.Fields(entry.message); n <= len(words) {
+				return words[n-1]
+			}
+			return ""
+		}, nil
+	}
+	return FieldKey(spec), nil
+}
+
+// SourceReport is the report of the entries of a single source.
+type SourceReport struct {
+	Source string
+	Report *AnalysisReport
+}
+
+// AnalyzeBySource analyze the entries of each source apart, the source
+// of an entry being its key, and return the reports ordered by source.
+// The entries without a source are reported under "(none)".
+func AnalyzeBySource(entries []LogEntry, key FrequencyKey, opts ...Option) ([]SourceReport, error) {
+	groups := make(map[string][]LogEntry)
+	for _, entry := range entries {
+		source := key(entry)
+		if source == "" {
+			source = noSource
+		}
+		groups[source] = append(groups[source], entry)
+	}
+	reports := make([]SourceReport, 0, len(groups))
+	for source, group := range groups {
+		report, err := Analyze(group, opts...)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, SourceReport{Source: source, Report: report})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Source < reports[j].Source
+	})
+	return reports, nil
+}
+
+// PrintSources write the report of each source in a section headed by
+// the source, each report being written by write.
+func PrintSources(w io.Writer, reports []SourceReport, write func(io.Writer, *AnalysisReport) error) error {
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "== %s ==\n", r.Source)
+		if err := write(w, r.Report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSourcesJSON write the reports to w as a json object of the report
+// of each source by source, indented with two spaces if pretty is set.
+func WriteSourcesJSON(w io.Writer, reports []SourceReport, pretty bool) error {
+	bySource := make(map[string]*AnalysisReport, len(reports))
+	for _, r := range reports {
+		bySource[r.Source] = r.Report
+	}
+	enc := json.NewEncoder(w)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(bySource)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeBySource(t *testing.T) {
+	var entries []LogEntry
+	for _, line := range []string{
+		"2025-01-01 10:00:00 INFO web-1 Started",
+		"2025-01-01 10:00:01 INFO db-1 Started",
+		"2025-01-01 10:00:02 ERROR web-1 Connection lost",
+		"2025-01-01 10:00:03 INFO web-1 Request processed in 10 ms",
+		"2025-01-01 10:00:04 WARN db-1 Slow query",
+		"2025-01-01 10:00:05 INFO ",
+	} {
+		entry, err := NewLogEntry(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	key, err := SourceKey("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err := AnalyzeBySource(entries, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		source                    string
+		total, info, warn, errors int
+	}{
+		{noSource, 1, 1, 0, 0},
+		{"db-1", 2, 1, 1, 0},
+		{"web-1", 3, 2, 0, 1},
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("got %d reports, want %d", len(reports), len(want))
+	}
+	for i, w := range want {
+		r := reports[i]
+		if r.Source != w.source || r.Report.TotalEntries != w.total || r.Report.Info != w.info || r.Report.Warn != w.warn || r.Report.Error != w.errors {
+			t.Errorf("got the report %d of %s with %d entries, %d info, %d warn, %d error, want %+v",
+				i, r.Source, r.Report.TotalEntries, r.Report.Info, r.Report.Warn, r.Report.Error, w)
+		}
+	}
+	if _, ok := reports[2].Report.AverageResponseTime(); !ok {
+		t.Error("got no response time for web-1")
+	}
+
+	for _, spec := range []string{"", "0", "-1"} {
+		if _, err := SourceKey(spec); err == nil {
+			t.Errorf("SourceKey(%q): got no error", spec)
+		}
+	}
+}
+
+func TestBySourceFlag(t *testing.T) {
+	path := writeLines(t, "app.log",
+		"2025-01-01 10:00:00 INFO web-1 Started",
+		"2025-01-01 10:00:01 ERROR db-1 Disk full",
+		"2025-01-01 10:00:02 ERROR web-1 Connection lost")
+	stdout, stderr, status := runMain(t, "-by-source", "1", "-level", "info,error", path)
+	if status != 0 || !strings.HasPrefix(stdout, "== db-1 ==\nTotal Log Entries: 1\n") || !strings.Contains(stdout, "\n\n== web-1 ==\nTotal Log Entries: 2\n") {
+		t.Errorf("got %q, %q, exit %d, want a section per source", stdout, stderr, status)
+	}
+
+	stdout, stderr, status = runMain(t, "-by-source", "1", "-level", "info,error", "-format", "json", path)
+	var bySource map[string]AnalysisReport
+	if err := json.Unmarshal([]byte(stdout), &bySource); err != nil || status != 0 {
+		t.Fatalf("got %q, %q, exit %d: %v", stdout, stderr, status, err)
+	}
+	if bySource["db-1"].Error != 1 || bySource["web-1"].TotalEntries != 2 {
+		t.Errorf("got %+v, want the reports of db-1 and web-1", bySource)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// State is the position in a log file up to which it was analyzed.
+type State struct {
+	Inode  uint64 `json:"inode"`
+	Size   int64  `json:"size"`
+	Offset int64  `json:"offset"`
+}
+
+// LoadState read the state saved at path. A missing
+// state file is not an error, the zero state is returned.
+func LoadState(path string) (State, error) {
+	var s State
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save write the state to path, replacing
+// the previous one atomically.
+func (s State) Save(path string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Checkpoint resume the analysis of a file from the state saved by the
+// previous run, so only the lines appended since then are analyzed.
+// The state file is locked for the lifetime of the checkpoint.
+type Checkpoint struct {
+	path  string
+	lock  string
+	start int64 // offset of the first line to analyze
+	next  State
+	done  bool // the lock is released
+}
+
+// OpenCheckpoint lock the state at path and position f after the lines
+// analyzed by the previous run. The returned reader yields the complete
+// lines appended since, a partially written last line is left for the next
+// run. The file is analyzed from the start when it was rotated or truncated.
+func OpenCheckpoint(path string, f *os.File) (*Checkpoint, io.Reader, error) {
+	lock := path + ".lock"
+	lf, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, nil, fmt.Errorf("state %s is locked by another run, remove %s if it is stale", path, lock)
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock state: %w", err)
+	}
+	fmt.Fprintln(lf, os.Getpid())
+	lf.Close()
+
+	c := &Checkpoint{path: path, lock: lock}
+	r, err := c.open(f)
+	if err != nil {
+		c.Release()
+		return nil, nil, err
+	}
+	return c, r, nil
+}
+
+func (c *Checkpoint) open(f *os.File) (io.Reader, error) {
+	prev, err := LoadState(c.path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := prev.Offset
+	inode := fileInode(fi)
+	if inode != prev.Inode || fi.Size() < prev.Size || fi.Size() < offset {
+		offset = 0
+	}
+	end, err := lastLineEnd(f, offset, fi.Size())
+	if err != nil {
+		return nil, err
+	}
+	c.start = offset
+	c.next = State{Inode: inode, Size: fi.Size(), Offset: end}
+	return io.NewSectionReader(f, offset, end-offset), nil
+}
+
+// Start return the offset in the file of the first line
+// to analyze, zero without checkpoint.
+func (c *Checkpoint) Start() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.start
+}
+
+// Commit save the position reached by this run and release the lock.
+// It is a no-op on a nil checkpoint.
+func (c *Checkpoint) Commit() error {
+	if c == nil {
+		return nil
+	}
+	defer c.Release()
+	return c.next.Save(c.path)
+}
+
+// Release the lock without saving the position, the next run
+// will analyze the same lines again. It is a no-op on a nil checkpoint
+// and once released, so the lock of a later run is left in place.
+func (c *Checkpoint) Release() {
+	if c == nil || c.done {
+		return
+	}
+	c.done = true
+	os.Remove(c.lock)
+}
+
+// lastLineEnd return the offset just after the last newline of f
+// between from and to, or from if there is none.
+func lastLineEnd(f io.ReaderAt, from, to int64) (int64, error) {
+	const blockSize = 64 << 10
+	buf := make([]byte, blockSize)
+	for end := to; end > from; {
+		start := max(end-blockSize, from)
+		b := buf[:end-start]
+		if _, err := f.ReadAt(b, start); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return from, nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readCheckpoint open the checkpoint of the state at statePath over the
+// file at path and return the lines to analyze, committing the checkpoint.
+func readCheckpoint(t *testing.T, statePath, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c, r, err := OpenCheckpoint(statePath, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		c.Release()
+		t.Fatal(err)
+	}
+	if err := c.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	dir := t.TempDir()
+	path, statePath := filepath.Join(dir, "app.log"), filepath.Join(dir, "state.json")
+	appendFile(t, path, "line 1\nline 2\n")
+	if got := readCheckpoint(t, statePath, path); got != "line 1\nline 2\n" {
+		t.Errorf("first run: got %q, want the whole file", got)
+	}
+
+	// A partially written line is left for the next run.
+	appendFile(t, path, "line 3\nline")
+	if got := readCheckpoint(t, statePath, path); got != "line 3\n" {
+		t.Errorf("second run: got %q, want the appended complete line", got)
+	}
+	appendFile(t, path, " 4\n")
+	if got := readCheckpoint(t, statePath, path); got != "line 4\n" {
+		t.Errorf("third run: got %q, want the completed line", got)
+	}
+	if got := readCheckpoint(t, statePath, path); got != "" {
+		t.Errorf("fourth run: got %q, want nothing new", got)
+	}
+}
+
+func TestCheckpointTruncated(t *testing.T) {
+	dir := t.TempDir()
+	path, statePath := filepath.Join(dir, "app.log"), filepath.Join(dir, "state.json")
+	appendFile(t, path, "line 1\nline 2\nline 3\n")
+	readCheckpoint(t, statePath, path)
+
+	if err := os.WriteFile(path, []byte("new 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readCheckpoint(t, statePath, path); got != "new 1\n" {
+		t.Errorf("got %q after the truncation, want the file from offset 0", got)
+	}
+}
+
+func TestCheckpointRotated(t *testing.T) {
+	dir := t.TempDir()
+	path, statePath := filepath.Join(dir, "app.log"), filepath.Join(dir, "state.json")
+	appendFile(t, path, "line 1\n")
+	readCheckpoint(t, statePath, path)
+	if s, err := LoadState(statePath); err != nil || s.Inode == 0 {
+		t.Skipf("no inode: %+v, %v", s, err)
+	}
+
+	// The new file is larger than the offset reached in the rotated one.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "new 1\nnew 2\n")
+	if got := readCheckpoint(t, statePath, path); got != "new 1\nnew 2\n" {
+		t.Errorf("got %q after the rotation, want the new file from offset 0", got)
+	}
+}
+
+func TestCheckpointLocked(t *testing.T) {
+	dir := t.TempDir()
+	path, statePath := filepath.Join(dir, "app.log"), filepath.Join(dir, "state.json")
+	appendFile(t, path, "line 1\n")
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c, _, err := OpenCheckpoint(statePath, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := OpenCheckpoint(statePath, f); err == nil {
+		t.Error("opened the checkpoint locked by another run")
+	}
+	c.Release()
+	if got := readCheckpoint(t, statePath, path); got != "line 1\n" {
+		t.Errorf("got %q once released without saving, want the same lines again", got)
+	}
+
+	// Releasing again leaves the lock of the next run.
+	next, _, err := OpenCheckpoint(statePath, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer next.Release()
+	c.Release()
+	if _, _, err := OpenCheckpoint(statePath, f); err == nil {
+		t.Error("opened the checkpoint locked by the next run")
+	}
+}
+
+// The lock is released when the run fails after locking the state.
+func TestStateReleasedOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path, statePath := filepath.Join(dir, "app.log"), filepath.Join(dir, "state.json")
+	appendFile(t, path, "2025-01-01 10:00:00 INFO Request processed in 10 ms\n")
+	for _, args := range [][]string{
+		{"-dump", filepath.Join(dir, "missing", "entries.json")},
+		{"-rotated"},
+	} {
+		args = append(append([]string{"-state", statePath}, args...), path)
+		_, stderr, status := runMain(t, args...)
+		if status == 0 {
+			t.Errorf("%q: got exit 0, want the run failing", args)
+		}
+		if _, err := os.Stat(statePath + ".lock"); !os.IsNotExist(err) {
+			t.Errorf("%q: got the state left locked, %v\nstderr: %s", args, err, stderr)
+		}
+	}
+}
+
+// With -max-entries the lines after the first entries would be skipped
+// by the next runs, so both are not used together.
+func TestStateMaxEntries(t *testing.T) {
+	dir := t.TempDir()
+	path, statePath := filepath.Join(dir, "app.log"), filepath.Join(dir, "state.json")
+	appendFile(t, path, "2025-01-01 10:00:00 INFO Started\n2025-01-01 10:00:01 INFO Started\n")
+	_, stderr, status := runMain(t, "-state", statePath, "-max-entries", "1", path)
+	if status != 1 || !strings.Contains(stderr, "-state can not be used with -f, -watch or -max-entries") {
+		t.Errorf("got %q, exit %d, want -max-entries rejected", stderr, status)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("got the state saved, %v", err)
+	}
+}
+
+func TestLoadState(t *testing.T) {
+	dir := t.TempDir()
+	if s, err := LoadState(filepath.Join(dir, "missing.json")); err != nil || s != (State{}) {
+		t.Errorf("got %+v, %v for a missing state, want the zero state", s, err)
+	}
+	path := filepath.Join(dir, "state.json")
+	want := State{Inode: 7, Size: 120, Offset: 100}
+	if err := want.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := LoadState(path); err != nil || s != want {
+		t.Errorf("got %+v, %v, want %+v", s, err, want)
+	}
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadState(path); err == nil {
+		t.Error("loaded a corrupt state")
+	}
+}
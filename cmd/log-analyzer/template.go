@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+// FormatTemplate render the report with the Go template of -template-file.
+const FormatTemplate = "template"
+
+// templateFuncs are the functions available to the report templates
+// besides the builtin ones, e.g. {{datetime .FirstEntry}} or
+// {{pct .ErrorRate}}.
+var templateFuncs = template.FuncMap{
+	"datetime": func(t time.Time) string { return t.Format(time.DateTime) },
+	"pct":      func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) },
+}
+
+// ParseTemplate parse the text/template at path, with the report
+// template functions.
+func ParseTemplate(path string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate write the report to w by executing the text/template at
+// tmplPath with the report as dot, e.g. {{.TotalEntries}} or
+// {{range .TopMessages 5}}{{.Count}} {{.Message}}{{end}}.
+func RenderTemplate(w io.Writer, tmplPath string, report *AnalysisReport) error {
+	tmpl, err := ParseTemplate(tmplPath)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, report)
+}
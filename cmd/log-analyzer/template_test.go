@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTemplate(t *testing.T, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRenderTemplate(t *testing.T) {
+	report, err := Analyze(generateEntries(1234, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := RenderTemplate(&b, writeTemplate(t, "{{.TotalEntries}}"), report); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "1234" {
+		t.Errorf("got %q, want %q", b.String(), "1234")
+	}
+
+	if err := RenderTemplate(&b, writeTemplate(t, "{{.TotalEntries"), report); err == nil {
+		t.Error("got no error rendering an invalid template")
+	}
+}
+
+// The sample template render the levels, the error rate and the top messages.
+func TestDefaultTemplate(t *testing.T) {
+	report, err := Analyze([]LogEntry{
+		{time: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), level: LevelError, rawLevel: "ERROR", message: "Connection lost"},
+		{time: time.Date(2025, 1, 1, 10, 0, 1, 0, time.UTC), level: LevelInfo, rawLevel: "INFO", message: "Started"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := RenderTemplate(&b, filepath.Join("templates", "default.txt"), report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Log analysis of 2 entries from 2025-01-01 10:00:00 to 2025-01-01 10:00:01\n",
+		"ERROR: 1 (50.0%)\n",
+		"       1  Connection lost\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("got %q, want it to contain %q", b.String(), want)
+		}
+	}
+}
+
+func TestFormatTemplate(t *testing.T) {
+	path := writeLines(t, "app.log", "2025-01-01 10:00:00 INFO Started", "2025-01-01 10:00:01 ERROR Connection lost")
+	tmpl := writeTemplate(t, "{{.TotalEntries}} entries, {{.Error}} errors\n")
+	stdout, stderr, status := runMain(t, "-format", "template", "-template-file", tmpl, "-level", "info,error", path)
+	if status != 0 || stdout != "2 entries, 1 errors\n" {
+		t.Errorf("got %q, %q, exit %d", stdout, stderr, status)
+	}
+
+	if _, stderr, status := runMain(t, "-format", "template", path); status == 0 || !strings.Contains(stderr, "requires -template-file") {
+		t.Errorf("got %q, exit %d, want -template-file required", stderr, status)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io"
+	"time"
+)
+
+// NewRateReader return a reader reading from r at no more than rate bytes
+// per second on average, with bursts of at most a second worth of bytes
+// after having been idle. A rate of zero or less does not limit r, which
+// is returned as is.
+func NewRateReader(r io.Reader, rate int64) io.Reader {
+	if rate <= 0 {
+		return r
+	}
+	return &rateReader{r: r, rate: rate}
+}
+
+// rateReader is a token bucket, each byte read taking a token
+// and tokens being added at rate per second up to rate.
+type rateReader struct {
+	r      io.Reader
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+func (r *rateReader) Read(b []byte) (int, error) {
+	now := time.Now()
+	if !r.last.IsZero() {
+		r.tokens = min(r.tokens+now.Sub(r.last).Seconds()*float64(r.rate), float64(r.rate))
+	}
+	r.last = now
+	if int64(len(b)) > r.rate {
+		b = b[:r.rate]
+	}
+	n, err := r.r.Read(b)
+	r.tokens -= float64(n)
+	if r.tokens < 0 {
+		// The tokens added while sleeping are counted by the next read.
+		time.Sleep(time.Duration(-r.tokens / float64(r.rate) * float64(time.Second)))
+	}
+	return n, err
+}
+
+// throttle limit the reading of r to the -read-rate, if any.
+func throttle(r io.Reader) io.Reader {
+	return NewRateReader(r, *readRate)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRateReader(t *testing.T) {
+	const size, rate = 3000, 10000
+	data := bytes.Repeat([]byte("x"), size)
+	start := time.Now()
+	b, err := io.ReadAll(NewRateReader(bytes.NewReader(data), rate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, data) {
+		t.Fatalf("read %d bytes, want the %d bytes of the input", len(b), size)
+	}
+	if elapsed, want := time.Since(start), time.Duration(size)*time.Second/rate; elapsed < want {
+		t.Errorf("read %d bytes in %v at %d bytes per second, want at least %v", size, elapsed, rate, want)
+	}
+
+	// Without a positive rate the reader is not limited, rather than
+	// never reading with a zero rate or panicking with a negative one.
+	for _, rate := range []int64{0, -1} {
+		r := bytes.NewReader(data)
+		if got := NewRateReader(r, rate); got != io.Reader(r) {
+			t.Errorf("got %T with a rate of %d, want the reader as is", got, rate)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrorTimeline is the time distribution of an error message.
+type ErrorTimeline struct {
+	Message string
+	Total   int
+	// Buckets hold the number of occurrences per bucket, the first
+	// bucket starting at the Start of the timelines it belongs to.
+	Buckets []int
+}
+
+// ErrorTimelines is the time distribution of the most frequent error
+// messages, bucketed on a common time axis so they can be compared.
+type ErrorTimelines struct {
+	Start     time.Time
+	Bucket    time.Duration
+	Timelines []ErrorTimeline
+}
+
+// TopErrorsByTime find the n most frequent error messages among entries and
+// count their occurrences per bucket. Messages with the same frequency are
+// ordered alphabetically.
+func TopErrorsByTime(entries []LogEntry, n int, bucket time.Duration) *ErrorTimelines {
+	result := &ErrorTimelines{Bucket: bucket}
+	if n <= 0 || bucket <= 0 {
+		return result
+	}
+
+	var first, last time.Time
+	times := make(map[string][]time.Time)
+	for _, entry := range entries {
+		if entry.level != LevelError {
+			continue
+		}
+		if first.IsZero() || entry.time.Before(first) {
+			first = entry.time
+		}
+		if entry.time.After(last) {
+			last = entry.time
+		}
+		times[entry.message] = append(times[entry.message], entry.time)
+	}
+	if len(times) == 0 {
+		return result
+	}
+
+	messages := make([]string, 0, len(times))
+	for msg := range times {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		ci, cj := len(times[messages[i]]), len(times[messages[j]])
+		if ci != cj {
+			return ci > cj
+		}
+		return messages[i] < messages[j]
+	})
+	if len(messages) > n {
+		messages = messages[:n]
+	}
+
+	result.Start = first.Truncate(bucket)
+	buckets := int(last.Sub(result.Start)/bucket) + 1
+	for _, msg := range messages {
+		tl := ErrorTimeline{
+			Message: msg,
+			Total:   len(times[msg]),
+			Buckets: make([]int, buckets),
+		}
+		for _, t := range times[msg] {
+			tl.Buckets[int(t.Sub(result.Start)/bucket)]++
+		}
+		result.Timelines = append(result.Timelines, tl)
+	}
+	return result
+}
+
+// Print write one row per error message with its total count
+// followed by the count in each bucket.
+func (e *ErrorTimelines) Print(w io.Writer) error {
+	if len(e.Timelines) == 0 {
+		_, err := fmt.Fprintln(w, "No error entries found")
+		return err
+	}
+	fmt.Fprintf(w, "Top errors by time (from %s, %s buckets):\n", e.Start.Format(time.DateTime), e.Bucket)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOTAL\tDISTRIBUTION\tMESSAGE")
+	for _, tl := range e.Timelines {
+		counts := make([]string, len(tl.Buckets))
+		for i, c := range tl.Buckets {
+			counts[i] = strconv.Itoa(c)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", tl.Total, strings.Join(counts, " "), tl.Message)
+	}
+	return tw.Flush()
+}
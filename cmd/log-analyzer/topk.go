@@ -0,0 +1,162 @@
+package main
+
+import (
+	"container/heap"
+	"encoding/json"
+)
+
+// spaceSaving count the most frequent keys of a stream in bounded memory
+// with the Space-Saving algorithm: at most capacity keys are tracked and
+// a new key replaces the least frequent one, inheriting its count as the
+// overestimation error. Any key occurring more than total/capacity times
+// is guaranteed to be tracked.
+type spaceSaving struct {
+	capacity int
+	evicted  bool
+	items    map[string]*ssItem
+	heap     ssHeap
+}
+
+type ssItem struct {
+	key   string
+	count int
+	err   int // overestimation of count
+	index int // position in the heap
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{
+		capacity: capacity,
+		items:    make(map[string]*ssItem, capacity),
+		heap:     make(ssHeap, 0, capacity),
+	}
+}
+
+// Add count n more occurrences of key, up to err of which may be
+// overestimated.
+func (s *spaceSaving) Add(key string, n, err int) {
+	if it, ok := s.items[key]; ok {
+		it.count += n
+		it.err += err
+		heap.Fix(&s.heap, it.index)
+		return
+	}
+	if len(s.heap) < s.capacity {
+		it := &ssItem{key: key, count: n, err: err}
+		s.items[key] = it
+		heap.Push(&s.heap, it)
+		return
+	}
+	// Replace the least frequent key.
+	s.evicted = true
+	it := s.heap[0]
+	delete(s.items, it.key)
+	it.key, it.err = key, it.count+err
+	it.count += n
+	s.items[key] = it
+	heap.Fix(&s.heap, 0)
+}
+
+// Counts return the guaranteed count, i.e. excluding the possible
+// overestimation, of each tracked key and their total. Keys whose
+// count may entirely be overestimated are left out.
+func (s *spaceSaving) Counts() (map[string]int, int) {
+	counts := make(map[string]int, len(s.items))
+	var total int
+	for key, it := range s.items {
+		if n := it.count - it.err; n > 0 {
+			counts[key] = n
+			total += n
+		}
+	}
+	return counts, total
+}
+
+func (s *spaceSaving) clone() *spaceSaving {
+	c := newSpaceSaving(s.capacity)
+	c.evicted = s.evicted
+	for _, it := range s.heap {
+		ci := *it
+		c.items[ci.key] = &ci
+		c.heap = append(c.heap, &ci)
+	}
+	return c
+}
+
+// savedSpaceSaving is the json form of a spaceSaving, saved along with
+// the report so the counts go on after Load, see Save.
+type savedSpaceSaving struct {
+	Capacity int      `json:"capacity"`
+	Evicted  bool     `json:"evicted,omitempty"`
+	Items    []ssSave `json:"items"`
+}
+
+type ssSave struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+	Err   int    `json:"err,omitempty"`
+}
+
+func (s *spaceSaving) MarshalJSON() ([]byte, error) {
+	saved := savedSpaceSaving{Capacity: s.capacity, Evicted: s.evicted, Items: make([]ssSave, len(s.heap))}
+	for i, it := range s.heap {
+		saved.Items[i] = ssSave{Key: it.key, Count: it.count, Err: it.err}
+	}
+	return json.Marshal(saved)
+}
+
+func (s *spaceSaving) UnmarshalJSON(b []byte) error {
+	var saved savedSpaceSaving
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return err
+	}
+	*s = *newSpaceSaving(max(saved.Capacity, 0))
+	s.evicted = saved.Evicted
+	for _, it := range saved.Items {
+		item := &ssItem{key: it.Key, count: it.Count, err: it.Err}
+		s.items[it.Key] = item
+		heap.Push(&s.heap, item)
+	}
+	return nil
+}
+
+// ssHeap is a min-heap of items ordered by count.
+type ssHeap []*ssItem
+
+func (h ssHeap) Len() int           { return len(h) }
+func (h ssHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h ssHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *ssHeap) Push(x any) {
+	it := x.(*ssItem)
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *ssHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+// countHeap is a min-heap of message counts, the
+// message ranking last according to before at its root.
+type countHeap []MessageCount
+
+func (h countHeap) Len() int           { return len(h) }
+func (h countHeap) Less(i, j int) bool { return h[j].before(h[i]) }
+func (h countHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *countHeap) Push(x any) { *h = append(*h, x.(MessageCount)) }
+
+func (h *countHeap) Pop() any {
+	old := *h
+	m := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return m
+}
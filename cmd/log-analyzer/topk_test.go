@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+// highCardinalityEntries return n entries of messages unique to each
+// entry, as request ids make them, among which every heavy-th entry is
+// one of the heavy hitters "Connection lost 0" to "Connection lost 9".
+func highCardinalityEntries(n, heavy int) []LogEntry {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	entries := make([]LogEntry, n)
+	for i := range entries {
+		msg := fmt.Sprintf("Request %08x processed", i)
+		if i%heavy == 0 {
+			msg = fmt.Sprintf("Connection lost %d", i/heavy%10)
+		}
+		entries[i] = LogEntry{time: start.Add(time.Duration(i) * time.Millisecond), level: LevelInfo, rawLevel: "INFO", message: msg}
+	}
+	return entries
+}
+
+// The bounded counts are never above the exact ones and miss at most
+// total/capacity occurrences, the heavy hitters being all tracked.
+func TestMaxUniqueMessages(t *testing.T) {
+	const n, heavy, capacity = 50_000, 4, 200
+	entries := highCardinalityEntries(n, heavy)
+	exact, err := Analyze(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bounded, err := Analyze(entries, WithMaxUniqueMessages(capacity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exact.ApproximateMessages || len(exact.MsgFrequency) != n-n/heavy+10 {
+		t.Fatalf("got %d exact messages (approximate %v), want %d", len(exact.MsgFrequency), exact.ApproximateMessages, n-n/heavy+10)
+	}
+	if !bounded.ApproximateMessages || len(bounded.MsgFrequency) > capacity {
+		t.Fatalf("got %d bounded messages (approximate %v), want at most %d approximate", len(bounded.MsgFrequency), bounded.ApproximateMessages, capacity)
+	}
+
+	var tracked int
+	for msg, count := range bounded.MsgFrequency {
+		if want := exact.MsgFrequency[msg]; count > want || want-count > n/capacity {
+			t.Errorf("%q: got the count %d, want %d less at most %d", msg, count, want, n/capacity)
+		}
+		tracked += count
+	}
+	if bounded.OtherMessages != n-tracked {
+		t.Errorf("got %d other entries, want %d", bounded.OtherMessages, n-tracked)
+	}
+	for i := 0; i < 10; i++ {
+		if msg := fmt.Sprintf("Connection lost %d", i); bounded.MsgFrequency[msg] == 0 {
+			t.Errorf("%q occurring %d times is not tracked", msg, exact.MsgFrequency[msg])
+		}
+	}
+	for i, m := range bounded.TopMessages(10) {
+		if want := exact.TopMessages(10)[i].Message; m.Message != want {
+			t.Errorf("got the top message %d %q, want %q", i, m.Message, want)
+		}
+	}
+
+	// The counts are exact as long as the bound is not reached.
+	few, err := Analyze(entries[:capacity], WithMaxUniqueMessages(capacity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := Analyze(entries[:capacity])
+	if few.ApproximateMessages || few.OtherMessages != 0 || fmt.Sprint(few.MsgFrequency) != fmt.Sprint(want.MsgFrequency) {
+		t.Errorf("got the bounded counts %v, want the exact %v", few.MsgFrequency, want.MsgFrequency)
+	}
+}
+
+// BenchmarkMaxUniqueMessages compare the memory held by the report
+// counting the messages of a high-cardinality log in the exact map and
+// with -max-unique-messages, reported as live-B/op.
+func BenchmarkMaxUniqueMessages(b *testing.B) {
+	entries := highCardinalityEntries(500_000, 4)
+	for _, bench := range []struct {
+		name string
+		opts []Option
+	}{
+		{"exact", nil},
+		{"max=1000", []Option{WithMaxUniqueMessages(1000)}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var live uint64
+			for i := 0; i < b.N; i++ {
+				live += liveHeap(func() any {
+					report, err := Analyze(entries, bench.opts...)
+					if err != nil {
+						b.Fatal(err)
+					}
+					return report
+				})
+			}
+			b.ReportMetric(float64(live)/float64(b.N), "live-B/op")
+		})
+	}
+}
+
+// messageFrequencies return n distinct messages of frequencies
+// within [1, 100], so that many messages tie.
+func messageFrequencies(n int) map[string]int {
+	rnd := rand.New(rand.NewSource(1))
+	freq := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		freq[fmt.Sprintf("Request %08x processed", rnd.Int63())] = 1 + rnd.Intn(100)
+	}
+	return freq
+}
+
+// sortedMessages return all the messages of freq in the order of topMessages.
+func sortedMessages(freq map[string]int) []MessageCount {
+	all := make([]MessageCount, 0, len(freq))
+	for msg, count := range freq {
+		all = append(all, MessageCount{Message: msg, Count: count})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].before(all[j]) })
+	return all
+}
+
+// The messages selected by the heap are the first ones of the full
+// sort, the ties being broken alphabetically whatever the map order.
+func TestTopMessages(t *testing.T) {
+	freq := messageFrequencies(10_000)
+	all := sortedMessages(freq)
+	for _, n := range []int{1, 3, 10, 100, 9_999, 10_000, 20_000} {
+		got := topMessages(freq, n)
+		if want := all[:min(n, len(all))]; !slices.Equal(got, want) {
+			t.Errorf("n=%d: got %d messages, not the first %d sorted", n, len(got), len(want))
+		}
+	}
+	ties := map[string]int{"b": 2, "a": 2, "d": 1, "c": 2, "e": 3}
+	want := []MessageCount{{"e", 3}, {"a", 2}, {"b", 2}}
+	for i := 0; i < 20; i++ {
+		if got := topMessages(ties, 3); !slices.Equal(got, want) {
+			t.Fatalf("got the top messages %v, want %v", got, want)
+		}
+	}
+	if got := topMessages(freq, 0); got != nil {
+		t.Errorf("got the top messages %v for n=0", got)
+	}
+	if got := topMessages(nil, 3); len(got) != 0 {
+		t.Errorf("got the top messages %v without messages", got)
+	}
+}
+
+// BenchmarkTopMessages compare selecting the top messages among many
+// distinct ones with the bounded heap to sorting them all.
+func BenchmarkTopMessages(b *testing.B) {
+	freq := messageFrequencies(1_000_000)
+	for _, n := range []int{10, 1000} {
+		b.Run(fmt.Sprintf("heap/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				topMessages(freq, n)
+			}
+		})
+	}
+	b.Run("sort", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sortedMessages(freq)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// validThreshold is the minimum ratio of parseable lines
+// for a file to be considered valid.
+const validThreshold = 0.9
+
+// RunValidate read all lines of the file at path, try to parse each of them
+// and write the number and percentage of lines parsed successfully to w.
+func RunValidate(path string, w io.Writer) (parseErrors int, total int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		total++
+		if _, err := lineParser.parse(total, s.Text()); err != nil {
+			parseErrors++
+		}
+	}
+	if err := s.Err(); err != nil {
+		return parseErrors, total, err
+	}
+
+	var pct float64
+	if total > 0 {
+		pct = float64(total-parseErrors) / float64(total) * 100
+	}
+	fmt.Fprintf(w, "%s: %d of %d lines parsed (%.2f%%), %d invalid\n", path, total-parseErrors, total, pct, parseErrors)
+	return parseErrors, total, nil
+}
+
+// validate run the validate subcommand and exit with status 0
+// if enough lines were parsed, 1 otherwise.
+func validate(args []string) {
+	fs := newFlagSet("validate", parseFlags)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	setup()
+	parseErrors, total, err := RunValidate(fs.Arg(0), os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "log-analyzer:", err)
+		os.Exit(1)
+	}
+	if total == 0 || float64(total-parseErrors)/float64(total) < validThreshold {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// A file of which half the lines are valid is reported as such and fails
+// the validation, a file of valid lines passes it.
+func TestRunValidate(t *testing.T) {
+	path := writeLines(t, "half.log",
+		"2025-01-01 10:00:00 INFO Request processed in 10 ms",
+		"not a log line",
+		"2025-01-01 10:00:01 ERROR Connection lost",
+		"2025-01-01",
+		"2025-01-01 10:00:02 WARN Memory usage is high",
+		"2025-13-01 10:00:03 INFO Bad month",
+	)
+	var b strings.Builder
+	parseErrors, total, err := RunValidate(path, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parseErrors != 3 || total != 6 {
+		t.Errorf("got %d parse errors of %d lines, want 3 of 6", parseErrors, total)
+	}
+	if want := path + ": 3 of 6 lines parsed (50.00%), 3 invalid\n"; b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+	if stdout, _, status := runMain(t, "validate", path); status != 1 || !strings.Contains(stdout, "(50.00%)") {
+		t.Errorf("got %q, exit %d, want the 50%% valid file failing with exit 1", stdout, status)
+	}
+
+	valid := writeLines(t, "valid.log", "2025-01-01 10:00:00 INFO Request processed in 10 ms")
+	if stdout, _, status := runMain(t, "validate", valid); status != 0 || !strings.Contains(stdout, "(100.00%)") {
+		t.Errorf("got %q, exit %d, want the valid file passing with exit 0", stdout, status)
+	}
+	if _, _, err := RunValidate(path+".missing", &b); err == nil {
+		t.Error("got no error validating a missing file")
+	}
+}
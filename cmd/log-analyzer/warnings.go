@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Verbosity select how the invalid lines are reported.
+type Verbosity int
+
+const (
+	// VerbosityNormal report the first invalid lines, then
+	// periodically how many more were found.
+	VerbosityNormal Verbosity = iota
+	// VerbosityQuiet report only the number of invalid lines.
+	VerbosityQuiet
+	// VerbosityVerbose report every invalid line.
+	VerbosityVerbose
+)
+
+const (
+	// invalidLineLimit is the number of invalid lines
+	// reported individually with VerbosityNormal.
+	invalidLineLimit = 10
+	// invalidLineEvery is the interval at which the invalid
+	// lines found since are counted with VerbosityNormal.
+	invalidLineEvery = 5 * time.Second
+)
+
+// invalidLines report the invalid lines of the analysis on stderr.
+var invalidLines = NewInvalidLines(log.Default(), VerbosityNormal)
+
+// InvalidLines report the lines which could not be parsed without flooding
+// the output, as a file in the wrong format has as many invalid lines as
+// lines. It is safe for concurrent use.
+type InvalidLines struct {
+	mu        sync.Mutex
+	logger    *log.Logger
+	verbosity Verbosity
+	count     int       // invalid lines found
+	reported  int       // invalid lines reported individually or counted
+	last      time.Time // time of the last count
+}
+
+func NewInvalidLines(logger *log.Logger, v Verbosity) *InvalidLines {
+	return &InvalidLines{logger: logger, verbosity: v}
+}
+
+// SetVerbosity change how the invalid lines are reported from now on.
+func (l *InvalidLines) SetVerbosity(v Verbosity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verbosity = v
+}
+
+// Report an invalid line, err being its parse error.
+func (l *InvalidLines) Report(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.count++
+	switch {
+	case l.verbosity == VerbosityVerbose || (l.verbosity == VerbosityNormal && l.count <= invalidLineLimit):
+		l.logger.Printf("invalid log entry: %v", err)
+		l.reported = l.count
+	case l.verbosity == VerbosityNormal && l.count == invalidLineLimit+1:
+		l.last = time.Now()
+	case l.verbosity == VerbosityNormal && time.Since(l.last) >= invalidLineEvery:
+		l.logger.Printf("...and %d more invalid log entries", l.count-l.reported)
+		l.reported, l.last = l.count, time.Now()
+	}
+}
+
+// Count return the number of invalid lines reported.
+func (l *InvalidLines) Count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.count
+}
+
+// Flush report the invalid lines not reported yet, or
+// their total count with VerbosityQuiet.
+func (l *InvalidLines) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case l.verbosity == VerbosityQuiet && l.count > 0:
+		l.logger.Printf("%d invalid log entries", l.count)
+	case l.verbosity == VerbosityNormal && l.count > l.reported:
+		l.logger.Printf("...and %d more invalid log entries, %d in total", l.count-l.reported, l.count)
+	}
+	l.reported = l.count
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvalidLines(t *testing.T) {
+	invalid := func(from, to int) string {
+		var s string
+		for i := from; i <= to; i++ {
+			s += fmt.Sprintf("invalid log entry: line %d: malformed line\n", i)
+		}
+		return s
+	}
+	for _, tt := range []struct {
+		verbosity Verbosity
+		want      string
+	}{
+		{VerbosityNormal, invalid(1, 10) + "...and 2 more invalid log entries\n...and 3 more invalid log entries, 15 in total\n"},
+		{VerbosityQuiet, "15 invalid log entries\n"},
+		{VerbosityVerbose, invalid(1, 15)},
+	} {
+		var b strings.Builder
+		l := NewInvalidLines(log.New(&b, "", 0), tt.verbosity)
+		for i := 1; i <= 15; i++ {
+			l.Report(&ParseError{Line: i, Reason: Malformed})
+			if i == 11 {
+				// The invalid lines beyond the limit are counted periodically.
+				l.last = l.last.Add(-invalidLineEvery)
+			}
+		}
+		l.Flush()
+		if b.String() != tt.want || l.Count() != 15 {
+			t.Errorf("verbosity %d: got %d invalid lines reported as\n%s\nwant\n%s", tt.verbosity, l.Count(), b.String(), tt.want)
+		}
+
+		// Nothing more is reported once flushed, but the total count.
+		b.Reset()
+		l.Flush()
+		if tt.verbosity != VerbosityQuiet && b.Len() != 0 {
+			t.Errorf("verbosity %d: got %q flushed twice", tt.verbosity, b.String())
+		}
+	}
+}
+
+func TestInvalidLinesNone(t *testing.T) {
+	for _, v := range []Verbosity{VerbosityNormal, VerbosityQuiet, VerbosityVerbose} {
+		var b strings.Builder
+		NewInvalidLines(log.New(&b, "", 0), v).Flush()
+		if b.Len() != 0 {
+			t.Errorf("verbosity %d: got %q without invalid lines", v, b.String())
+		}
+	}
+}
+
+// The invalid lines are counted in the report.
+func TestSkippedLines(t *testing.T) {
+	report, err := AnalyzeReader(strings.NewReader("2025-01-01 10:00:00 INFO Started\nnot a log line\n2025-01-01 10:00:01 ERROR Connection lost\ntoo few\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SkippedLines != 2 {
+		t.Errorf("got %d skipped lines, want 2", report.SkippedLines)
+	}
+}
+
+// The invalid lines of testdata/interleaved.log are not analyzed as zero
+// entries, which would count an empty message and the zero time.
+func TestInvalidLinesNotAnalyzed(t *testing.T) {
+	b, err := os.ReadFile("testdata/interleaved.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadFile(bytes.NewReader(b))
+	if err != nil || len(entries) != 4 {
+		t.Fatalf("got %d entries, %v, want the 4 valid ones", len(entries), err)
+	}
+	for _, e := range entries {
+		if e.time.IsZero() || e.message == "" {
+			t.Errorf("got the zero entry %+v", e)
+		}
+	}
+
+	analyze := map[string]func() (*AnalysisReport, error){
+		"AnalyzeSource": func() (*AnalysisReport, error) {
+			return AnalyzeSource(NewReader(bytes.NewReader(b)), WithInvalidLineHandler(DiscardInvalidLine))
+		},
+		"AnalyzeReader": func() (*AnalysisReport, error) {
+			return AnalyzeReader(bytes.NewReader(b), WithInvalidLineHandler(DiscardInvalidLine), WithWorkers(4), WithEmptyMessages(true))
+		},
+	}
+	for name, analyze := range analyze {
+		report, err := analyze()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.TotalEntries != 4 || report.Info != 2 || report.Error != 2 || report.SkippedLines != 3 {
+			t.Errorf("%s: got %d entries (%d info, %d error) and %d skipped lines, want 4 (2, 2) and 3", name, report.TotalEntries, report.Info, report.Error, report.SkippedLines)
+		}
+		if _, ok := report.MsgFrequency[""]; ok || len(report.MsgFrequency) != 3 {
+			t.Errorf("%s: got the message frequencies %v, want the 3 messages", name, report.MsgFrequency)
+		}
+		if want := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC); !report.FirstEntry.Equal(want) {
+			t.Errorf("%s: got the first entry at %s, want %s", name, report.FirstEntry, want)
+		}
+	}
+}
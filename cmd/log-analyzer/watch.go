@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the watcher waits after the last change
+// before running the analysis, so a burst of writes triggers a single run.
+const watchDebounce = 200 * time.Millisecond
+
+// Watch call run once and then again whenever the file at path changes,
+// until the context is done. The parent directory is watched rather than
+// the file itself so that a file replaced atomically by renaming another
+// one over it keeps being watched.
+func Watch(ctx context.Context, path string, run func() error) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	path = filepath.Clean(path)
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	if err := run(); err != nil {
+		return err
+	}
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != path || !event.Has(fsnotify.Write|fsnotify.Create) {
+				continue
+			}
+			debounce.Reset(watchDebounce)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("watch %s: %w", path, err)
+		case <-debounce.C:
+			if err := run(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Poll call run once and then again whenever the modification time or size
+// of the file at path changes, checking every interval until the context
+// is done. It is an alternative to Watch on file systems without change
+// notifications, such as network mounts.
+func Poll(ctx context.Context, path string, interval time.Duration, run func() error) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := run(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			cur, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if cur.ModTime().Equal(fi.ModTime()) && cur.Size() == fi.Size() {
+				continue
+			}
+			fi = cur
+			if err := run(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Poll run again once the file was appended between two intervals,
+// and only then.
+func TestPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("2025-01-01 10:00:00 INFO started\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := make(chan int)
+	done := make(chan error)
+	go func() {
+		done <- Poll(ctx, path, 5*time.Millisecond, func() error {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			select {
+			case runs <- strings.Count(string(b), "\n"):
+			case <-ctx.Done():
+			}
+			return nil
+		})
+	}()
+
+	if n := <-runs; n != 1 {
+		t.Fatalf("got a first run over %d lines, want 1", n)
+	}
+	select {
+	case n := <-runs:
+		t.Fatalf("got a run over %d lines without change", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+	appendFile(t, path, "2025-01-01 10:00:01 INFO stopped\n")
+	select {
+	case n := <-runs:
+		if n != 2 {
+			t.Errorf("got a run over %d lines, want the 2 lines of the appended file", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no run after the file was appended")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPollMissingFile(t *testing.T) {
+	err := Poll(context.Background(), filepath.Join(t.TempDir(), "missing.log"), time.Millisecond, func() error {
+		t.Error("run on a missing file")
+		return nil
+	})
+	if err == nil {
+		t.Error("polled a missing file")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignPayload(t *testing.T) {
+	// The example of the GitHub webhook documentation.
+	want := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if got := SignPayload("It's a Secret to Everybody", []byte("Hello, World!")); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestPostReport(t *testing.T) {
+	report, err := Analyze(generateEntries(20, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"", "s3cret"} {
+		var header http.Header
+		var body []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			body, _ = io.ReadAll(r.Body)
+		}))
+		if err := PostReport(srv.URL, secret, report); err != nil {
+			t.Fatal(err)
+		}
+		srv.Close()
+
+		var got AnalysisReport
+		if err := json.Unmarshal(body, &got); err != nil || got.TotalEntries != 20 || header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %q, %v, want the report as json", body, err)
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if sig := header.Get("X-Signature-256"); (secret == "" && sig != "") || (secret != "" && sig != want) {
+			t.Errorf("secret %q: got the signature %q, want %q", secret, sig, want)
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	if err := PostReport(srv.URL, "", report); err == nil {
+		t.Error("got no error for an unauthorized request")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// SlidingWindowAnalyze analyze entries in overlapping time windows of the
+// given width, the first one starting at start and each following one
+// step later. Only windows fitting entirely between start and end are
+// analyzed. Window include entries at or after its start and before its end.
+func SlidingWindowAnalyze(entries []LogEntry, start, end time.Time, step, width time.Duration) []*AnalysisReport {
+	var reports []*AnalysisReport
+	if step <= 0 || width <= 0 {
+		return reports
+	}
+	for ws := start; !ws.Add(width).After(end); ws = ws.Add(step) {
+		we := ws.Add(width)
+		report := NewAnalysisReport()
+		for _, entry := range entries {
+			if !entry.time.Before(ws) && entry.time.Before(we) {
+				report.Add(entry)
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// ParseWindowSpec parse a 'start,end,step' window analysis specification.
+func ParseWindowSpec(spec string) (start, end time.Time, step time.Duration, err error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != 3 {
+		return start, end, step, fmt.Errorf("invalid window spec %q: expected 'start,end,step'", spec)
+	}
+	if start, err = parseTime(parts[0]); err != nil {
+		return start, end, step, fmt.Errorf("invalid window start: %w", err)
+	}
+	if end, err = parseTime(parts[1]); err != nil {
+		return start, end, step, fmt.Errorf("invalid window end: %w", err)
+	}
+	if step, err = time.ParseDuration(strings.TrimSpace(parts[2])); err != nil {
+		return start, end, step, fmt.Errorf("invalid window step: %w", err)
+	}
+	if step <= 0 {
+		return start, end, step, fmt.Errorf("invalid window step: %s must be positive", step)
+	}
+	if !end.After(start) {
+		return start, end, step, fmt.Errorf("invalid window spec %q: end must be after start", spec)
+	}
+	return start, end, step, nil
+}
+
+// PrintWindows write one row per window report, as produced by
+// SlidingWindowAnalyze with the same start, step and width.
+func PrintWindows(w io.Writer, reports []*AnalysisReport, start time.Time, step, width time.Duration) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "START\tEND\tTOTAL\tINFO\tDEBUG\tWARN\tERROR\tAVG RESPONSE (ms)")
+	for i, r := range reports {
+		ws := start.Add(time.Duration(i) * step)
+		avg := "-"
+		if v, ok := r.AverageResponseTime(); ok {
+			avg = fmt.Sprintf("%.2f", v)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			ws.Format(time.DateTime), ws.Add(width).Format(time.DateTime),
+			r.TotalEntries, r.Info, r.Debug, r.Warn, r.Error, avg)
+	}
+	return tw.Flush()
+}
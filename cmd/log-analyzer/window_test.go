@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlidingWindowAnalyze(t *testing.T) {
+	start, end, step, err := ParseWindowSpec("2025-01-01T10:00:00,2025-01-01T12:00:00,15m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []LogEntry
+	// An entry every 10 minutes from 10:00 to 12:00, the last one being
+	// past the end of the last window.
+	for m := 0; m <= 120; m += 10 {
+		ts := start.Add(time.Duration(m) * time.Minute).Format(time.DateTime)
+		entry, err := NewLogEntry(ts + " INFO Request processed in 10 ms")
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	// The 2 hours hold 7 windows of 30 minutes starting every 15 minutes,
+	// the last one from 11:30 to 12:00. The 4 windows of 30 minutes not
+	// overlapping are those of a 30 minute step.
+	var totals []int
+	for _, r := range SlidingWindowAnalyze(entries, start, end, step, 30*time.Minute) {
+		totals = append(totals, r.TotalEntries)
+	}
+	if got := fmt.Sprint(totals); got != "[3 3 3 3 3 3 3]" {
+		t.Errorf("got windows of %s entries, want the 7 windows of 3 entries", got)
+	}
+	if got := SlidingWindowAnalyze(entries, start, end, 30*time.Minute, 30*time.Minute); len(got) != 4 {
+		t.Errorf("got %d windows of a 30m step, want 4", len(got))
+	}
+	if got := SlidingWindowAnalyze(entries, start, end, 0, 30*time.Minute); len(got) != 0 {
+		t.Errorf("got %d windows of a zero step, want none", len(got))
+	}
+}
+
+func TestParseWindowSpec(t *testing.T) {
+	for _, spec := range []string{
+		"2025-01-01T10:00:00,2025-01-01T12:00:00",
+		"2025-01-01T10:00:00,2025-01-01T12:00:00,0s",
+		"2025-01-01T10:00:00,2025-01-01T12:00:00,soon",
+		"2025-01-01T12:00:00,2025-01-01T10:00:00,15m",
+		"yesterday,2025-01-01T12:00:00,15m",
+	} {
+		if _, _, _, err := ParseWindowSpec(spec); err == nil {
+			t.Errorf("ParseWindowSpec(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestPrintWindows(t *testing.T) {
+	start, _ := time.Parse(time.DateTime, "2025-01-01 10:00:00")
+	entry, err := NewLogEntry("2025-01-01 10:05:00 ERROR Request failed in 20 ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports := SlidingWindowAnalyze([]LogEntry{entry}, start, start.Add(time.Hour), 30*time.Minute, 30*time.Minute)
+	var b strings.Builder
+	if err := PrintWindows(&b, reports, start, 30*time.Minute, 30*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "2025-01-01 10:00:00  2025-01-01 10:30:00  1 ") ||
+		!strings.HasSuffix(lines[1], "20.00") || !strings.HasSuffix(lines[2], "-") {
+		t.Errorf("got the windows\n%s\nwant a row per window", b.String())
+	}
+}
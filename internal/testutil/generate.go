@@ -0,0 +1,67 @@
+// Package testutil provides helpers shared by the tests and benchmarks
+// of the commands, such as deterministic test data.
+package testutil
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Epoch is the end of the 24 hours test entries are generated in.
+// It is fixed rather than the current time so that generated entries
+// only depend on the seed.
+var Epoch = time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+var levels = []string{"INFO", "DEBUG", "WARN", "ERROR"}
+
+var templates = []string{
+	"Starting the application",
+	"Application stopped",
+	"Initializing module %c",
+	"Failed to connect to database",
+	"Memory usage is high",
+	"Request processed in %d ms",
+}
+
+// Entry is a generated log entry.
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Message string
+}
+
+// String return the entry as a line of a log file in the default format.
+func (e Entry) String() string {
+	return fmt.Sprintf("%s %s %s", e.Time.Format(time.DateTime), e.Level, e.Message)
+}
+
+// GenerateTestEntries return count entries generated from seed, with random
+// levels, messages from a fixed set of templates and timestamps within the
+// 24 hours preceding Epoch, in chronological order. The same seed always
+// produces the same entries.
+func GenerateTestEntries(count int, seed int64) []Entry {
+	rnd := rand.New(rand.NewSource(seed))
+	start := Epoch.Add(-24 * time.Hour)
+	step := 24 * time.Hour / time.Duration(max(count, 1))
+
+	entries := make([]Entry, count)
+	for i := range entries {
+		var msg string
+		switch tmpl := templates[rnd.Intn(len(templates))]; tmpl {
+		case "Initializing module %c":
+			msg = fmt.Sprintf(tmpl, 'A'+rune(rnd.Intn(26)))
+		case "Request processed in %d ms":
+			msg = fmt.Sprintf(tmpl, 1+rnd.Intn(500))
+		default:
+			msg = tmpl
+		}
+		level := levels[rnd.Intn(len(levels))]
+		entries[i] = Entry{
+			Time:    start.Add(time.Duration(i)*step + time.Duration(rnd.Int63n(int64(step)+1))).Truncate(time.Second),
+			Level:   level,
+			Message: msg,
+		}
+	}
+	return entries
+}
@@ -0,0 +1,35 @@
+package testutil
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGenerateTestEntries(t *testing.T) {
+	entries := GenerateTestEntries(1000, 0)
+	if len(entries) != 1000 {
+		t.Fatalf("got %d entries, want 1000", len(entries))
+	}
+	if !slices.Equal(entries, GenerateTestEntries(1000, 0)) {
+		t.Error("entries generated from the same seed differ")
+	}
+	if slices.Equal(entries, GenerateTestEntries(1000, 1)) {
+		t.Error("entries generated from different seeds are the same")
+	}
+	for i, e := range entries {
+		if e.Time.Before(Epoch.Add(-24*time.Hour)) || e.Time.After(Epoch) {
+			t.Fatalf("entry %d at %v, not within the 24 hours before %v", i, e.Time, Epoch)
+		}
+		if i > 0 && e.Time.Before(entries[i-1].Time) {
+			t.Fatalf("entry %d at %v before entry %d at %v", i, e.Time, i-1, entries[i-1].Time)
+		}
+	}
+}
+
+func TestEntryString(t *testing.T) {
+	e := Entry{Time: Epoch, Level: "WARN", Message: "Memory usage is high"}
+	if got, want := e.String(), "2025-01-02 00:00:00 WARN Memory usage is high"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}